@@ -98,8 +98,8 @@ func (p *DoFunctionProvider) getMatchingAction(comment *gogh.IssueCommentEvent)
 
 // Execute triggers the given DoFunctions (when all checks are fulfilled) for the given pr comment
 func (e *CmdExecutor) Execute(client ghclient.Client, logger log.Logger, comment *gogh.IssueCommentEvent) error {
-	body := strings.TrimSpace(*comment.Comment.Body)
-	if prefix := strings.Split(body, " ")[0]; e.Command != body && prefix != e.Command {
+	fields := strings.Fields(*comment.Comment.Body)
+	if len(fields) == 0 || fields[0] != e.Command {
 		return nil
 	}
 	for _, doExecutor := range e.executors {
@@ -81,6 +81,24 @@ var _ = Describe("Command executor features", func() {
 			Expect(counter).To(Equal(1))
 		})
 
+		It("should execute command when its arguments are given on the next line", func() {
+			// given
+			executed := false
+			command := is.CmdExecutor{Command: "/command", Quiet: true}
+			command.When(is.Deleted).By(is.Anybody).Then(func() error {
+				executed = true
+				return nil
+			})
+			deletedCommand.Comment.Body = utils.String("/command\r\nwith arguments")
+
+			// when
+			err := command.Execute(client, log, deletedCommand)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(executed).To(BeTrue())
+		})
+
 		It("should execute command once when multiple action are set and one is matching", func() {
 			// given
 			executed := false
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
 	yaml "gopkg.in/yaml.v2"
 )
 
@@ -9,25 +12,131 @@ type SourcesProvider interface {
 	Sources() []Source
 }
 
-// Source is a function type representing strategy for loading configuration file into []byte
+// LayersProvider is an interface which provides layers of the configuration ordered from the most general one
+// (such as organization-wide defaults) to the most specific one (such as configuration of the repository)
+type LayersProvider interface {
+	Layers() []SourcesProvider
+}
+
+// Source is a function type representing strategy for loading configuration file into []byte.
+// It returns scm.NotFoundError when there is no configuration it could load, so the next source can be tried
 type Source func() ([]byte, error)
 
+// These are possible values of status_report setting, which decides how the plugin publishes status of the change.
+const (
+	ReportStatus = "status" // commit status (default)
+	ReportChecks = "checks" // check run
+	ReportBoth   = "both"   // both commit status and check run
+)
+
+// LoadFailureMessage is a message used in GH Status as description when the configuration file exists, but cannot be loaded
+const LoadFailureMessage = "Failed to load the configuration of the plugin"
+
 // PluginConfiguration holds common configuration for all the plugins
 type PluginConfiguration struct {
-	PluginName  string
-	LocationURL string
+	PluginName   string   `yaml:"-"`
+	LocationURL  string   `yaml:"-"`
+	LocationURLs []string `yaml:"-"`
+	StatusReport string   `yaml:"status_report,omitempty"`
 }
 
-// Load loads configuration of the plugin based on strategies defined by SourcesProvider
-// It ignores errors returned by providers and only propagates the one occurred while unmarshalling
+// Validate checks the values of the fields common for all the plugins
+func (c *PluginConfiguration) Validate() []FieldError {
+	switch c.StatusReport {
+	case "", ReportStatus, ReportChecks, ReportBoth:
+		return nil
+	default:
+		return []FieldError{{
+			Field:   "status_report",
+			Value:   c.StatusReport,
+			Message: fmt.Sprintf("%q is not one of %s, %s, %s", c.StatusReport, ReportStatus, ReportChecks, ReportBoth),
+		}}
+	}
+}
+
+// Load loads configuration of the plugin based on strategies defined by SourcesProvider. The first source which finds
+// the configuration is used. When none of them does, the target is left untouched, so its defaults apply.
+// The error is returned when the source fails to load the configuration. ValidationError is returned when the
+// configuration contains unknown fields, values of a wrong type or values rejected by the target being a Validator.
 func Load(target interface{}, loader SourcesProvider) error {
-	var source []byte
+	loaded, err := find(loader)
+	if scm.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := validate(loaded, target); err != nil {
+		return err
+	}
+	return yaml.Unmarshal(loaded, target)
+}
+
+// LoadLayers loads configuration of the plugin from all the layers defined by LayersProvider. Each layer is looked up
+// the same way as by Load and the found ones are deep-merged - nested mappings are merged key by key, any other value
+// (including lists) of the more specific layer replaces the one of the more general layer. Unknown fields and values
+// of a wrong type are reported for each layer, while the values are validated by the target being a Validator only
+// once the layers are merged, as a layer may rely on the values of the others. When none of the layers is found,
+// the target is left untouched, so its defaults apply.
+func LoadLayers(target interface{}, provider LayersProvider) error {
+	var merged map[interface{}]interface{}
+	var layers [][]byte
+	for _, layer := range provider.Layers() {
+		loaded, err := find(layer)
+		if scm.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := validateSchema(loaded, target); err != nil {
+			return err
+		}
+		layers = append(layers, loaded)
+		var values map[interface{}]interface{}
+		if err := yaml.Unmarshal(loaded, &values); err != nil {
+			return err
+		}
+		merged = mergeMappings(merged, values)
+	}
+
+	if merged == nil {
+		return nil
+	}
+	content, err := yaml.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	if err := validateValues(content, target, layers...); err != nil {
+		return err
+	}
+	return yaml.Unmarshal(content, target)
+}
+
+func find(loader SourcesProvider) ([]byte, error) {
 	for _, load := range loader.Sources() {
 		loaded, err := load()
-		if err == nil {
-			source = loaded
-			break
+		if scm.IsNotFound(err) {
+			continue
+		}
+		return loaded, err
+	}
+	return nil, &scm.NotFoundError{Resource: "configuration"}
+}
+
+func mergeMappings(base, override map[interface{}]interface{}) map[interface{}]interface{} {
+	merged := make(map[interface{}]interface{}, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		baseMapping, baseIsMapping := merged[key].(map[interface{}]interface{})
+		overrideMapping, overrideIsMapping := value.(map[interface{}]interface{})
+		if baseIsMapping && overrideIsMapping {
+			merged[key] = mergeMappings(baseMapping, overrideMapping)
+		} else {
+			merged[key] = value
 		}
 	}
-	return yaml.Unmarshal(source, target)
+	return merged
 }
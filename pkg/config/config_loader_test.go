@@ -3,6 +3,7 @@ package config_test
 import (
 	"github.com/arquillian/ike-prow-plugins/pkg/config"
 	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/pkg/errors"
@@ -10,8 +11,9 @@ import (
 
 type sampleConfiguration struct {
 	config.PluginConfiguration `yaml:",inline,omitempty"`
-	Name                       string   `yaml:"name,omitempty"`
-	Skip                       []string `yaml:"skip_validation_for,omitempty"`
+	Name                       string            `yaml:"name,omitempty"`
+	Skip                       []string          `yaml:"skip_validation_for,omitempty"`
+	Labels                     map[string]string `yaml:"labels,omitempty"`
 }
 
 type testConfigProvider func() []config.Source
@@ -29,10 +31,26 @@ var nameAndSkip = config.Source(func() ([]byte, error) {
 		"skip_validation_for: ['anything']"), nil
 })
 
+var missing = config.Source(func() ([]byte, error) {
+	return nil, &scm.NotFoundError{Resource: "config"}
+})
+
 var faulty = config.Source(func() ([]byte, error) {
-	return nil, errors.New("no config found here")
+	return nil, errors.New("config cannot be read")
 })
 
+type testLayersProvider []config.SourcesProvider
+
+func (l testLayersProvider) Layers() []config.SourcesProvider {
+	return l
+}
+
+func layer(sources ...config.Source) config.SourcesProvider {
+	return testConfigProvider(func() []config.Source {
+		return sources
+	})
+}
+
 var _ = Describe("Config loader features", func() {
 
 	Context("Loading configuration from file", func() {
@@ -74,10 +92,10 @@ var _ = Describe("Config loader features", func() {
 			Expect(sampleConfig.Name).To(Equal("awesome-o"))
 		})
 
-		It("should load configuration when failing and successful lookup provided, skipping first failing", func() {
+		It("should load configuration when missing and successful lookup provided, skipping first missing", func() {
 			// given
 			testConfigProviders := testConfigProvider(func() []config.Source {
-				return []config.Source{faulty, onlyName}
+				return []config.Source{missing, onlyName}
 			})
 
 			sampleConfig := sampleConfiguration{}
@@ -123,10 +141,10 @@ var _ = Describe("Config loader features", func() {
 			Expect(sampleConfig.Name).To(Equal("prototype"))
 		})
 
-		It("should not propagate error when faulty source provided", func() {
+		It("should preserve prototype config name when none of the sources finds the config", func() {
 			// given
 			testConfigProviders := testConfigProvider(func() []config.Source {
-				return []config.Source{faulty}
+				return []config.Source{missing, missing}
 			})
 
 			sampleConfig := sampleConfiguration{Name: "prototype"}
@@ -139,5 +157,110 @@ var _ = Describe("Config loader features", func() {
 			Expect(sampleConfig.Name).To(Equal("prototype"))
 		})
 
+		It("should propagate error when faulty source provided", func() {
+			// given
+			testConfigProviders := testConfigProvider(func() []config.Source {
+				return []config.Source{faulty, onlyName}
+			})
+
+			sampleConfig := sampleConfiguration{Name: "prototype"}
+
+			// when
+			err := config.Load(&sampleConfig, testConfigProviders)
+
+			// then
+			Ω(err).Should(MatchError("config cannot be read"))
+			Expect(sampleConfig.Name).To(Equal("prototype"))
+		})
+
+		It("should propagate error when config cannot be unmarshalled", func() {
+			// given
+			testConfigProviders := testConfigProvider(func() []config.Source {
+				return []config.Source{func() ([]byte, error) {
+					return []byte("name: ['not', 'a', 'string']"), nil
+				}}
+			})
+
+			sampleConfig := sampleConfiguration{Name: "prototype"}
+
+			// when
+			err := config.Load(&sampleConfig, testConfigProviders)
+
+			// then
+			Ω(err).Should(HaveOccurred())
+		})
+
+	})
+
+	Context("Loading configuration from layers", func() {
+
+		organizationDefaults := config.Source(func() ([]byte, error) {
+			return []byte("name: 'org-defaults'\n" +
+				"skip_validation_for: ['*.md', '*.adoc']\n" +
+				"labels: {wip: 'work-in-progress', bug: 'bug'}"), nil
+		})
+
+		It("should deep-merge repository configuration on top of organization defaults", func() {
+			// given
+			repository := config.Source(func() ([]byte, error) {
+				return []byte("skip_validation_for: ['pom.xml']\n" +
+					"labels: {wip: 'do-not-merge'}"), nil
+			})
+			layers := testLayersProvider{layer(organizationDefaults), layer(missing, repository)}
+
+			sampleConfig := sampleConfiguration{Name: "prototype"}
+
+			// when
+			err := config.LoadLayers(&sampleConfig, layers)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(sampleConfig.Name).To(Equal("org-defaults"))
+			Expect(sampleConfig.Skip).To(ConsistOf("pom.xml"))
+			Expect(sampleConfig.Labels).To(Equal(map[string]string{"wip": "do-not-merge", "bug": "bug"}))
+		})
+
+		It("should load organization defaults when repository configuration is missing", func() {
+			// given
+			layers := testLayersProvider{layer(organizationDefaults), layer(missing, missing)}
+
+			sampleConfig := sampleConfiguration{}
+
+			// when
+			err := config.LoadLayers(&sampleConfig, layers)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(sampleConfig.Name).To(Equal("org-defaults"))
+			Expect(sampleConfig.Skip).To(ConsistOf("*.md", "*.adoc"))
+		})
+
+		It("should preserve prototype config when none of the layers is found", func() {
+			// given
+			layers := testLayersProvider{layer(missing), layer(missing, missing)}
+
+			sampleConfig := sampleConfiguration{Name: "prototype"}
+
+			// when
+			err := config.LoadLayers(&sampleConfig, layers)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(sampleConfig.Name).To(Equal("prototype"))
+		})
+
+		It("should propagate error when any of the layers fails to load", func() {
+			// given
+			layers := testLayersProvider{layer(faulty), layer(onlyName)}
+
+			sampleConfig := sampleConfiguration{Name: "prototype"}
+
+			// when
+			err := config.LoadLayers(&sampleConfig, layers)
+
+			// then
+			Ω(err).Should(MatchError("config cannot be read"))
+			Expect(sampleConfig.Name).To(Equal("prototype"))
+		})
 	})
 })
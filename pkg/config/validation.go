@@ -0,0 +1,188 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// InvalidConfigMessage is a message used in GH Status as description when the configuration file doesn't conform to the schema of the plugin
+const InvalidConfigMessage = "Invalid configuration of the plugin"
+
+// Validator is implemented by plugin configurations verifying the values of their fields beyond what is checked by
+// strict unmarshalling, such as patterns which have to be valid regular expressions
+type Validator interface {
+	Validate() []FieldError
+}
+
+// FieldError describes a problem with a single field of the configuration file
+type FieldError struct {
+	Line    int    // line of the configuration file, 0 when unknown
+	Field   string // name of the field as used in the configuration file
+	Value   string // the invalid value (if any), used to find the line of list items
+	Message string
+}
+
+func (e FieldError) String() string {
+	var location []string
+	if e.Line > 0 {
+		location = append(location, fmt.Sprintf("line %d", e.Line))
+	}
+	if e.Field != "" {
+		location = append(location, fmt.Sprintf("field `%s`", e.Field))
+	}
+	if len(location) == 0 {
+		return e.Message
+	}
+	return strings.Join(location, ", ") + ": " + e.Message
+}
+
+// ValidationError is returned when the configuration file doesn't conform to the schema of the plugin
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fieldErr.String())
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// IsValidationError checks if the given error is ValidationError
+func IsValidationError(err error) bool {
+	_, invalid := err.(*ValidationError)
+	return invalid
+}
+
+// ErrorStatusMessage returns a description of GH Status reporting that the configuration couldn't be loaded because of the given error
+func ErrorStatusMessage(err error) string {
+	if IsValidationError(err) {
+		return InvalidConfigMessage
+	}
+	return LoadFailureMessage
+}
+
+var (
+	errorLineRegexp    = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldRegexp = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+	keyRegexp          = regexp.MustCompile(`^(\s*)(?:-\s+)?([\w-]+)\s*:`)
+)
+
+// validate strictly unmarshals the given content into the new instance of the target type and lets it validate itself
+// when it's a Validator. Both unknown fields and values of a wrong type are reported as ValidationError
+func validate(content []byte, target interface{}) error {
+	if err := validateSchema(content, target); err != nil {
+		return err
+	}
+	return validateValues(content, target, content)
+}
+
+// validateSchema strictly unmarshals the given content into the new instance of the target type, so unknown fields and
+// values of a wrong type are reported as ValidationError
+func validateSchema(content []byte, target interface{}) error {
+	layer := reflect.New(reflect.TypeOf(target).Elem()).Interface()
+	if err := yaml.UnmarshalStrict(content, layer); err != nil {
+		return newValidationError(content, err)
+	}
+	return nil
+}
+
+// validateValues unmarshals the given content into the new instance of the target type and lets it validate itself when
+// it's a Validator. The lines of the reported fields are looked up in the given sources of the content, starting with
+// the last one (the most specific layer)
+func validateValues(content []byte, target interface{}, sources ...[]byte) error {
+	layer := reflect.New(reflect.TypeOf(target).Elem()).Interface()
+	validator, ok := layer.(Validator)
+	if !ok {
+		return nil
+	}
+	if err := yaml.Unmarshal(content, layer); err != nil {
+		return newValidationError(content, err)
+	}
+	fieldErrors := validator.Validate()
+	if len(fieldErrors) == 0 {
+		return nil
+	}
+	for i, fieldErr := range fieldErrors {
+		for j := len(sources) - 1; j >= 0 && fieldErrors[i].Line == 0; j-- {
+			fieldErrors[i].Line = lineOf(strings.Split(string(sources[j]), "\n"), fieldErr.Field, fieldErr.Value)
+		}
+	}
+	return &ValidationError{Errors: fieldErrors}
+}
+
+func newValidationError(content []byte, err error) *ValidationError {
+	var messages []string
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		messages = typeErr.Errors
+	} else {
+		messages = []string{err.Error()}
+	}
+
+	lines := strings.Split(string(content), "\n")
+	fieldErrors := make([]FieldError, 0, len(messages))
+	for _, msg := range messages {
+		fieldErr := FieldError{Message: msg}
+		if match := errorLineRegexp.FindStringSubmatch(msg); match != nil {
+			fieldErr.Line, _ = strconv.Atoi(match[1])
+			fieldErr.Message = match[2]
+			fieldErr.Field = fieldAt(lines, fieldErr.Line)
+		}
+		if match := unknownFieldRegexp.FindStringSubmatch(fieldErr.Message); match != nil {
+			fieldErr.Field = match[1]
+			fieldErr.Message = "unknown field"
+		}
+		fieldErrors = append(fieldErrors, fieldErr)
+	}
+	return &ValidationError{Errors: fieldErrors}
+}
+
+// fieldAt finds the name of the field defined at the given line (counted from 1). When the line holds a list item or
+// a continuation of the value, the closest field defined above with a lower indentation is returned
+func fieldAt(lines []string, line int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	for i := line - 1; i >= 0; i-- {
+		match := keyRegexp.FindStringSubmatch(lines[i])
+		if match != nil && (i == line-1 || len(match[1]) < indentationOf(lines[line-1])) {
+			return match[2]
+		}
+	}
+	return ""
+}
+
+// lineOf finds the line (counted from 1) where the given field is defined. When the value is given, the line within
+// the definition of the field which contains the value is preferred. It is 0 when the field is not found
+func lineOf(lines []string, field, value string) int {
+	for i, line := range lines {
+		match := keyRegexp.FindStringSubmatch(line)
+		if match == nil || match[2] != field {
+			continue
+		}
+		if value == "" {
+			return i + 1
+		}
+		for j := i; j < len(lines); j++ {
+			if j > i && strings.TrimSpace(lines[j]) != "" && indentationOf(lines[j]) <= len(match[1]) &&
+				!strings.HasPrefix(strings.TrimSpace(lines[j]), "-") {
+				break
+			}
+			if strings.Contains(lines[j], value) {
+				return j + 1
+			}
+		}
+		return i + 1
+	}
+	return 0
+}
+
+func indentationOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
@@ -0,0 +1,110 @@
+package config_test
+
+import (
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func loadFrom(content string) (sampleConfiguration, error) {
+	sampleConfig := sampleConfiguration{Name: "prototype"}
+	err := config.Load(&sampleConfig, testConfigProvider(func() []config.Source {
+		return []config.Source{func() ([]byte, error) {
+			return []byte(content), nil
+		}}
+	}))
+	return sampleConfig, err
+}
+
+var _ = Describe("Config validation features", func() {
+
+	Context("Validating configuration against the schema of the plugin", func() {
+
+		It("should report unknown field with its line", func() {
+			// when
+			sampleConfig, err := loadFrom("name: 'awesome-o'\n" +
+				"skip_validation: ['anything']")
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			Expect(err.(*config.ValidationError).Errors).To(ConsistOf(
+				config.FieldError{Line: 2, Field: "skip_validation", Message: "unknown field"}))
+			Expect(sampleConfig.Name).To(Equal("prototype"))
+		})
+
+		It("should report value of a wrong type with the field it belongs to", func() {
+			// when
+			_, err := loadFrom("name: 'awesome-o'\n" +
+				"skip_validation_for: 'anything'")
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			fieldErrors := err.(*config.ValidationError).Errors
+			Expect(fieldErrors).To(HaveLen(1))
+			Expect(fieldErrors[0].Line).To(Equal(2))
+			Expect(fieldErrors[0].Field).To(Equal("skip_validation_for"))
+		})
+
+		It("should report malformed yaml as validation error", func() {
+			// when
+			_, err := loadFrom("skip_validation_for: {not a list")
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			Expect(config.ErrorStatusMessage(err)).To(Equal(config.InvalidConfigMessage))
+		})
+
+		It("should report unsupported status report type", func() {
+			// when
+			_, err := loadFrom("name: 'awesome-o'\n" +
+				"status_report: comments")
+
+			// then
+			Ω(err).Should(MatchError("invalid configuration: line 2, field `status_report`: " +
+				`"comments" is not one of status, checks, both`))
+		})
+
+		It("should accept valid configuration", func() {
+			// when
+			sampleConfig, err := loadFrom("name: 'awesome-o'\n" +
+				"status_report: checks\n" +
+				"skip_validation_for:\n" +
+				"  - 'pom.xml'")
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(sampleConfig.StatusReport).To(Equal(config.ReportChecks))
+			Expect(sampleConfig.Skip).To(ConsistOf("pom.xml"))
+		})
+
+		It("should validate schema of each of the layers", func() {
+			// given
+			invalidRepository := config.Source(func() ([]byte, error) {
+				return []byte("labels: ['not', 'a', 'mapping']"), nil
+			})
+			layers := testLayersProvider{layer(onlyName), layer(invalidRepository)}
+
+			sampleConfig := sampleConfiguration{Name: "prototype"}
+
+			// when
+			err := config.LoadLayers(&sampleConfig, layers)
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			Expect(sampleConfig.Name).To(Equal("prototype"))
+		})
+	})
+
+	Context("Rendering validation errors", func() {
+
+		It("should render field error with its location", func() {
+			Expect(config.FieldError{Line: 4, Field: "test_patterns", Message: "pattern must not be empty"}.String()).
+				To(Equal("line 4, field `test_patterns`: pattern must not be empty"))
+		})
+
+		It("should render field error without unknown location", func() {
+			Expect(config.FieldError{Message: "did not find expected key"}.String()).
+				To(Equal("did not find expected key"))
+		})
+	})
+})
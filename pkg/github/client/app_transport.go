@@ -0,0 +1,221 @@
+package ghclient
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gogh "github.com/google/go-github/github"
+)
+
+const (
+	// jwtValidity is how long the JWT used to authenticate as the GitHub App is valid (GitHub accepts at most 10 minutes)
+	jwtValidity = 9 * time.Minute
+	// tokenExpiryMargin ensures that the installation token is renewed before it expires while the request is in flight
+	tokenExpiryMargin = time.Minute
+)
+
+// AppTransport is a http.RoundTripper which authenticates requests as an installation of the GitHub App.
+// The installation is resolved based on the owner of the repository the request is sent to - either from the ones
+// registered using RegisterInstallation (e.g. taken from incoming webhooks) or by asking GitHub for it.
+// Requests which are not related to any repository (such as rate limits) are authenticated as the App itself.
+// Installation tokens are cached until they expire. The token is renewed while holding the lock of its installation
+// only, so a slow renewal doesn't block requests sent on behalf of other installations.
+type AppTransport struct {
+	appID         int64
+	privateKey    *rsa.PrivateKey
+	apps          *gogh.AppsService
+	jwtTransport  http.RoundTripper
+	mutex         sync.Mutex
+	installations map[string]int64
+	tokens        map[int64]*gogh.InstallationToken
+	renewals      map[int64]*sync.Mutex
+}
+
+// NewAppTransport creates AppTransport for the GitHub App with the given ID using PEM encoded private key of the App
+func NewAppTransport(appID int64, privateKeyPEM []byte) (*AppTransport, error) {
+	privateKey, err := parsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	transport := &AppTransport{
+		appID:         appID,
+		privateKey:    privateKey,
+		installations: map[string]int64{},
+		tokens:        map[int64]*gogh.InstallationToken{},
+		renewals:      map[int64]*sync.Mutex{},
+	}
+	transport.jwtTransport = &jwtTransport{app: transport}
+	transport.apps = newGoGitHubClient(&http.Client{Transport: transport.jwtTransport}).Apps
+	return transport, nil
+}
+
+// RegisterInstallation binds the account (user or organization owning repositories) with the installation of the App
+func (t *AppTransport) RegisterInstallation(account string, installationID int64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.installations[strings.ToLower(account)] = installationID
+}
+
+// RoundTrip sends the request authenticated with the token of the installation the request belongs to. The request
+// which doesn't belong to any repository is authenticated as the App itself
+func (t *AppTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	owner, repo := repositoryOf(req.URL.Path)
+	if owner == "" {
+		return t.jwtTransport.RoundTrip(req)
+	}
+	token, err := t.installationToken(req.Context(), owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	authorized := cloneRequest(req)
+	authorized.Header.Set("Authorization", "token "+token)
+	return http.DefaultTransport.RoundTrip(authorized)
+}
+
+func (t *AppTransport) installationToken(ctx context.Context, owner, repo string) (string, error) {
+	installationID, err := t.resolveInstallation(ctx, owner, repo)
+	if err != nil {
+		return "", err
+	}
+
+	renewal := t.renewalOf(installationID)
+	renewal.Lock()
+	defer renewal.Unlock()
+
+	t.mutex.Lock()
+	token, cached := t.tokens[installationID]
+	t.mutex.Unlock()
+	if cached && !token.GetExpiresAt().Before(time.Now().Add(tokenExpiryMargin)) {
+		return token.GetToken(), nil
+	}
+
+	token, _, err = t.apps.CreateInstallationToken(ctx, installationID)
+	if err != nil {
+		return "", fmt.Errorf("unable to create access token for installation %d. cause: %s", installationID, err)
+	}
+	t.mutex.Lock()
+	t.tokens[installationID] = token
+	t.mutex.Unlock()
+	return token.GetToken(), nil
+}
+
+// renewalOf returns the lock guarding the renewal of the token of the given installation
+func (t *AppTransport) renewalOf(installationID int64) *sync.Mutex {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	renewal, exists := t.renewals[installationID]
+	if !exists {
+		renewal = &sync.Mutex{}
+		t.renewals[installationID] = renewal
+	}
+	return renewal
+}
+
+// resolveInstallation finds the installation for the given repository. When the installation of its owner hasn't been
+// registered yet, it's looked up without holding the lock, so other requests are not blocked in the meantime
+func (t *AppTransport) resolveInstallation(ctx context.Context, owner, repo string) (int64, error) {
+	t.mutex.Lock()
+	installationID, found := t.installations[strings.ToLower(owner)]
+	t.mutex.Unlock()
+	if found {
+		return installationID, nil
+	}
+
+	installation, _, err := t.apps.FindRepositoryInstallation(ctx, owner, repo)
+	if err != nil {
+		return 0, fmt.Errorf("unable to find installation of the GitHub App for %s/%s. cause: %s", owner, repo, err)
+	}
+	t.RegisterInstallation(owner, installation.GetID())
+	return installation.GetID(), nil
+}
+
+// jwt creates a token signed by the private key of the App which is used to authenticate as the App itself
+func (t *AppTransport) jwt() (string, error) {
+	now := time.Now()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(), // to allow some clock drift
+		"exp": now.Add(jwtValidity).Unix(),
+		"iss": t.appID,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	hashed := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.privateKey, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// jwtTransport authenticates requests as the GitHub App itself. It is used to obtain installation tokens.
+type jwtTransport struct {
+	app *AppTransport
+}
+
+func (t *jwtTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := t.app.jwt()
+	if err != nil {
+		return nil, err
+	}
+	authorized := cloneRequest(req)
+	authorized.Header.Set("Authorization", "Bearer "+jwt)
+	return http.DefaultTransport.RoundTrip(authorized)
+}
+
+func parsePrivateKey(privateKeyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(privateKeyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("private key of the GitHub App is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse private key of the GitHub App. cause: %s", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key of the GitHub App is not a RSA key")
+	}
+	return rsaKey, nil
+}
+
+// repositoryOf extracts owner and name of the repository from API paths such as /repos/{owner}/{repo}/pulls
+func repositoryOf(path string) (owner, repo string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if segment == "repos" && i+2 < len(segments) {
+			return segments[i+1], segments[i+2]
+		}
+	}
+	return "", ""
+}
+
+// cloneRequest returns a shallow copy of the request with deep copy of its headers, as RoundTripper
+// must not modify the request it is given
+func cloneRequest(req *http.Request) *http.Request {
+	clone := new(http.Request)
+	*clone = *req
+	clone.Header = make(http.Header, len(req.Header))
+	for key, values := range req.Header {
+		clone.Header[key] = append([]string(nil), values...)
+	}
+	return clone
+}
@@ -0,0 +1,226 @@
+package ghclient_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+var _ = Describe("GitHub App authentication", func() {
+
+	const appID = 1234
+
+	var (
+		privateKey *rsa.PrivateKey
+		transport  *ghclient.AppTransport
+		client     ghclient.Client
+	)
+
+	verifyJWT := func(req *http.Request, _ *gock.Request) (bool, error) {
+		jwt := strings.Split(strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "), ".")
+		if len(jwt) != 3 {
+			return false, nil
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(jwt[2])
+		if err != nil {
+			return false, err
+		}
+		hashed := sha256.Sum256([]byte(jwt[0] + "." + jwt[1]))
+		if err := rsa.VerifyPKCS1v15(&privateKey.PublicKey, crypto.SHA256, hashed[:], signature); err != nil {
+			return false, nil
+		}
+		claims, err := base64.RawURLEncoding.DecodeString(jwt[1])
+		if err != nil {
+			return false, err
+		}
+		var issuer struct {
+			Iss int64 `json:"iss"`
+		}
+		return json.Unmarshal(claims, &issuer) == nil && issuer.Iss == appID, nil
+	}
+
+	signedByApp := func() gock.Matcher {
+		matcher := gock.NewBasicMatcher()
+		matcher.Add(verifyJWT)
+		return matcher
+	}
+
+	installationToken := func(installationID int, token string, expiresAt time.Time) {
+		gock.New("https://api.github.com").
+			Post(fmt.Sprintf("/app/installations/%d/access_tokens", installationID)).
+			SetMatcher(signedByApp()).
+			Reply(201).
+			BodyString(fmt.Sprintf(`{"token": %q, "expires_at": %q}`, token, expiresAt.Format(time.RFC3339)))
+	}
+
+	pullRequest := func(owner, token string) {
+		gock.New("https://api.github.com").
+			Get(fmt.Sprintf("/repos/%s/repo/pulls/1", owner)).
+			MatchHeader("Authorization", "^token "+token+"$").
+			Reply(200).
+			BodyString(`{"number": 1}`)
+	}
+
+	BeforeEach(func() {
+		gock.OffAll()
+		gock.Intercept()
+		var err error
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Ω(err).ShouldNot(HaveOccurred())
+		privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+		transport, err = ghclient.NewAppTransport(appID, privateKeyPEM)
+		Ω(err).ShouldNot(HaveOccurred())
+		client = ghclient.NewAppClient(transport, log.NewTestLogger())
+		client.RegisterAroundFunctions(ghclient.NewPaginationChecker())
+	})
+
+	AfterEach(func() {
+		EnsureGockRequestsHaveBeenMatched()
+		gock.OffAll()
+	})
+
+	It("should authenticate requests using cached token of the registered installation", func() {
+		// given
+		transport.RegisterInstallation("alien-ike", 42)
+		installationToken(42, "v1.alien-ike", time.Now().Add(time.Hour))
+		pullRequest("alien-ike", "v1.alien-ike")
+		pullRequest("alien-ike", "v1.alien-ike")
+
+		// when
+		_, firstErr := client.GetPullRequest("alien-ike", "repo", 1)
+		_, secondErr := client.GetPullRequest("alien-ike", "repo", 1)
+
+		// then - implicit verification that the token has been created only once
+		Ω(firstErr).ShouldNot(HaveOccurred())
+		Ω(secondErr).ShouldNot(HaveOccurred())
+		Ω(gock.IsDone()).Should(BeTrue())
+	})
+
+	It("should use separate tokens for different installations", func() {
+		// given
+		transport.RegisterInstallation("alien-ike", 42)
+		transport.RegisterInstallation("arquillian", 43)
+		installationToken(42, "v1.alien-ike", time.Now().Add(time.Hour))
+		installationToken(43, "v1.arquillian", time.Now().Add(time.Hour))
+		pullRequest("alien-ike", "v1.alien-ike")
+		pullRequest("arquillian", "v1.arquillian")
+
+		// when
+		_, alienIkeErr := client.GetPullRequest("alien-ike", "repo", 1)
+		_, arquillianErr := client.GetPullRequest("arquillian", "repo", 1)
+
+		// then
+		Ω(alienIkeErr).ShouldNot(HaveOccurred())
+		Ω(arquillianErr).ShouldNot(HaveOccurred())
+		Ω(gock.IsDone()).Should(BeTrue())
+	})
+
+	It("should renew token which is about to expire", func() {
+		// given
+		transport.RegisterInstallation("alien-ike", 42)
+		installationToken(42, "v1.expiring", time.Now().Add(30*time.Second))
+		installationToken(42, "v1.renewed", time.Now().Add(time.Hour))
+		pullRequest("alien-ike", "v1.expiring")
+		pullRequest("alien-ike", "v1.renewed")
+
+		// when
+		_, firstErr := client.GetPullRequest("alien-ike", "repo", 1)
+		_, secondErr := client.GetPullRequest("alien-ike", "repo", 1)
+
+		// then
+		Ω(firstErr).ShouldNot(HaveOccurred())
+		Ω(secondErr).ShouldNot(HaveOccurred())
+		Ω(gock.IsDone()).Should(BeTrue())
+	})
+
+	It("should look up installation of the repository which has not been registered", func() {
+		// given
+		gock.New("https://api.github.com").
+			Get("/repos/alien-ike/repo/installation").
+			SetMatcher(signedByApp()).
+			Reply(200).
+			BodyString(`{"id": 42}`)
+		installationToken(42, "v1.alien-ike", time.Now().Add(time.Hour))
+		pullRequest("alien-ike", "v1.alien-ike")
+
+		// when
+		_, err := client.GetPullRequest("alien-ike", "repo", 1)
+
+		// then
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(gock.IsDone()).Should(BeTrue())
+	})
+
+	It("should authenticate requests not related to any repository as the App itself", func() {
+		// given
+		gock.New("https://api.github.com").
+			Get("/rate_limit").
+			SetMatcher(signedByApp()).
+			Reply(200).
+			BodyString(`{"resources": {"core": {"limit": 5000, "remaining": 4999}}}`)
+
+		// when
+		limits, err := client.GetRateLimit()
+
+		// then
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(limits.Core.Remaining).To(Equal(4999))
+	})
+
+	It("should not block requests of other installations while the token is being renewed", func() {
+		// given
+		transport.RegisterInstallation("alien-ike", 42)
+		transport.RegisterInstallation("arquillian", 43)
+		gock.New("https://api.github.com").
+			Post("/app/installations/42/access_tokens").
+			SetMatcher(signedByApp()).
+			Reply(201).
+			Delay(2 * time.Second).
+			BodyString(fmt.Sprintf(`{"token": "v1.alien-ike", "expires_at": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339)))
+		installationToken(43, "v1.arquillian", time.Now().Add(time.Hour))
+		pullRequest("alien-ike", "v1.alien-ike")
+		pullRequest("arquillian", "v1.arquillian")
+
+		slowRequest := make(chan error)
+		go func() {
+			_, err := client.GetPullRequest("alien-ike", "repo", 1)
+			slowRequest <- err
+		}()
+		time.Sleep(200 * time.Millisecond)
+
+		// when
+		start := time.Now()
+		_, arquillianErr := client.GetPullRequest("arquillian", "repo", 1)
+		elapsed := time.Since(start)
+
+		// then
+		Ω(arquillianErr).ShouldNot(HaveOccurred())
+		Expect(elapsed).To(BeNumerically("<", time.Second))
+		Ω(<-slowRequest).ShouldNot(HaveOccurred())
+	})
+
+	It("should fail when the private key is not PEM encoded", func() {
+		// when
+		_, err := ghclient.NewAppTransport(appID, []byte("not a key"))
+
+		// then
+		Ω(err).Should(MatchError("private key of the GitHub App is not PEM encoded"))
+	})
+})
@@ -4,7 +4,10 @@ import (
 	"context"
 
 	"fmt"
+	"net/http"
+	"net/url"
 
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	"github.com/arquillian/ike-prow-plugins/pkg/scm"
 	gogh "github.com/google/go-github/github"
@@ -22,11 +25,21 @@ type Client interface {
 	GetPermissionLevel(owner, repo, user string) (*gogh.RepositoryPermissionLevel, error)
 	GetPullRequest(owner, repo string, prNumber int) (*gogh.PullRequest, error)
 	ListPullRequestFiles(owner, repo string, prNumber int) ([]scm.ChangedFile, error)
+	ListPullRequestCommits(owner, repo string, prNumber int) ([]*gogh.RepositoryCommit, error)
+	ListMergedPullRequests(owner, repo string, limit int) ([]*gogh.PullRequest, error)
+	ListOpenPullRequests(owner, repo string) ([]*gogh.PullRequest, error)
+	ListPullRequestsWithCommit(owner, repo, sha string) ([]*gogh.PullRequest, error)
+	ListLanguages(owner, repo string) (map[string]int, error)
 	GetPullRequestReviews(owner, repo string, prNumber int) ([]*gogh.PullRequestReview, error)
+	GetIssue(issue scm.RepositoryIssue) (*gogh.Issue, error)
 	ListIssueComments(issue scm.RepositoryIssue) ([]*gogh.IssueComment, error)
+	GetFileContent(owner, repo, ref, path string) ([]byte, error)
 	CreateIssueComment(issue scm.RepositoryIssue, commentMsg *string) error
 	EditIssueComment(issue scm.RepositoryIssue, commentID int64, commentMsg *string) error
+	GetCombinedStatus(change scm.RepositoryChange) (*gogh.CombinedStatus, error)
+	GetCommit(change scm.RepositoryChange) (*gogh.RepositoryCommit, error)
 	CreateStatus(change scm.RepositoryChange, repoStatus *gogh.RepoStatus) error
+	CreateCheckRun(change scm.RepositoryChange, checkRun *gogh.CreateCheckRunOptions) error
 	AddPullRequestLabel(change scm.RepositoryChange, prNumber int, label []string) error
 	RemovePullRequestLabel(change scm.RepositoryChange, prNumber int, label string) error
 	EditPullRequest(*gogh.PullRequest) error
@@ -39,8 +52,22 @@ type Client interface {
 // it creates go-github client which is used as delegate
 func NewOauthClient(oauthSecret []byte, logger log.Logger) Client {
 	token := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: string(oauthSecret)})
-	oauthClient := gogh.NewClient(oauth2.NewClient(context.Background(), token))
-	return NewClient(oauthClient, logger)
+	return NewClient(newGoGitHubClient(oauth2.NewClient(context.Background(), token)), logger)
+}
+
+// NewAppClient creates a Client instance authenticated as an installation of the GitHub App using the given transport.
+// Underneath it creates go-github client which is used as delegate
+func NewAppClient(transport *AppTransport, logger log.Logger) Client {
+	return NewClient(newGoGitHubClient(&http.Client{Transport: transport}), logger)
+}
+
+// newGoGitHubClient creates go-github client talking to the API of the GitHub instance set by github.UseEndpoints
+func newGoGitHubClient(httpClient *http.Client) *gogh.Client {
+	client := gogh.NewClient(httpClient)
+	if baseURL, err := url.Parse(github.CurrentEndpoints().API); err == nil {
+		client.BaseURL = baseURL
+	}
+	return client
 }
 
 // NewClient creates a Client instance with the given instance of go-github client which will be used as a delegate
@@ -133,6 +160,121 @@ func (c *client) ListPullRequestFiles(owner, repo string, prNumber int) ([]scm.C
 	return changedFiles, err
 }
 
+// ListPullRequestCommits lists the commits of a pull request.
+func (c *client) ListPullRequestCommits(owner, repo string, prNumber int) ([]*gogh.RepositoryCommit, error) {
+	prCommits := make([]*gogh.RepositoryCommit, 0)
+
+	err := c.do(func(aroundCtx aroundContext) (func(), *gogh.Response, error) {
+		commits, response, e := c.gh.PullRequests.ListCommits(context.Background(), owner, repo, prNumber, listOpts(aroundCtx))
+		return func() {
+			prCommits = append(prCommits, commits...)
+		}, response, c.checkHTTPCode(response, e)
+	})
+
+	return prCommits, err
+}
+
+// ListMergedPullRequests lists at most limit (up to 100) most recently updated pull requests which have been merged.
+// Only the first page of closed pull requests is retrieved, so there might be less of them.
+func (c *client) ListMergedPullRequests(owner, repo string, limit int) ([]*gogh.PullRequest, error) {
+	mergedPRs := make([]*gogh.PullRequest, 0, limit)
+
+	err := c.do(func(aroundCtx aroundContext) (func(), *gogh.Response, error) {
+		prsOpt := &gogh.PullRequestListOptions{
+			State:       "closed",
+			Sort:        "updated",
+			Direction:   "desc",
+			ListOptions: gogh.ListOptions{PerPage: 100},
+		}
+		prs, response, e := c.gh.PullRequests.List(context.Background(), owner, repo, prsOpt)
+		if response != nil {
+			// only the most recent pull requests are of interest, so there is no point in going through all the pages
+			response.NextPage = 0
+		}
+		return func() {
+			mergedPRs = mergedPRs[:0]
+			for _, pr := range prs {
+				if pr.MergedAt != nil && len(mergedPRs) < limit {
+					mergedPRs = append(mergedPRs, pr)
+				}
+			}
+		}, response, c.checkHTTPCode(response, e)
+	})
+
+	return mergedPRs, err
+}
+
+// ListOpenPullRequests lists all open pull requests of the repository.
+func (c *client) ListOpenPullRequests(owner, repo string) ([]*gogh.PullRequest, error) {
+	openPRs := make([]*gogh.PullRequest, 0)
+
+	err := c.do(func(aroundCtx aroundContext) (func(), *gogh.Response, error) {
+		prsOpt := &gogh.PullRequestListOptions{State: "open", ListOptions: *listOpts(aroundCtx)}
+		prs, response, e := c.gh.PullRequests.List(context.Background(), owner, repo, prsOpt)
+		return func() {
+			openPRs = append(openPRs, prs...)
+		}, response, c.checkHTTPCode(response, e)
+	})
+
+	return openPRs, err
+}
+
+// ListPullRequestsWithCommit lists pull requests of the repository which contain the given commit.
+func (c *client) ListPullRequestsWithCommit(owner, repo, sha string) ([]*gogh.PullRequest, error) {
+	pullRequests := make([]*gogh.PullRequest, 0)
+
+	err := c.do(func(aroundCtx aroundContext) (func(), *gogh.Response, error) {
+		prsOpt := &gogh.PullRequestListOptions{ListOptions: *listOpts(aroundCtx)}
+		prs, response, e := c.gh.PullRequests.ListPullRequestsWithCommit(context.Background(), owner, repo, sha, prsOpt)
+		return func() {
+			pullRequests = append(pullRequests, prs...)
+		}, response, c.checkHTTPCode(response, e)
+	})
+
+	return pullRequests, err
+}
+
+// ListLanguages lists languages used in the repository together with the number of bytes of code written in each of them.
+func (c *client) ListLanguages(owner, repo string) (map[string]int, error) {
+	var repoLanguages map[string]int
+
+	err := c.do(func(aroundContext aroundContext) (func(), *gogh.Response, error) {
+		languages, response, e := c.gh.Repositories.ListLanguages(context.Background(), owner, repo)
+		return func() {
+			repoLanguages = languages
+		}, response, c.checkHTTPCode(response, e)
+	})
+
+	return repoLanguages, err
+}
+
+// GetIssue retrieves the specified issue (which might be also a pull request). scm.NotFoundError is returned when there
+// is no such issue or when it has been deleted.
+func (c *client) GetIssue(issue scm.RepositoryIssue) (*gogh.Issue, error) {
+	var (
+		repositoryIssue *gogh.Issue
+		notFound        bool
+	)
+
+	err := c.do(func(aroundContext aroundContext) (func(), *gogh.Response, error) {
+		i, response, e := c.gh.Issues.Get(context.Background(), issue.Owner, issue.RepoName, issue.Number)
+		if response != nil && (response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone) {
+			// missing issue is a valid answer, so there is no point in retrying the request
+			return func() {
+				notFound = true
+			}, response, nil
+		}
+		return func() {
+			repositoryIssue = i
+		}, response, c.checkHTTPCode(response, e)
+	})
+
+	if err == nil && notFound {
+		return nil, &scm.NotFoundError{Resource: fmt.Sprintf("%s/%s#%d", issue.Owner, issue.RepoName, issue.Number)}
+	}
+	return repositoryIssue, err
+}
+
 // ListIssueComments lists all comments on the specified issue.
 func (c *client) ListIssueComments(issue scm.RepositoryIssue) ([]*gogh.IssueComment, error) {
 	allComments := make([]*gogh.IssueComment, 0)
@@ -174,6 +316,35 @@ func (c *client) EditIssueComment(issue scm.RepositoryIssue, commentID int64, co
 	return err
 }
 
+// GetCombinedStatus retrieves the latest status of every context set for the reference represented by a RepositoryChange
+func (c *client) GetCombinedStatus(change scm.RepositoryChange) (*gogh.CombinedStatus, error) {
+	var combinedStatus *gogh.CombinedStatus
+
+	err := c.do(func(aroundContext aroundContext) (func(), *gogh.Response, error) {
+		status, response, e := c.gh.Repositories.GetCombinedStatus(context.Background(), change.Owner, change.RepoName,
+			change.Hash, &gogh.ListOptions{PerPage: 100})
+		return func() {
+			combinedStatus = status
+		}, response, c.checkHTTPCode(response, e)
+	})
+
+	return combinedStatus, err
+}
+
+// GetCommit retrieves the commit represented by a RepositoryChange together with the files it changes
+func (c *client) GetCommit(change scm.RepositoryChange) (*gogh.RepositoryCommit, error) {
+	var repositoryCommit *gogh.RepositoryCommit
+
+	err := c.do(func(aroundContext aroundContext) (func(), *gogh.Response, error) {
+		commit, response, e := c.gh.Repositories.GetCommit(context.Background(), change.Owner, change.RepoName, change.Hash)
+		return func() {
+			repositoryCommit = commit
+		}, response, c.checkHTTPCode(response, e)
+	})
+
+	return repositoryCommit, err
+}
+
 // CreateStatus creates a new status for a repository at the specified reference represented by a RepositoryChange
 func (c *client) CreateStatus(change scm.RepositoryChange, repoStatus *gogh.RepoStatus) error {
 	err := c.do(func(aroundContext aroundContext) (func(), *gogh.Response, error) {
@@ -185,6 +356,18 @@ func (c *client) CreateStatus(change scm.RepositoryChange, repoStatus *gogh.Repo
 	return err
 }
 
+// CreateCheckRun creates a new check run for a repository at the specified reference represented by a RepositoryChange
+func (c *client) CreateCheckRun(change scm.RepositoryChange, checkRun *gogh.CreateCheckRunOptions) error {
+	checkRun.HeadSHA = change.Hash
+	err := c.do(func(aroundContext aroundContext) (func(), *gogh.Response, error) {
+		_, response, e :=
+			c.gh.Checks.CreateCheckRun(context.Background(), change.Owner, change.RepoName, *checkRun)
+		return func() {}, response, c.checkHTTPCode(response, e)
+	})
+
+	return err
+}
+
 func (c *client) EditPullRequest(pr *gogh.PullRequest) error {
 	err := c.do(func(aroundContext aroundContext) (func(), *gogh.Response, error) {
 		_, response, e :=
@@ -213,6 +396,45 @@ func (c *client) RemovePullRequestLabel(change scm.RepositoryChange, prNumber in
 	return err
 }
 
+// GetFileContent retrieves the content of the file stored under the given path at the given ref (branch, tag or commit SHA).
+// The default branch of the repository is used when the ref is empty. scm.NotFoundError is returned when there is no such file.
+func (c *client) GetFileContent(owner, repo, ref, path string) ([]byte, error) {
+	var (
+		content  []byte
+		notFound bool
+	)
+
+	err := c.do(func(aroundContext aroundContext) (func(), *gogh.Response, error) {
+		file, _, response, e := c.gh.Repositories.GetContents(context.Background(), owner, repo, path,
+			&gogh.RepositoryContentGetOptions{Ref: ref})
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			// missing file is a valid answer, so there is no point in retrying the request
+			return func() {
+				notFound = true
+			}, response, nil
+		}
+		if e == nil && file == nil {
+			e = fmt.Errorf("%s is not a file", path)
+		}
+		if e != nil {
+			return func() {}, response, c.checkHTTPCode(response, e)
+		}
+		fileContent, e := file.GetContent()
+		return func() {
+			content = []byte(fileContent)
+		}, response, e
+	})
+
+	if err == nil && notFound {
+		resource := fmt.Sprintf("%s/%s/%s", owner, repo, path)
+		if ref != "" {
+			resource += "@" + ref
+		}
+		return nil, &scm.NotFoundError{Resource: resource}
+	}
+	return content, err
+}
+
 // GetRateLimits retrieves the rate limits for the current GH client
 func (c *client) GetRateLimit() (*gogh.RateLimits, error) {
 	limits, _, err := c.gh.RateLimits(context.Background())
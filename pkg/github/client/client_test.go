@@ -0,0 +1,360 @@
+package ghclient_test
+
+import (
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	gogh "github.com/google/go-github/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+var _ = Describe("GitHub client features", func() {
+
+	client := ghclient.NewClient(gogh.NewClient(nil), log.NewTestLogger())
+	client.RegisterAroundFunctions(
+		ghclient.NewRateLimitWatcher(client, log.NewTestLogger(), 100),
+		ghclient.NewRetryWrapper(3, 0),
+		ghclient.NewPaginationChecker())
+
+	Context("Retrieving content of the files stored in the repository", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should decode content of the file at the given ref", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/contents/.ike-prow/test-keeper.yml").
+				MatchParam("ref", "46cb8fac44709e4ccaae97448c65e8f7320cfea7").
+				Reply(200).
+				BodyString(FileContentResponse("test_patterns: ['*Test.java']"))
+
+			// when
+			content, err := client.GetFileContent("owner", "repo", "46cb8fac44709e4ccaae97448c65e8f7320cfea7", ".ike-prow/test-keeper.yml")
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(string(content)).To(Equal("test_patterns: ['*Test.java']"))
+		})
+
+		It("should return not found error without retrying when there is no such file", func() {
+			// given
+			calls := 0
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/contents/.ike-prow/test-keeper.yml").
+				SetMatcher(spyOnCalls(&calls)).
+				Persist().
+				Reply(404).
+				BodyString(`{"message": "Not Found"}`)
+
+			// when
+			content, err := client.GetFileContent("owner", "repo", "46cb8fac44709e4ccaae97448c65e8f7320cfea7", ".ike-prow/test-keeper.yml")
+
+			// then
+			Expect(scm.IsNotFound(err)).To(BeTrue())
+			Expect(content).To(BeNil())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should fail when the file cannot be retrieved", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/contents/.ike-prow/test-keeper.yml").
+				Times(3).
+				Reply(500).
+				BodyString(`{"message": "Server Error"}`)
+
+			// when
+			_, err := client.GetFileContent("owner", "repo", "46cb8fac44709e4ccaae97448c65e8f7320cfea7", ".ike-prow/test-keeper.yml")
+
+			// then
+			Ω(err).Should(HaveOccurred())
+			Expect(scm.IsNotFound(err)).To(BeFalse())
+		})
+
+		It("should fail when the path points to a directory", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/contents/.ike-prow").
+				Reply(200).
+				BodyString(`[{"type": "file", "name": "test-keeper.yml"}]`)
+
+			// when
+			_, err := client.GetFileContent("owner", "repo", "46cb8fac44709e4ccaae97448c65e8f7320cfea7", ".ike-prow")
+
+			// then
+			Ω(err).Should(MatchError(ContainSubstring(".ike-prow is not a file")))
+		})
+	})
+
+	Context("Listing merged pull requests", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should list only merged pull requests from the first page limited to the given number", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/pulls").
+				MatchParam("state", "closed").
+				MatchParam("sort", "updated").
+				MatchParam("direction", "desc").
+				Reply(200).
+				SetHeader("Link", `<https://api.github.com/repos/owner/repo/pulls?page=2>; rel="next"`).
+				BodyString(`[{"number": 4, "merged_at": "2018-06-04T10:00:00Z"},
+					{"number": 3, "merged_at": null},
+					{"number": 2, "merged_at": "2018-06-02T10:00:00Z"},
+					{"number": 1, "merged_at": "2018-06-01T10:00:00Z"}]`)
+
+			// when
+			prs, err := client.ListMergedPullRequests("owner", "repo", 2)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(prs).To(HaveLen(2))
+			Expect(prs[0].GetNumber()).To(Equal(4))
+			Expect(prs[1].GetNumber()).To(Equal(2))
+		})
+	})
+
+	Context("Listing open pull requests", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should list open pull requests from all pages", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/pulls").
+				MatchParam("state", "open").
+				MatchParam("page", "1").
+				Reply(200).
+				SetHeader("Link", `<https://api.github.com/repos/owner/repo/pulls?state=open&page=2>; rel="next"`).
+				BodyString(`[{"number": 5, "head": {"sha": "3b0cd6e2a1bbd3c8a4fe36a1a46e0cda23fa0faf"}}]`)
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/pulls").
+				MatchParam("state", "open").
+				MatchParam("page", "2").
+				Reply(200).
+				BodyString(`[{"number": 3, "head": {"sha": "46cb8fac44709e4ccaae97448c65e8f7320cfea7"}}]`)
+
+			// when
+			prs, err := client.ListOpenPullRequests("owner", "repo")
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(prs).To(HaveLen(2))
+			Expect(prs[0].GetNumber()).To(Equal(5))
+			Expect(prs[1].GetHead().GetSHA()).To(Equal("46cb8fac44709e4ccaae97448c65e8f7320cfea7"))
+		})
+	})
+
+	Context("Listing pull requests with commit", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should list pull requests containing the commit", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/commits/46cb8fac44709e4ccaae97448c65e8f7320cfea7/pulls").
+				MatchParam("page", "1").
+				Reply(200).
+				BodyString(`[{"number": 5, "state": "open", "head": {"sha": "46cb8fac44709e4ccaae97448c65e8f7320cfea7"}}]`)
+
+			// when
+			prs, err := client.ListPullRequestsWithCommit("owner", "repo", "46cb8fac44709e4ccaae97448c65e8f7320cfea7")
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(prs).To(HaveLen(1))
+			Expect(prs[0].GetNumber()).To(Equal(5))
+		})
+	})
+
+	Context("Listing pull request commits", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should list commits of the pull request from all pages", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/pulls/1/commits").
+				MatchParam("page", "1").
+				Reply(200).
+				SetHeader("Link", `<https://api.github.com/repos/owner/repo/pulls/1/commits?page=2>; rel="next"`).
+				BodyString(`[{"sha": "3b0cd6e2a1bbd3c8a4fe36a1a46e0cda23fa0faf", "commit": {"message": "feat: adds endpoint"}}]`)
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/pulls/1/commits").
+				MatchParam("page", "2").
+				Reply(200).
+				BodyString(`[{"sha": "46cb8fac44709e4ccaae97448c65e8f7320cfea7", "commit": {"message": "fix: handles nil"}}]`)
+
+			// when
+			commits, err := client.ListPullRequestCommits("owner", "repo", 1)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(commits).To(HaveLen(2))
+			Expect(commits[0].GetCommit().GetMessage()).To(Equal("feat: adds endpoint"))
+			Expect(commits[1].GetSHA()).To(Equal("46cb8fac44709e4ccaae97448c65e8f7320cfea7"))
+		})
+	})
+
+	Context("Retrieving issue", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should retrieve the issue with its state", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/issues/2").
+				Reply(200).
+				BodyString(`{"number": 2, "state": "closed"}`)
+
+			// when
+			issue, err := client.GetIssue(scm.RepositoryIssue{Owner: "owner", RepoName: "repo", Number: 2})
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(issue.GetState()).To(Equal("closed"))
+			Expect(issue.IsPullRequest()).To(BeFalse())
+		})
+
+		It("should return not found error without retrying when there is no such issue", func() {
+			// given
+			calls := 0
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/issues/2").
+				SetMatcher(spyOnCalls(&calls)).
+				Persist().
+				Reply(404).
+				BodyString(`{"message": "Not Found"}`)
+
+			// when
+			issue, err := client.GetIssue(scm.RepositoryIssue{Owner: "owner", RepoName: "repo", Number: 2})
+
+			// then
+			Expect(scm.IsNotFound(err)).To(BeTrue())
+			Expect(err).To(MatchError("owner/repo#2 not found"))
+			Expect(issue).To(BeNil())
+			Expect(calls).To(Equal(1))
+		})
+	})
+
+	Context("Listing languages", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should list languages of the repository", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/languages").
+				Reply(200).
+				BodyString(`{"Java": 24680, "Shell": 135}`)
+
+			// when
+			languages, err := client.ListLanguages("owner", "repo")
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(languages).To(Equal(map[string]int{"Java": 24680, "Shell": 135}))
+		})
+	})
+
+	Context("Retrieving combined status", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should retrieve latest statuses of the commit", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/commits/46cb8fac44709e4ccaae97448c65e8f7320cfea7/status").
+				Reply(200).
+				BodyString(`{"state": "success", "statuses": [` +
+					`{"context": "ci/coverage", "state": "success", "target_url": "https://ci.example.com/coverage.xml"}]}`)
+
+			// when
+			status, err := client.GetCombinedStatus(scm.RepositoryChange{
+				Owner: "owner", RepoName: "repo", Hash: "46cb8fac44709e4ccaae97448c65e8f7320cfea7"})
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(status.Statuses).To(HaveLen(1))
+			Expect(status.Statuses[0].GetTargetURL()).To(Equal("https://ci.example.com/coverage.xml"))
+		})
+	})
+
+	Context("Retrieving commit", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should retrieve the commit with its date and changed files", func() {
+			// given
+			mockHighRateLimit()
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/commits/46cb8fac44709e4ccaae97448c65e8f7320cfea7").
+				Reply(200).
+				BodyString(`{"sha": "46cb8fac44709e4ccaae97448c65e8f7320cfea7",` +
+					`"commit": {"committer": {"date": "2018-06-05T10:00:00Z"}},` +
+					`"files": [{"filename": "pkg/plugin.go", "status": "modified", "additions": 3, "deletions": 1}]}`)
+
+			// when
+			commit, err := client.GetCommit(scm.RepositoryChange{
+				Owner: "owner", RepoName: "repo", Hash: "46cb8fac44709e4ccaae97448c65e8f7320cfea7"})
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(commit.GetCommit().GetCommitter().GetDate().UTC().Hour()).To(Equal(10))
+			Expect(commit.Files).To(HaveLen(1))
+			Expect(commit.Files[0].GetFilename()).To(Equal("pkg/plugin.go"))
+		})
+	})
+})
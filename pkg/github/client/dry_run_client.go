@@ -0,0 +1,168 @@
+package ghclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	gogh "github.com/google/go-github/github"
+)
+
+// MaxRecordedMutations is the number of the most recent mutations kept by DryRunClient
+const MaxRecordedMutations = 1000
+
+// Mutation is a change which would have been made in GitHub if the client was not running in dry-run mode
+type Mutation struct {
+	Time      time.Time              `json:"time"`
+	Operation string                 `json:"operation"`
+	Repo      string                 `json:"repo"`
+	Number    int                    `json:"number,omitempty"`
+	Ref       string                 `json:"ref,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// DryRunClient is a Client which passes all reads to the delegate, but only records and logs mutating operations
+// instead of sending them to GitHub.
+type DryRunClient struct {
+	Client
+	logger    log.Logger
+	mutex     sync.RWMutex
+	mutations []Mutation
+}
+
+// NewDryRunClient creates a DryRunClient which reads using the given delegate
+func NewDryRunClient(delegate Client, logger log.Logger) *DryRunClient {
+	return &DryRunClient{Client: delegate, logger: logger}
+}
+
+// Mutations returns recorded mutations (from the oldest to the newest one) done in the given repository.
+// When repo is empty mutations done in all repositories are returned
+func (c *DryRunClient) Mutations(repo string) []Mutation {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	mutations := make([]Mutation, 0, len(c.mutations))
+	for _, mutation := range c.mutations {
+		if repo == "" || mutation.Repo == repo {
+			mutations = append(mutations, mutation)
+		}
+	}
+	return mutations
+}
+
+// CreateIssueComment records creation of a new comment on the specified issue.
+func (c *DryRunClient) CreateIssueComment(issue scm.RepositoryIssue, commentMsg *string) error {
+	c.record(Mutation{
+		Operation: "CreateIssueComment",
+		Repo:      fullName(issue.Owner, issue.RepoName),
+		Number:    issue.Number,
+		Details:   map[string]interface{}{"body": (&gogh.IssueComment{Body: commentMsg}).GetBody()},
+	})
+	return nil
+}
+
+// EditIssueComment records edition of an already existing comment in the given issue.
+func (c *DryRunClient) EditIssueComment(issue scm.RepositoryIssue, commentID int64, commentMsg *string) error {
+	c.record(Mutation{
+		Operation: "EditIssueComment",
+		Repo:      fullName(issue.Owner, issue.RepoName),
+		Number:    issue.Number,
+		Details:   map[string]interface{}{"comment_id": commentID, "body": (&gogh.IssueComment{Body: commentMsg}).GetBody()},
+	})
+	return nil
+}
+
+// CreateStatus records creation of a new status at the specified reference represented by a RepositoryChange
+func (c *DryRunClient) CreateStatus(change scm.RepositoryChange, repoStatus *gogh.RepoStatus) error {
+	c.record(Mutation{
+		Operation: "CreateStatus",
+		Repo:      fullName(change.Owner, change.RepoName),
+		Ref:       change.Hash,
+		Details: map[string]interface{}{
+			"state":       repoStatus.GetState(),
+			"context":     repoStatus.GetContext(),
+			"description": repoStatus.GetDescription(),
+			"target_url":  repoStatus.GetTargetURL(),
+		},
+	})
+	return nil
+}
+
+// CreateCheckRun records creation of a new check run at the specified reference represented by a RepositoryChange
+func (c *DryRunClient) CreateCheckRun(change scm.RepositoryChange, checkRun *gogh.CreateCheckRunOptions) error {
+	details := map[string]interface{}{
+		"name":        checkRun.Name,
+		"status":      checkRun.GetStatus(),
+		"conclusion":  checkRun.GetConclusion(),
+		"details_url": checkRun.GetDetailsURL(),
+	}
+	if checkRun.Output != nil {
+		details["title"] = checkRun.Output.GetTitle()
+		details["annotations"] = len(checkRun.Output.Annotations)
+	}
+	c.record(Mutation{
+		Operation: "CreateCheckRun",
+		Repo:      fullName(change.Owner, change.RepoName),
+		Ref:       change.Hash,
+		Details:   details,
+	})
+	return nil
+}
+
+// EditPullRequest records edition of the pull request
+func (c *DryRunClient) EditPullRequest(pr *gogh.PullRequest) error {
+	c.record(Mutation{
+		Operation: "EditPullRequest",
+		Repo:      pr.GetBase().GetRepo().GetFullName(),
+		Number:    pr.GetNumber(),
+		Details:   map[string]interface{}{"title": pr.GetTitle()},
+	})
+	return nil
+}
+
+// AddPullRequestLabel records adding labels to the pull request
+func (c *DryRunClient) AddPullRequestLabel(change scm.RepositoryChange, prNumber int, label []string) error {
+	c.record(Mutation{
+		Operation: "AddPullRequestLabel",
+		Repo:      fullName(change.Owner, change.RepoName),
+		Number:    prNumber,
+		Details:   map[string]interface{}{"labels": label},
+	})
+	return nil
+}
+
+// RemovePullRequestLabel records removal of the label from the pull request
+func (c *DryRunClient) RemovePullRequestLabel(change scm.RepositoryChange, prNumber int, label string) error {
+	c.record(Mutation{
+		Operation: "RemovePullRequestLabel",
+		Repo:      fullName(change.Owner, change.RepoName),
+		Number:    prNumber,
+		Details:   map[string]interface{}{"label": label},
+	})
+	return nil
+}
+
+func (c *DryRunClient) record(mutation Mutation) {
+	mutation.Time = time.Now()
+
+	c.mutex.Lock()
+	c.mutations = append(c.mutations, mutation)
+	if len(c.mutations) > MaxRecordedMutations {
+		c.mutations = c.mutations[len(c.mutations)-MaxRecordedMutations:]
+	}
+	c.mutex.Unlock()
+
+	entry, err := json.Marshal(mutation)
+	if err != nil {
+		c.logger.Errorf("dry-run: would have done %s in %s. unable to marshal details: %s", mutation.Operation, mutation.Repo, err)
+		return
+	}
+	c.logger.Warnf("dry-run: would have done %s", entry)
+}
+
+func fullName(owner, repoName string) string {
+	return fmt.Sprintf("%s/%s", owner, repoName)
+}
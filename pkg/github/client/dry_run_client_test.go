@@ -0,0 +1,89 @@
+package ghclient_test
+
+import (
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	gogh "github.com/google/go-github/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+var _ = Describe("Dry-run client features", func() {
+
+	var client *ghclient.DryRunClient
+
+	BeforeEach(func() {
+		gock.Intercept()
+		delegate := ghclient.NewClient(gogh.NewClient(nil), log.NewTestLogger())
+		delegate.RegisterAroundFunctions(ghclient.NewPaginationChecker())
+		client = ghclient.NewDryRunClient(delegate, log.NewTestLogger())
+	})
+
+	AfterEach(func() {
+		EnsureGockRequestsHaveBeenMatched()
+		gock.OffAll()
+	})
+
+	It("should pass reads to the delegate", func() {
+		// given
+		gock.New("https://api.github.com").
+			Get("/repos/owner/repo/pulls/1").
+			Reply(200).
+			BodyString(`{"number": 1, "title": "Fix the bug"}`)
+
+		// when
+		pr, err := client.GetPullRequest("owner", "repo", 1)
+
+		// then
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(pr.GetTitle()).To(Equal("Fix the bug"))
+	})
+
+	It("should record mutations instead of sending them to GitHub", func() {
+		// given
+		change := scm.RepositoryChange{Owner: "owner", RepoName: "repo", Hash: "46cb8fac44709e4ccaae97448c65e8f7320cfea7"}
+		issue := scm.RepositoryIssue{Owner: "owner", RepoName: "repo", Number: 1}
+
+		// when
+		Ω(client.CreateStatus(change, &gogh.RepoStatus{State: gogh.String("success"), Context: gogh.String("alien-ike/test-keeper")})).Should(Succeed())
+		Ω(client.CreateIssueComment(issue, gogh.String("Hello"))).Should(Succeed())
+		Ω(client.EditIssueComment(issue, 42, gogh.String("Hello again"))).Should(Succeed())
+		Ω(client.AddPullRequestLabel(change, 1, []string{"work-in-progress"})).Should(Succeed())
+		Ω(client.RemovePullRequestLabel(change, 1, "work-in-progress")).Should(Succeed())
+
+		// then
+		mutations := client.Mutations("")
+		Expect(mutations).To(HaveLen(5))
+		Expect(mutations[0].Operation).To(Equal("CreateStatus"))
+		Expect(mutations[0].Ref).To(Equal(change.Hash))
+		Expect(mutations[0].Details).To(HaveKeyWithValue("state", "success"))
+		Expect(mutations[1].Operation).To(Equal("CreateIssueComment"))
+		Expect(mutations[1].Number).To(Equal(1))
+		Expect(mutations[1].Details).To(HaveKeyWithValue("body", "Hello"))
+		Expect(mutations[2].Details).To(HaveKeyWithValue("comment_id", int64(42)))
+		Expect(mutations[3].Operation).To(Equal("AddPullRequestLabel"))
+		Expect(mutations[4].Operation).To(Equal("RemovePullRequestLabel"))
+	})
+
+	It("should filter recorded mutations by repository", func() {
+		// given
+		pr := &gogh.PullRequest{
+			Number: gogh.Int(2),
+			Title:  gogh.String("WIP fix the bug"),
+			Base:   &gogh.PullRequestBranch{Repo: &gogh.Repository{FullName: gogh.String("owner/other")}},
+		}
+		Ω(client.CreateIssueComment(scm.RepositoryIssue{Owner: "owner", RepoName: "repo", Number: 1}, gogh.String("Hello"))).Should(Succeed())
+
+		// when
+		Ω(client.EditPullRequest(pr)).Should(Succeed())
+
+		// then
+		mutations := client.Mutations("owner/other")
+		Expect(mutations).To(HaveLen(1))
+		Expect(mutations[0].Operation).To(Equal("EditPullRequest"))
+		Expect(mutations[0].Details).To(HaveKeyWithValue("title", "WIP fix the bug"))
+	})
+})
@@ -0,0 +1,59 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Endpoints is a set of base URLs of the GitHub instance the plugins talk to. Each of them ends with a slash.
+type Endpoints struct {
+	API string // REST API
+	Web string // web UI, used for links to the files
+}
+
+// DefaultEndpoints are the ones of github.com
+var DefaultEndpoints = Endpoints{
+	API: "https://api.github.com/",
+	Web: "https://github.com/",
+}
+
+var endpoints = DefaultEndpoints
+
+// NewEndpoints derives the whole set of endpoints from the given API URL. For GitHub Enterprise, which serves its API
+// under /api/v3/ path, the web UI is served from the root of the instance.
+func NewEndpoints(apiURL string) (Endpoints, error) {
+	api, err := url.Parse(apiURL)
+	if err != nil {
+		return Endpoints{}, err
+	}
+	if api.Scheme == "" || api.Host == "" {
+		return Endpoints{}, fmt.Errorf("%q is not an absolute URL", apiURL)
+	}
+	if api.Host == "api.github.com" {
+		return DefaultEndpoints, nil
+	}
+
+	root := fmt.Sprintf("%s://%s/", api.Scheme, api.Host)
+	return Endpoints{API: withTrailingSlash(apiURL), Web: root}, nil
+}
+
+// UseEndpoints sets the endpoints used by the plugins. It is expected to be called once, when the plugin starts.
+func UseEndpoints(e Endpoints) {
+	endpoints = Endpoints{
+		API: withTrailingSlash(e.API),
+		Web: withTrailingSlash(e.Web),
+	}
+}
+
+// CurrentEndpoints returns the endpoints used by the plugins, which are DefaultEndpoints unless set by UseEndpoints
+func CurrentEndpoints() Endpoints {
+	return endpoints
+}
+
+func withTrailingSlash(u string) string {
+	if strings.HasSuffix(u, "/") {
+		return u
+	}
+	return u + "/"
+}
@@ -0,0 +1,39 @@
+package github_test
+
+import (
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GitHub endpoints", func() {
+
+	It("should use github.com endpoints for public API", func() {
+		// when
+		endpoints, err := github.NewEndpoints("https://api.github.com")
+
+		// then
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(endpoints).To(Equal(github.DefaultEndpoints))
+	})
+
+	It("should derive web endpoint from GitHub Enterprise API", func() {
+		// when
+		endpoints, err := github.NewEndpoints("https://github.example.com/api/v3")
+
+		// then
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(endpoints).To(Equal(github.Endpoints{
+			API: "https://github.example.com/api/v3/",
+			Web: "https://github.example.com/",
+		}))
+	})
+
+	It("should reject relative API URL", func() {
+		// when
+		_, err := github.NewEndpoints("github.example.com/api/v3")
+
+		// then
+		Ω(err).Should(HaveOccurred())
+	})
+})
@@ -4,17 +4,39 @@ import (
 	"fmt"
 
 	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
 	"github.com/arquillian/ike-prow-plugins/pkg/scm"
-	"github.com/arquillian/ike-prow-plugins/pkg/utils"
 )
 
-const githubBaseURL = "https://github.com/"
-
 // ConfigHome is a directory to keep prow configuration files
 const ConfigHome = ".ike-prow/"
 
+// defaultBranch is a ref used in links to the files of the organization config repository, which are always read from
+// its default branch
+const defaultBranch = "HEAD"
+
+var organizationConfigRepository string
+
+// UseOrganizationConfigRepository sets the name of the repository which holds organization-wide defaults of the plugins
+// configuration (such as .ike-prow). Organization defaults are not looked up when the name is empty, which is the default.
+// It is expected to be called once, when the plugin starts.
+func UseOrganizationConfigRepository(repoName string) {
+	organizationConfigRepository = repoName
+}
+
+// ChangesConfiguration checks if any of the given changed files is a configuration file of the plugin stored in ConfigHome
+func ChangesConfiguration(pluginName string, files []scm.ChangedFile) bool {
+	for _, file := range files {
+		if file.Name == ConfigHome+pluginName+".yml" || file.Name == ConfigHome+pluginName+".yaml" {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadableConfig holds information about the plugin name, repository change and pointer to base config
 type LoadableConfig struct {
+	Client     ghclient.Client
 	PluginName string
 	Change     scm.RepositoryChange
 	BaseConfig *config.PluginConfiguration
@@ -24,29 +46,63 @@ type LoadableConfig struct {
 // revision. Two files are expected to be found there plugin-name.yml or plugin-name.yaml (in that order)
 func (l *LoadableConfig) Sources() []config.Source {
 	return []config.Source{
-		l.loadFromRawFile(ConfigHome + "%s.yml"),
-		l.loadFromRawFile(ConfigHome + "%s.yaml"),
+		l.loadFromRepository(ConfigHome + "%s.yml"),
+		l.loadFromRepository(ConfigHome + "%s.yaml"),
 	}
 }
 
-func (l *LoadableConfig) loadFromRawFile(pathTemplate string) config.Source {
+// Layers provides organization-wide defaults followed by the configuration of the repository itself (see Sources).
+// The defaults are looked up in the root of the organization config repository on its default branch, using the same
+// file names as in the repository
+func (l *LoadableConfig) Layers() []config.SourcesProvider {
+	if organizationConfigRepository == "" {
+		return []config.SourcesProvider{l}
+	}
+	organizationDefaults := sources{
+		l.loadFromOrganization("%s.yml"),
+		l.loadFromOrganization("%s.yaml"),
+	}
+	return []config.SourcesProvider{organizationDefaults, l}
+}
 
-	filePath := fmt.Sprintf(pathTemplate, l.PluginName)
+func (l *LoadableConfig) loadFromRepository(pathTemplate string) config.Source {
+	return l.loadFile(l.Change, l.Change.Hash, fmt.Sprintf(pathTemplate, l.PluginName))
+}
+
+func (l *LoadableConfig) loadFromOrganization(pathTemplate string) config.Source {
+	defaults := scm.RepositoryChange{
+		Owner:    l.Change.Owner,
+		RepoName: organizationConfigRepository,
+		Hash:     defaultBranch,
+	}
+	return l.loadFile(defaults, "", fmt.Sprintf(pathTemplate, l.PluginName))
+}
+
+func (l *LoadableConfig) loadFile(change scm.RepositoryChange, ref, filePath string) config.Source {
 
 	rawFileService := RawFileService{
-		Change: l.Change,
+		Change: change,
 	}
 
 	return func() ([]byte, error) {
-		configURL := rawFileService.GetRawFileURL(filePath)
-		downloadedConfig, err := utils.GetFileFromURL(configURL)
+		downloadedConfig, err := l.Client.GetFileContent(change.Owner, change.RepoName, ref, filePath)
 		l.BaseConfig.PluginName = l.PluginName
 
-		if err != nil {
+		if scm.IsNotFound(err) {
 			return nil, err
 		}
-		l.BaseConfig.LocationURL = githubBaseURL + rawFileService.GetRelativePath(filePath, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s. cause: %s", rawFileService.GetRelativePath(filePath, false), err)
+		}
+		l.BaseConfig.LocationURL = rawFileService.GetFileURL(filePath)
+		l.BaseConfig.LocationURLs = append(l.BaseConfig.LocationURLs, l.BaseConfig.LocationURL)
 
 		return downloadedConfig, nil
 	}
 }
+
+type sources []config.Source
+
+func (s sources) Sources() []config.Source {
+	return s
+}
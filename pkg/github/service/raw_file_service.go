@@ -3,19 +3,18 @@ package ghservice
 import (
 	"fmt"
 
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
 	"github.com/arquillian/ike-prow-plugins/pkg/scm"
 )
 
-const rawURL = "https://raw.githubusercontent.com/%s"
-
 // RawFileService encapsulates retrieval of files in the given GitHub repository change
 type RawFileService struct {
 	Change scm.RepositoryChange
 }
 
-// GetRawFileURL creates a url to the given path related to the GitHub repository change
-func (s *RawFileService) GetRawFileURL(path string) string {
-	return fmt.Sprintf(rawURL, s.GetRelativePath(path, false))
+// GetFileURL creates a url to the page presenting the given path related to the GitHub repository change
+func (s *RawFileService) GetFileURL(path string) string {
+	return github.CurrentEndpoints().Web + s.GetRelativePath(path, true)
 }
 
 // GetRelativePath creates repository specific relative path
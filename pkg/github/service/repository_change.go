@@ -13,3 +13,12 @@ func NewRepositoryChangeForPR(pr *gogh.PullRequest) scm.RepositoryChange {
 		Hash:     *pr.Head.SHA,
 	}
 }
+
+// NewRepositoryChangeForPRBase creates a RepositoryChange instance for the commit the given pull request is based on
+func NewRepositoryChangeForPRBase(pr *gogh.PullRequest) scm.RepositoryChange {
+	return scm.RepositoryChange{
+		Owner:    *pr.Base.Repo.Owner.Login,
+		RepoName: *pr.Base.Repo.Name,
+		Hash:     *pr.Base.SHA,
+	}
+}
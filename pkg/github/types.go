@@ -34,7 +34,20 @@ const (
 	ActionUnlabeled = "unlabeled"
 )
 
+// ActionRequested is the action of the Check Run Event Type sent when the user requests an action offered by the check run
+const ActionRequested = "requested_action"
+
+// These are possible statuses and conclusions of a Check Run.
+const (
+	CheckRunInProgress = "in_progress"
+	CheckRunCompleted  = "completed"
+	ConclusionSuccess  = "success"
+	ConclusionFailure  = "failure"
+)
+
 const (
 	IssueComment = EventType("issue_comment") // nolint
 	PullRequest  = EventType("pull_request")  // nolint
+	CheckRun     = EventType("check_run")     // nolint
+	Status       = EventType("status")        // nolint
 )
@@ -1,9 +1,13 @@
 package test
 
 import (
+	"encoding/base64"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
 	"github.com/onsi/gomega"
 	gock "gopkg.in/h2non/gock.v1"
 )
@@ -13,15 +17,28 @@ func EnsureGockRequestsHaveBeenMatched() {
 	gomega.Expect(gock.GetUnmatchedRequests()).To(gomega.BeEmpty(), "Have no unmatched requests")
 }
 
-// NonExistingRawGitHubFiles mocks any matching path suffix when calling "https://raw.githubusercontent.com" with 404 response
+// NonExistingRawGitHubFiles mocks any matching path suffix when retrieving files using contents API with 404 response
 func NonExistingRawGitHubFiles(pathSuffixes ...string) {
 	for _, pathSuffix := range pathSuffixes {
-		gock.New("https://raw.githubusercontent.com").
+		gock.New(gitHubAPI().String()).
 			SetMatcher(fileRequested(pathSuffix)).
 			Reply(404)
 	}
 }
 
+// FileContentResponse creates a body of the contents API response for a file with the given content
+func FileContentResponse(content string) string {
+	return fmt.Sprintf(`{"type": "file", "encoding": "base64", "content": %q}`,
+		base64.StdEncoding.EncodeToString([]byte(content)))
+}
+
+// gitHubAPI returns URL of the API of the GitHub instance set by github.UseEndpoints
+func gitHubAPI() *url.URL {
+	api, err := url.Parse(github.CurrentEndpoints().API)
+	gomega.Expect(err).NotTo(gomega.HaveOccurred())
+	return api
+}
+
 func fileRequested(pathSuffix string) gock.Matcher {
 	matcher := gock.NewBasicMatcher()
 	matcher.Add(func(req *http.Request, _ *gock.Request) (bool, error) { // nolint:unparam
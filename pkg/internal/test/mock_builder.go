@@ -3,6 +3,7 @@ package test
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/arquillian/ike-prow-plugins/pkg/command"
 	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
@@ -27,6 +28,7 @@ func NewMockPluginTemplate(pluginName string) MockPluginTemplate {
 type MockPrBuilder struct {
 	pluginName   string
 	pullRequest  *gogh.PullRequest
+	languages    string
 	mockCreators []MockCreator
 	errors       []error
 }
@@ -50,7 +52,7 @@ func (l *MockPrBuilderLoader) LoadedFrom(jsonPath string) *MockPrBuilder {
 }
 
 func (l *MockPrBuilderLoader) load(jsonContent string) *MockPrBuilder {
-	builder := &MockPrBuilder{pluginName: l.pluginName}
+	builder := &MockPrBuilder{pluginName: l.pluginName, languages: "{}"}
 	if err := json.Unmarshal([]byte(jsonContent), &builder.pullRequest); err != nil {
 		builder.errors = []error{err}
 	}
@@ -61,6 +63,7 @@ func (l *MockPrBuilderLoader) load(jsonContent string) *MockPrBuilder {
 				builder.errors = append(builder.errors, err)
 			}
 			builder.baseGetMock(fmt.Sprintf("%s/pulls/%d", builder.baseRepoPath(), *builder.pullRequest.Number), string(content))
+			builder.baseGetMock(builder.baseRepoPath()+"/languages", builder.languages)
 		},
 	}
 	return builder
@@ -183,6 +186,34 @@ func (pr *PrMock) CreatePullRequestEvent(action string) *gogh.PullRequestEvent {
 	}
 }
 
+// CreateCheckRunEvent based on the mocked PR information creates a CheckRunEvent requesting the given action
+// from the check run of the given name
+func (pr *PrMock) CreateCheckRunEvent(userCreator SenderCreator, checkRunName, actionIdentifier string) *gogh.CheckRunEvent {
+	return &gogh.CheckRunEvent{
+		Action: utils.String("requested_action"),
+		CheckRun: &gogh.CheckRun{
+			Name:         utils.String(checkRunName),
+			HeadSHA:      pr.PullRequest.Head.SHA,
+			PullRequests: []*gogh.PullRequest{{Number: pr.PullRequest.Number}},
+		},
+		RequestedAction: &gogh.RequestedAction{Identifier: actionIdentifier},
+		Repo:            pr.PullRequest.Base.Repo,
+		Sender:          userCreator(pr.PullRequest),
+	}
+}
+
+// CreateStatusEvent based on the mocked PR information creates a StatusEvent of the given context and state set for
+// the head commit of the PR
+func (pr *PrMock) CreateStatusEvent(context, state, targetURL string) *gogh.StatusEvent {
+	return &gogh.StatusEvent{
+		SHA:       pr.PullRequest.Head.SHA,
+		Context:   utils.String(context),
+		State:     utils.String(state),
+		TargetURL: utils.String(targetURL),
+		Repo:      pr.PullRequest.Base.Repo,
+	}
+}
+
 // PermissionForUser based on the mocked PR information creates an instance of PermissionService
 func (pr *PrMock) PermissionForUser(userName string) *PermissionServiceMocker {
 	return &PermissionServiceMocker{userName: userName, pr: pr.PullRequest}
@@ -225,8 +256,10 @@ func (b *SubMockBuilder) ToChange(change scm.RepositoryChange) {
 }
 
 func baseGockMock(method RequestOption, options ...RequestOption) *gock.Request {
-	request := gock.New("https://api.github.com")
+	api := gitHubAPI()
+	request := gock.New(fmt.Sprintf("%s://%s", api.Scheme, api.Host))
 	method(request)
+	request.URLStruct.Path = strings.TrimSuffix(api.Path, "/") + request.URLStruct.Path
 
 	for _, opt := range options {
 		opt(request)
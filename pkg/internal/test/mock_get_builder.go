@@ -29,6 +29,72 @@ func (b *MockPrBuilder) mockFiles(content string, options ...RequestOption) {
 	b.addMockCreator(b.mockGetForPR("pulls", "/files", content, options...))
 }
 
+// WithLanguages sets the given payload containing languages of the repository the mocked PR belongs to.
+// By default the repository has no language
+func (b *MockPrBuilder) WithLanguages(jsonContent string) *MockPrBuilder {
+	b.languages = jsonContent
+	return b
+}
+
+// WithCombinedStatus sets the given payload containing statuses set for the head commit of the mocked PR
+func (b *MockPrBuilder) WithCombinedStatus(jsonContent string) *MockPrBuilder {
+	b.addMockCreator(func(builder *MockPrBuilder) {
+		b.baseGetMock(fmt.Sprintf("%s/commits/%s/status", b.baseRepoPath(), *b.pullRequest.Head.SHA), jsonContent)
+	})
+	return b
+}
+
+// WithCommit sets the given payload containing the commit of the given SHA together with its changed files
+func (b *MockPrBuilder) WithCommit(sha, jsonContent string) *MockPrBuilder {
+	b.addMockCreator(func(builder *MockPrBuilder) {
+		b.baseGetMock(fmt.Sprintf("%s/commits/%s", b.baseRepoPath(), sha), jsonContent)
+	})
+	return b
+}
+
+// WithMergedPullRequests sets the given payload containing closed pull requests of the repository the mocked PR belongs to
+func (b *MockPrBuilder) WithMergedPullRequests(jsonContent string) *MockPrBuilder {
+	b.addMockCreator(func(builder *MockPrBuilder) {
+		b.baseGetMock(b.baseRepoPath()+"/pulls", jsonContent, func(request *gock.Request) {
+			request.MatchParam("state", "closed")
+		})
+	})
+	return b
+}
+
+// WithPullRequestsOfHeadCommit mocks the list of pull requests containing the head commit of the mocked PR, which
+// contains only the mocked PR
+func (b *MockPrBuilder) WithPullRequestsOfHeadCommit() *MockPrBuilder {
+	b.addMockCreator(func(builder *MockPrBuilder) {
+		content, err := json.Marshal([]*gogh.PullRequest{builder.pullRequest})
+		if err != nil {
+			builder.errors = append(builder.errors, err)
+		}
+		b.baseGetMock(fmt.Sprintf("%s/commits/%s/pulls", b.baseRepoPath(), *b.pullRequest.Head.SHA), string(content))
+	})
+	return b
+}
+
+// WithOpenPullRequestsContaining sets the given payload containing open pull requests of the repository the mocked PR
+// belongs to
+func (b *MockPrBuilder) WithOpenPullRequestsContaining(jsonContent string) *MockPrBuilder {
+	b.addMockCreator(func(builder *MockPrBuilder) {
+		b.baseGetMock(b.baseRepoPath()+"/pulls", jsonContent, func(request *gock.Request) {
+			request.MatchParam("state", "open")
+		})
+	})
+	return b
+}
+
+// WithFilesOfPullRequest sets the given payload containing changed files to another pull request (e.g. merged one)
+// of the repository the mocked PR belongs to
+func (b *MockPrBuilder) WithFilesOfPullRequest(number int, jsonContent string) *MockPrBuilder {
+	b.addMockCreator(func(builder *MockPrBuilder) {
+		b.baseGetMock(fmt.Sprintf("%s/pulls/%d/files", b.baseRepoPath(), number), jsonContent, perPage100, page1)
+	})
+	return b
+}
+
 // WithComments sets the given payload containing comments to the mocked PR
 func (b *MockPrBuilder) WithComments(jsonContent string, options ...RequestOption) *MockPrBuilder {
 	b.mockComments(jsonContent, options...)
@@ -67,6 +133,35 @@ func (b *MockPrBuilder) mockReviews(content string, options ...RequestOption) {
 	b.addMockCreator(b.mockGetForPR("pulls", "/reviews", content, options...))
 }
 
+// WithCommits sets the given payload containing list of commits to the mocked PR
+func (b *MockPrBuilder) WithCommits(jsonContent string, options ...RequestOption) *MockPrBuilder {
+	if len(options) == 0 {
+		options = []RequestOption{perPage100, page1}
+	}
+	b.addMockCreator(b.mockGetForPR("pulls", "/commits", jsonContent, options...))
+	return b
+}
+
+// WithInaccessibleCommits sets that the list of commits of the mocked PR cannot be loaded because of a server error
+func (b *MockPrBuilder) WithInaccessibleCommits() *MockPrBuilder {
+	b.addMockCreator(func(builder *MockPrBuilder) {
+		baseGockMock(
+			func(request *gock.Request) {
+				request.Get(fmt.Sprintf("%s/pulls/%d/commits$", b.baseRepoPath(), *b.pullRequest.Number))
+			}).
+			Reply(500)
+	})
+	return b
+}
+
+// WithIssue sets the given payload containing the issue of the given number in the repository the mocked PR belongs to
+func (b *MockPrBuilder) WithIssue(number int, jsonContent string) *MockPrBuilder {
+	b.addMockCreator(func(builder *MockPrBuilder) {
+		b.baseGetMock(fmt.Sprintf("%s/issues/%d", b.baseRepoPath(), number), jsonContent)
+	})
+	return b
+}
+
 // WithLabels sets the given payload containing list of labels to the mocked PR
 func (b *MockPrBuilder) WithLabels(labelNames ...string) *MockPrBuilder {
 	for _, labelName := range labelNames {
@@ -178,6 +273,21 @@ func (b *MockPrBuilder) WithoutConfigFilesForPlugin(pluginName string) *MockPrBu
 	return b
 }
 
+// WithoutBaseConfigFiles sets that the branch the associated mocked PR is based on shouldn't contain any configuration
+// file for the before-set plugin
+func (b *MockPrBuilder) WithoutBaseConfigFiles() *MockPrBuilder {
+	for _, config := range []string{"%s.yaml", "%s.yml"} {
+		path := ghservice.ConfigHome + fmt.Sprintf(config, b.pluginName)
+		b.addMockCreator(func(builder *MockPrBuilder) {
+			baseGockMock(
+				func(request *gock.Request) { request.Get(fmt.Sprintf("%s/contents/%s", b.baseRepoPath(), path)) },
+				func(request *gock.Request) { request.MatchParam("ref", *b.pullRequest.Base.SHA) }).
+				Reply(404)
+		})
+	}
+	return b
+}
+
 // WithoutMessageFiles sets that the associated mocked PR shouldn't contain status messages with the given names
 func (b *MockPrBuilder) WithoutMessageFiles(fileNames ...string) *MockPrBuilder {
 	for _, fileName := range fileNames {
@@ -207,7 +317,7 @@ func Containing(params ...KeyValue) string {
 	var content string
 	for _, param := range params {
 		key, value := param()
-		content += fmt.Sprintf("%s\n%s : %s", content, key, value)
+		content += fmt.Sprintf("\n%s : %s", key, value)
 	}
 	return content
 }
@@ -243,15 +353,41 @@ func (b *MockPrBuilder) WithRawFile(fileName, content string) *MockPrBuilder {
 	b.addMockCreator(func(builder *MockPrBuilder) {
 		builder.getBaseRawFilesMock(fileName).
 			Reply(200).
-			BodyString(content)
+			BodyString(FileContentResponse(content))
 	})
 	return b
 }
 
+// WithRawFileAtBase sets that the commit the associated mocked PR is based on should contain the given file
+func (b *MockPrBuilder) WithRawFileAtBase(fileName, content string) *MockPrBuilder {
+	b.addMockCreator(func(builder *MockPrBuilder) {
+		builder.getRawFileMock(fileName, *b.pullRequest.Base.SHA).
+			Reply(200).
+			BodyString(FileContentResponse(content))
+	})
+	return b
+}
+
+// WithoutRawFilesAtBase sets that the commit the associated mocked PR is based on should not contain the given files
+func (b *MockPrBuilder) WithoutRawFilesAtBase(fileNames ...string) *MockPrBuilder {
+	for _, path := range fileNames {
+		path := path
+		b.addMockCreator(func(builder *MockPrBuilder) {
+			builder.getRawFileMock(path, *b.pullRequest.Base.SHA).
+				Reply(404)
+		})
+	}
+	return b
+}
+
 func (b *MockPrBuilder) getBaseRawFilesMock(path string) *gock.Request {
-	pr := b.pullRequest
-	return gock.New("https://raw.githubusercontent.com").
-		Path(fmt.Sprintf("%s/%s/%s/%s", *pr.Base.Repo.Owner.Login, *pr.Base.Repo.Name, *pr.Head.SHA, path))
+	return b.getRawFileMock(path, *b.pullRequest.Head.SHA)
+}
+
+func (b *MockPrBuilder) getRawFileMock(path, ref string) *gock.Request {
+	return baseGockMock(
+		func(request *gock.Request) { request.Get(fmt.Sprintf("%s/contents/%s", b.baseRepoPath(), path)) },
+		func(request *gock.Request) { request.MatchParam("ref", ref) })
 }
 
 func (b *MockPrBuilder) baseGetMock(path, body string, options ...RequestOption) {
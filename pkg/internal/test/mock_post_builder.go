@@ -122,6 +122,20 @@ func basePostStatusMock(builder *MockPrBuilder) func(mather SoftMatcher) {
 	return basePostMock(fmt.Sprintf("%s/statuses", builder.baseRepoPath()))
 }
 
+// CheckRun creates a gock matcher to check that there is a Post with a check run that complies with the given restrictions
+func CheckRun(matherForPlugin BuilderMatcher) MockCreator {
+	return func(builder *MockPrBuilder) {
+		basePostMock(fmt.Sprintf("%s/check-runs", builder.baseRepoPath()))(matherForPlugin(builder))
+	}
+}
+
+// NoCheckRun creates a gock matcher to check that there is no Post check run request sent
+func NoCheckRun() MockCreator {
+	return func(builder *MockPrBuilder) {
+		basePostMock(fmt.Sprintf("%s/check-runs", builder.baseRepoPath()))(nil)
+	}
+}
+
 // RemovedLabel creates a gock matcher to check that there is a Delete request for the given label sent
 func RemovedLabel(labelName, response string) MockCreator {
 	return func(builder *MockPrBuilder) {
@@ -2,6 +2,7 @@ package test
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 
@@ -107,3 +108,90 @@ func HaveBodyThatContains(content string) SoftMatcher {
 		gomega.ContainSubstring(content),
 		"body")
 }
+
+// HaveName gets "name" key from map[string]interface{} and compares its value with expectedName
+// This matcher is used to verify check run name sent to GitHub API
+func HaveName(expectedName string) SoftMatcher {
+	return TransformWithName(
+		func(s map[string]interface{}) interface{} { return s["name"] },
+		gomega.Equal(expectedName),
+		"name")
+}
+
+// HaveConclusion gets "conclusion" key from map[string]interface{} and compares its value with expectedConclusion
+// This matcher is used to verify check run conclusion sent to GitHub API
+func HaveConclusion(expectedConclusion string) SoftMatcher {
+	return TransformWithName(
+		func(s map[string]interface{}) interface{} { return s["conclusion"] },
+		gomega.Equal(expectedConclusion),
+		"conclusion")
+}
+
+// HaveDetailsURL gets "details_url" key from map[string]interface{} and compares its value with expectedDetailsURL
+// This matcher is used to verify check run details URL sent to GitHub API
+func HaveDetailsURL(expectedDetailsURL string) SoftMatcher {
+	return TransformWithName(
+		func(s map[string]interface{}) interface{} { return s["details_url"] },
+		gomega.Equal(expectedDetailsURL),
+		"details_url")
+}
+
+// HaveOutputTitle gets "title" of the "output" from map[string]interface{} and compares its value with expectedTitle
+// This matcher is used to verify check run output sent to GitHub API
+func HaveOutputTitle(expectedTitle string) SoftMatcher {
+	return TransformWithName(
+		func(s map[string]interface{}) interface{} { return output(s)["title"] },
+		gomega.Equal(expectedTitle),
+		"output.title")
+}
+
+// HaveOutputSummaryThatContains gets "summary" of the "output" from map[string]interface{} and checks
+// if its value contains the given string.
+// This matcher is used to verify check run output sent to GitHub API
+func HaveOutputSummaryThatContains(content string) SoftMatcher {
+	return TransformWithName(
+		func(s map[string]interface{}) interface{} { return output(s)["summary"] },
+		gomega.ContainSubstring(content),
+		"output.summary")
+}
+
+// HaveAnnotatedFiles collects paths of all the annotations of the "output" from map[string]interface{} and checks
+// if they consist of the expected paths.
+// This matcher is used to verify check run annotations sent to GitHub API
+func HaveAnnotatedFiles(expectedPaths ...string) SoftMatcher {
+	return TransformWithName(
+		func(s map[string]interface{}) interface{} {
+			annotations, _ := output(s)["annotations"].([]interface{})
+			return valuesOf(annotations, "path")
+		},
+		gomega.ConsistOf(expectedPaths),
+		"output.annotations")
+}
+
+// HaveActions collects identifiers of all the "actions" from map[string]interface{} and checks
+// if they consist of the expected identifiers.
+// This matcher is used to verify check run actions sent to GitHub API
+func HaveActions(expectedIdentifiers ...string) SoftMatcher {
+	return TransformWithName(
+		func(s map[string]interface{}) interface{} {
+			actions, _ := s["actions"].([]interface{})
+			return valuesOf(actions, "identifier")
+		},
+		gomega.ConsistOf(expectedIdentifiers),
+		"actions")
+}
+
+func output(s map[string]interface{}) map[string]interface{} {
+	out, _ := s["output"].(map[string]interface{})
+	return out
+}
+
+func valuesOf(elements []interface{}, key string) []string {
+	values := make([]string, 0, len(elements))
+	for _, element := range elements {
+		if e, ok := element.(map[string]interface{}); ok {
+			values = append(values, fmt.Sprintf("%v", e[key]))
+		}
+	}
+	return values
+}
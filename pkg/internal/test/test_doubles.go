@@ -81,8 +81,11 @@ func LoadPullRequestEvent(filePath string) *gogh.PullRequestEvent {
 }
 
 // NewDefaultGitHubClient creates a GH client with default go-github client (without any authentication token)
+// talking to the API of the GitHub instance set by github.UseEndpoints
 func NewDefaultGitHubClient() ghclient.Client {
-	client := ghclient.NewClient(gogh.NewClient(nil), log.NewTestLogger())
+	ghClient := gogh.NewClient(nil)
+	ghClient.BaseURL = gitHubAPI()
+	client := ghclient.NewClient(ghClient, log.NewTestLogger())
 	client.RegisterAroundFunctions(ghclient.NewPaginationChecker())
 	return client
 }
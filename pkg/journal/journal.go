@@ -0,0 +1,216 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the journal file currently being written to. Rotated files get numeric suffix, where
+// the higher the number the older the file is (e.g. events.jsonl.1 is newer than events.jsonl.2)
+const FileName = "events.jsonl"
+
+// Entry is a validated webhook event stored in the journal
+type Entry struct {
+	Time      time.Time       `json:"time"`
+	GUID      string          `json:"guid"`
+	EventType string          `json:"event_type"`
+	Repo      string          `json:"repo,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Appender persists journal entries
+type Appender interface {
+	Append(entry Entry) error
+}
+
+// FileJournal is an Appender writing entries as JSON lines to a file which is rotated when it exceeds given size.
+// Only the given number of rotated files is kept, older ones are removed.
+type FileJournal struct {
+	dir      string
+	maxSize  int64
+	maxFiles int
+	mutex    sync.Mutex
+	file     *os.File
+	size     int64
+}
+
+// NewFileJournal opens (or creates) journal file in the given directory
+func NewFileJournal(dir string, maxSize int64, maxFiles int) (*FileJournal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	journal := &FileJournal{dir: dir, maxSize: maxSize, maxFiles: maxFiles}
+	if err := journal.open(); err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// Append writes the entry at the end of the journal, rotating the file beforehand when needed
+func (j *FileJournal) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.size > 0 && j.size+int64(len(line)) > j.maxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+
+	written, err := j.file.Write(line)
+	j.size += int64(written)
+	return err
+}
+
+// Close flushes the journal file to the disk and closes it
+func (j *FileJournal) Close() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	if err := j.file.Sync(); err != nil {
+		_ = j.file.Close()
+		return err
+	}
+	return j.file.Close()
+}
+
+func (j *FileJournal) open() error {
+	file, err := os.OpenFile(filepath.Join(j.dir, FileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	j.file = file
+	j.size = info.Size()
+	return nil
+}
+
+func (j *FileJournal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		return err
+	}
+
+	current := filepath.Join(j.dir, FileName)
+	if err := os.Remove(rotatedFile(current, j.maxFiles)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := j.maxFiles - 1; i > 0; i-- {
+		if err := os.Rename(rotatedFile(current, i), rotatedFile(current, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if j.maxFiles > 0 {
+		if err := os.Rename(current, rotatedFile(current, 1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(current); err != nil {
+		return err
+	}
+
+	return j.open()
+}
+
+// Filter selects journal entries. Zero values of its fields match all the entries
+type Filter struct {
+	GUIDs []string
+	Repo  string
+	Since time.Time
+	Until time.Time
+}
+
+// Matches checks if the entry satisfies all the criteria of the filter
+func (f Filter) Matches(entry Entry) bool {
+	if len(f.GUIDs) > 0 && !contains(f.GUIDs, entry.GUID) {
+		return false
+	}
+	if f.Repo != "" && f.Repo != entry.Repo {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Read loads entries matching the filter from all the journal files stored in the given directory,
+// from the oldest to the newest one
+func Read(dir string, filter Filter) ([]Entry, error) {
+	current := filepath.Join(dir, FileName)
+	rotated, err := filepath.Glob(current + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(rotated)+1)
+	for i := len(rotated); i > 0; i-- {
+		files = append(files, rotatedFile(current, i))
+	}
+	files = append(files, current)
+
+	var entries []Entry
+	for _, file := range files {
+		fileEntries, err := readFile(file, filter)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	return entries, nil
+}
+
+func readFile(path string, filter Filter) ([]Entry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // nolint:errcheck
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 25*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("malformed journal entry in %s at line %d: %s", path, line, err)
+		}
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+func rotatedFile(current string, index int) string {
+	return fmt.Sprintf("%s.%d", current, index)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package journal_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/journal"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File journal", func() {
+
+	var dir string
+	since := time.Date(2018, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	entry := func(guid, repo string, minutes int) journal.Entry {
+		return journal.Entry{
+			Time:      since.Add(time.Duration(minutes) * time.Minute),
+			GUID:      guid,
+			EventType: "pull_request",
+			Repo:      repo,
+			Payload:   json.RawMessage(`{"action":"opened"}`),
+		}
+	}
+
+	guids := func(entries []journal.Entry) []string {
+		var guids []string
+		for _, e := range entries {
+			guids = append(guids, e.GUID)
+		}
+		return guids
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "journal")
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Ω(os.RemoveAll(dir)).Should(Succeed())
+	})
+
+	It("should read appended entries in the order they were written", func() {
+		// given
+		fileJournal, err := journal.NewFileJournal(dir, 1024*1024, 2)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		// when
+		Ω(fileJournal.Append(entry("1", "owner/repo", 0))).Should(Succeed())
+		Ω(fileJournal.Append(entry("2", "owner/repo", 1))).Should(Succeed())
+		Ω(fileJournal.Close()).Should(Succeed())
+
+		// then
+		entries, err := journal.Read(dir, journal.Filter{})
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(guids(entries)).To(Equal([]string{"1", "2"}))
+		Expect(string(entries[0].Payload)).To(Equal(`{"action":"opened"}`))
+	})
+
+	It("should rotate journal file and keep only configured number of rotated files", func() {
+		// given
+		fileJournal, err := journal.NewFileJournal(dir, 100, 2)
+		Ω(err).ShouldNot(HaveOccurred())
+
+		// when
+		for _, guid := range []string{"1", "2", "3", "4"} {
+			Ω(fileJournal.Append(entry(guid, "owner/repo", 0))).Should(Succeed())
+		}
+		Ω(fileJournal.Close()).Should(Succeed())
+
+		// then
+		files, err := filepath.Glob(filepath.Join(dir, "*"))
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(files).To(HaveLen(3))
+		entries, err := journal.Read(dir, journal.Filter{})
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(guids(entries)).To(Equal([]string{"2", "3", "4"}))
+	})
+
+	It("should append to already existing journal file", func() {
+		// given
+		fileJournal, err := journal.NewFileJournal(dir, 1024*1024, 2)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(fileJournal.Append(entry("1", "owner/repo", 0))).Should(Succeed())
+		Ω(fileJournal.Close()).Should(Succeed())
+
+		// when
+		reopened, err := journal.NewFileJournal(dir, 1024*1024, 2)
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(reopened.Append(entry("2", "owner/repo", 0))).Should(Succeed())
+		Ω(reopened.Close()).Should(Succeed())
+
+		// then
+		entries, err := journal.Read(dir, journal.Filter{})
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(guids(entries)).To(Equal([]string{"1", "2"}))
+	})
+
+	Context("Filtering entries", func() {
+
+		BeforeEach(func() {
+			fileJournal, err := journal.NewFileJournal(dir, 1024*1024, 2)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(fileJournal.Append(entry("1", "owner/repo", 0))).Should(Succeed())
+			Ω(fileJournal.Append(entry("2", "owner/other", 10))).Should(Succeed())
+			Ω(fileJournal.Append(entry("3", "owner/repo", 20))).Should(Succeed())
+			Ω(fileJournal.Close()).Should(Succeed())
+		})
+
+		It("should select entries by GUID", func() {
+			// when
+			entries, err := journal.Read(dir, journal.Filter{GUIDs: []string{"1", "2"}})
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(guids(entries)).To(Equal([]string{"1", "2"}))
+		})
+
+		It("should select entries by repository", func() {
+			// when
+			entries, err := journal.Read(dir, journal.Filter{Repo: "owner/repo"})
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(guids(entries)).To(Equal([]string{"1", "3"}))
+		})
+
+		It("should select entries by time range", func() {
+			// when
+			entries, err := journal.Read(dir, journal.Filter{Since: since.Add(5 * time.Minute), Until: since.Add(20 * time.Minute)})
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(guids(entries)).To(Equal([]string{"2", "3"}))
+		})
+	})
+})
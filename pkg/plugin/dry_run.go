@@ -0,0 +1,27 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+)
+
+// newDryRunHandler exposes mutations recorded by the dry-run client as JSON. They can be narrowed down to
+// a single repository using "repo" query parameter (e.g. /dry-run?repo=owner/name)
+func newDryRunHandler(client *ghclient.DryRunClient, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, err := json.Marshal(client.Mutations(r.URL.Query().Get("repo")))
+		if err != nil {
+			logger.Errorf("failed while marshaling dry-run mutations: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(content); err != nil {
+			logger.Errorf("failed while writing dry-run mutations: %s", err)
+		}
+	})
+}
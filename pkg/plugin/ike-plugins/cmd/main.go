@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	pluginBootstrap "github.com/arquillian/ike-prow-plugins/pkg/plugin"
+	prsanitizer "github.com/arquillian/ike-prow-plugins/pkg/plugin/pr-sanitizer"
+	testkeeper "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
+	wip "github.com/arquillian/ike-prow-plugins/pkg/plugin/work-in-progress"
+	"github.com/arquillian/ike-prow-plugins/pkg/server"
+	"k8s.io/test-infra/prow/pluginhelp"
+)
+
+// ServerName is the name under which the multi-plugin server is registered as an external plugin
+const ServerName = "ike-plugins"
+
+var coverageReportHosts = flag.String("coverage-report-hosts", "",
+	"Comma-separated list of hosts test-keeper can download coverage reports from either using report_url or the target URL of status_context. None is allowed when empty.")
+
+var registrations = []pluginBootstrap.Registration{
+	{
+		Name: testkeeper.ProwPluginName,
+		NewEventHandler: func(githubClient ghclient.Client, botName string) server.GitHubEventHandler {
+			return &testkeeper.GitHubTestEventsHandler{Client: githubClient, BotName: botName,
+				CoverageReportHosts: strings.Split(*coverageReportHosts, ",")}
+		},
+		RegisterMetrics: testkeeper.RegisterMetrics,
+	},
+	{
+		Name: prsanitizer.ProwPluginName,
+		NewEventHandler: func(githubClient ghclient.Client, botName string) server.GitHubEventHandler {
+			return &prsanitizer.GitHubPRSanitizerEventsHandler{Client: githubClient, BotName: botName}
+		},
+	},
+	{
+		Name: wip.ProwPluginName,
+		NewEventHandler: func(githubClient ghclient.Client, botName string) server.GitHubEventHandler {
+			return &wip.GitHubWIPPRHandler{Client: githubClient, BotName: botName}
+		},
+	},
+}
+
+func main() {
+	pluginBootstrap.InitPlugins(ServerName, registrations, helpProvider)
+}
+
+func helpProvider(_ []string) (*pluginhelp.PluginHelp, error) { // nolint:unparam
+	names := make([]string, 0, len(registrations))
+	for _, registration := range registrations {
+		names = append(names, registration.Name)
+	}
+	return &pluginhelp.PluginHelp{
+		Description: fmt.Sprintf("Ike plugins server hosting: %s", strings.Join(names, ", ")),
+	}, nil
+}
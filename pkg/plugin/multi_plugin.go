@@ -0,0 +1,72 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	"github.com/arquillian/ike-prow-plugins/pkg/server"
+)
+
+// Registration describes a plugin which can be hosted by the multi-plugin server
+type Registration struct {
+	Name            string
+	NewEventHandler EventHandlerCreator
+	RegisterMetrics func() []error
+}
+
+func enabledRegistrations(registrations []Registration, enabledPlugins string) ([]Registration, error) {
+	if strings.TrimSpace(enabledPlugins) == "" {
+		return registrations, nil
+	}
+
+	registered := make(map[string]Registration, len(registrations))
+	for _, registration := range registrations {
+		registered[registration.Name] = registration
+	}
+
+	var enabled []Registration
+	for _, name := range strings.Split(enabledPlugins, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		registration, found := registered[name]
+		if !found {
+			return nil, fmt.Errorf("unknown plugin %q in --enabled-plugins. registered plugins: %s", name, registrationNames(registrations))
+		}
+		enabled = append(enabled, registration)
+	}
+
+	return enabled, nil
+}
+
+func newMultiPluginServer(serverName string, registrations []Registration, githubClient ghclient.Client, botName string,
+	webhookSecret []byte) (*server.Server, []error) {
+
+	var errors []error
+	handlers := make(server.PluginEventHandlers, 0, len(registrations))
+	for _, registration := range registrations {
+		if registration.RegisterMetrics != nil {
+			errors = append(errors, registration.RegisterMetrics()...)
+		}
+		handlers = append(handlers, server.PluginEventHandler{
+			PluginName:         registration.Name,
+			GitHubEventHandler: registration.NewEventHandler(githubClient, botName),
+		})
+	}
+
+	return &server.Server{
+		GitHubEventHandler: handlers,
+		HmacSecret:         webhookSecret,
+		PluginName:         serverName,
+	}, errors
+}
+
+func registrationNames(registrations []Registration) string {
+	names := make([]string, 0, len(registrations))
+	for _, registration := range registrations {
+		names = append(names, registration.Name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -1,8 +1,8 @@
 package plugin
 
 import (
+	"context"
 	"flag"
-	"net/url"
 	"os/signal"
 	"syscall"
 
@@ -19,7 +19,10 @@ import (
 
 	"time"
 
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
 	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
+	"github.com/arquillian/ike-prow-plugins/pkg/journal"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	"github.com/arquillian/ike-prow-plugins/pkg/server"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -32,7 +35,11 @@ var (
 	dryRun              = flag.Bool("dry-run", true, "Dry run for testing. Uses API tokens but does not mutate.")
 	pluginConfig        = flag.String("ike-plugins-config", "/etc/plugins/plugins", "Path to ike-plugins config file.")
 	githubEndpoint      = flag.String("github-endpoint", "https://api.github.com", "GitHub's API endpoint.")
+	githubWebURL        = flag.String("github-web-url", "", "GitHub's web UI URL. Derived from --github-endpoint when not set.")
+	orgConfigRepo       = flag.String("org-config-repo", ".ike-prow", "Repository of each organization holding organization-wide defaults of the plugins configuration. Not used when empty.")
 	githubTokenFile     = flag.String("github-token-file", "/etc/github/oauth", "Path to the file containing the GitHub OAuth secret.")
+	githubAppID         = flag.Int64("github-app-id", 0, "ID of the GitHub App the plugins authenticate as. OAuth token is used when not set.")
+	githubAppKeyFile    = flag.String("github-app-private-key-file", "/etc/github/app-private-key", "Path to the file containing the private key of the GitHub App.")
 	webhookSecretFile   = flag.String("hmac-secret-file", "/etc/webhook/hmac", "Path to the file containing the GitHub HMAC secret.")
 	sentryDsnSecretFile = flag.String("sentry-dsn-file", "/etc/sentry-dsn/sentry", "Path to the file containing the Sentry DSN url.")
 	sentryTimeout       = flag.Int("sentry-timeout", 1000, "Sentry server timeout in ms. Defaults to 1 second ")
@@ -40,6 +47,10 @@ var (
 	pluginBotName       = flag.String("bot-name", "alien-ike", "Bot Name used for the plugins.")
 	httpAddress         = flag.String("http.address", "0.0.0.0:"+strconv.Itoa(*port), "Http address at which prow server binds")
 	metricsHttpAddress  = flag.String("metrics.http.address", "0.0.0.0:"+strconv.Itoa(*port), "Address at which /metrics endpoint will be mounted.")
+	eventWorkers        = flag.Int("event-workers", 4, "Number of workers handling queued events. Events are handled synchronously when set to 0.")
+	eventQueueSize      = flag.Int("event-queue-size", 100, "Maximum number of events waiting in the queue to be handled, shared by all the workers.")
+	shutdownTimeout     = flag.Duration("shutdown-timeout", 25*time.Second, "Time given to handle already queued events when the server is terminated.")
+	enabledPlugins      = flag.String("enabled-plugins", "", "Comma-separated list of plugins served by multi-plugin server. All registered plugins are enabled when empty.")
 )
 
 // DocumentationURL is a link to arquillian ike-prow-plugins documentation
@@ -57,9 +68,26 @@ type ServerCreator func(hmacSecret []byte, evenHandler server.GitHubEventHandler
 func InitPlugin(pluginName string, newEventHandler EventHandlerCreator, newServer ServerCreator,
 	helpProvider externalplugins.ExternalPluginHelpProvider) {
 
-	// Ignore SIGTERM so that we don't drop hooks when the pod is removed.
-	// We'll get SIGTERM first and then SIGKILL after our graceful termination deadline.
-	signal.Ignore(syscall.SIGTERM)
+	startServer(pluginName, func(githubClient ghclient.Client, webhookSecret []byte) (*server.Server, []error) {
+		return newServer(webhookSecret, newEventHandler(githubClient, *pluginBotName))
+	}, helpProvider)
+}
+
+// InitPlugins starts single server hosting all of the registered plugins which are enabled using --enabled-plugins flag.
+// Every incoming event is dispatched to each of the enabled plugins.
+func InitPlugins(serverName string, registrations []Registration, helpProvider externalplugins.ExternalPluginHelpProvider) {
+	startServer(serverName, func(githubClient ghclient.Client, webhookSecret []byte) (*server.Server, []error) {
+		enabled, err := enabledRegistrations(registrations, *enabledPlugins)
+		if err != nil {
+			return nil, []error{err}
+		}
+		return newMultiPluginServer(serverName, enabled, githubClient, *pluginBotName, webhookSecret)
+	}, helpProvider)
+}
+
+type serverInitializer func(githubClient ghclient.Client, webhookSecret []byte) (*server.Server, []error)
+
+func startServer(pluginName string, initServer serverInitializer, helpProvider externalplugins.ExternalPluginHelpProvider) {
 
 	flag.Parse()
 
@@ -70,32 +98,50 @@ func InitPlugin(pluginName string, newEventHandler EventHandlerCreator, newServe
 		logger.WithError(err).Fatalf("unable to load webhook secret from %q", *webhookSecretFile)
 	}
 
-	oauthSecret, err := utils.LoadSecret(*githubTokenFile)
-	if err != nil {
-		logger.WithError(err).Fatalf("unable to load oauth token from %q", *githubTokenFile)
-	}
-
-	_, err = url.Parse(*githubEndpoint)
+	endpoints, err := github.NewEndpoints(*githubEndpoint)
 	if err != nil {
 		logger.WithError(err).Fatalf("Must specify a valid --github-endpoint URL.")
 	}
+	if *githubWebURL != "" {
+		endpoints.Web = *githubWebURL
+	}
+	github.UseEndpoints(endpoints)
+	ghservice.UseOrganizationConfigRepository(*orgConfigRepo)
 
 	pa := &plugins.PluginAgent{}
 	if err := pa.Start(*pluginConfig); err != nil {
 		logger.WithError(err).Fatalf("Error loading ike-plugins config from %q.", *pluginConfig)
 	}
 
-	githubClient := ghclient.NewOauthClient(oauthSecret, logger)
+	githubClient, installations := newGitHubClient(logger)
 	githubClient.RegisterAroundFunctions(
 		ghclient.NewRateLimitWatcher(githubClient, logger, 100),
 		ghclient.NewRetryWrapper(4, 30*time.Second),
 		ghclient.NewPaginationChecker())
 
-	handler := newEventHandler(githubClient, *pluginBotName)
+	var client ghclient.Client = githubClient
+	if *dryRun {
+		logger.Warn("Running in dry-run mode. No changes will be made in GitHub, see /dry-run endpoint for intended ones.")
+		dryRunClient := ghclient.NewDryRunClient(githubClient, logger)
+		http.Handle("/dry-run", newDryRunHandler(dryRunClient, logger))
+		client = dryRunClient
+	}
 
-	pluginServer, errs := newServer(webhookSecret, handler)
+	pluginServer, errs := initServer(client, webhookSecret)
+	logErrors(errs, logger, "Server initialization failed!")
+	pluginServer.Installations = installations
 	errors := server.RegisterMetrics(githubClient)
-	logErrors(append(errors, errs...), logger, "Prometheus metrics registration failed!")
+	logErrors(errors, logger, "Prometheus metrics registration failed!")
+
+	if *replay {
+		replayJournal(pluginServer, logger)
+		return
+	}
+
+	fileJournal := openJournal(pluginServer, logger)
+	if *eventWorkers > 0 {
+		pluginServer.Queue = server.NewEventQueue(*eventWorkers, *eventQueueSize)
+	}
 
 	port := strconv.Itoa(*port)
 	logger.Infof("Starting server on port %s", port)
@@ -118,9 +164,66 @@ func InitPlugin(pluginName string, newEventHandler EventHandlerCreator, newServe
 		}(*metricsHttpAddress)
 	}
 
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	httpServer := &http.Server{Addr: ":" + port}
+	shutdownDone := make(chan struct{})
+	go shutdownOnSigterm(httpServer, pluginServer.Queue, fileJournal, logger, shutdownDone)
+
+	if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
 		logger.WithError(err).Fatalf("failed to start server on port %s", port)
 	}
+	<-shutdownDone
+}
+
+// newGitHubClient creates client authenticated as the GitHub App when --github-app-id is set, together with the registry
+// of App installations. Otherwise the client uses OAuth token loaded from --github-token-file.
+func newGitHubClient(logger *logrus.Entry) (ghclient.Client, server.InstallationRegistry) {
+	if *githubAppID == 0 {
+		oauthSecret, err := utils.LoadSecret(*githubTokenFile)
+		if err != nil {
+			logger.WithError(err).Fatalf("unable to load oauth token from %q", *githubTokenFile)
+		}
+		return ghclient.NewOauthClient(oauthSecret, logger), nil
+	}
+
+	privateKey, err := utils.LoadSecret(*githubAppKeyFile)
+	if err != nil {
+		logger.WithError(err).Fatalf("unable to load private key of the GitHub App from %q", *githubAppKeyFile)
+	}
+	transport, err := ghclient.NewAppTransport(*githubAppID, privateKey)
+	if err != nil {
+		logger.WithError(err).Fatalf("unable to authenticate as the GitHub App %d", *githubAppID)
+	}
+	logger.Infof("Authenticating as the GitHub App %d", *githubAppID)
+	return ghclient.NewAppClient(transport, logger), transport
+}
+
+// shutdownOnSigterm stops accepting new hooks when the pod is removed, handles those which are already queued and closes
+// the journal, so no journaled event is lost. We'll get SIGTERM first and then SIGKILL after our graceful termination deadline.
+func shutdownOnSigterm(httpServer *http.Server, queue *server.EventQueue, fileJournal *journal.FileJournal, logger *logrus.Entry,
+	done chan<- struct{}) {
+	defer close(done)
+
+	sigterm := make(chan os.Signal, 1)
+	signal.Notify(sigterm, syscall.SIGTERM)
+	<-sigterm
+
+	logger.Infof("Received SIGTERM, shutting down within %s", *shutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(ctx); err != nil {
+		logger.WithError(err).Error("failed to gracefully shut down http server")
+	}
+	if queue != nil {
+		if err := queue.Shutdown(ctx); err != nil {
+			logger.WithError(err).Error("failed to handle all queued events before shutdown")
+		}
+	}
+	if fileJournal != nil {
+		if err := fileJournal.Close(); err != nil {
+			logger.WithError(err).Error("failed to close the journal")
+		}
+	}
 }
 
 func configureLogger(pluginName string) *logrus.Entry {
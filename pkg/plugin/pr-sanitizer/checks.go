@@ -7,17 +7,26 @@ import (
 
 	"fmt"
 
-	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	wip "github.com/arquillian/ike-prow-plugins/pkg/plugin/work-in-progress"
 	gogh "github.com/google/go-github/github"
+	"github.com/pkg/errors"
 )
 
+// closingKeywordsPattern matches any of the GitHub keywords closing the linked issue when the PR is merged
+const closingKeywordsPattern = `(?i:closes|closed|close|fixes|fixed|fix|resolves|resolved|resolve)[\s]*[:]?[\s]*`
+
 var (
-	issueLinkRegexp = regexp.MustCompile(`(?i)(close|closes|closed|fix|fixes|fixed|resolve|resolves|resolved)[\s]*[:]?[\s]*[\w-\/]*#[\d]+`)
+	// issueLinkRegexp matches the issue link used with any of the closing keywords. The first group is the optional
+	// repository (in the `owner/repo` form) and the second one is the number of the issue
+	issueLinkRegexp = regexp.MustCompile(closingKeywordsPattern + `([\w-\/]*)#([\d]+)`)
 	defaultTypes    = []string{"chore", "docs", "feat", "fix", "refactor", "style", "test"}
 )
 
+// titleTypePattern is a regular expression matching the given type prefix at the beginning of the title
+const titleTypePattern = `(?i)^(?:%s)`
+
 const (
 	// TitleFailureMessage is a message used in GH Status as description when the PR title does not follow semantic message style
 	TitleFailureMessage = "#### Semantic title\nThe PR title `%s` does not conform with the " +
@@ -25,6 +34,16 @@ const (
 		"The semantic message makes your changelog and git history clean. " +
 		"Please, edit the PR by prefixing it with one of the type prefixes that are valid for your repository: %s."
 
+	// TitleGrammarViolationMessage is a status message used when the PR title starts with a valid type, but it doesn't follow
+	// the Conventional Commits grammar
+	TitleGrammarViolationMessage = "#### Semantic title\nThe PR title `%s` does not conform with the " +
+		"[Conventional Commits](https://www.conventionalcommits.org) grammar `<type>[(<scope>)][!]: <description>` - %s."
+
+	// TitleConventionViolationMessage is a status message used when the PR title doesn't satisfy the scopes or the length
+	// of the title required by the configuration
+	TitleConventionViolationMessage = "#### Semantic title\nThe PR title `%s` does not conform with the conventions " +
+		"of your repository - %s."
+
 	// DescriptionLengthShortMessage is a status message that is used in case of short PR description.
 	DescriptionLengthShortMessage = "#### PR description length\nThe PR description is too short - it is expected that " +
 		"the description should have more than %d characters, but it has %d. " +
@@ -35,44 +54,157 @@ const (
 	IssueLinkMissingMessage = "#### Issue link\nThe PR description is missing any issue link that would be used with any of the " +
 		"[GitHub keywords](https://help.github.com/articles/closing-issues-using-keywords/). " +
 		"Having it in the PR description ensures that the issue is automatically closed when the PR is merged."
+
+	// CommitMessagesViolationMessage is a beginning of the status message listing commits with messages that don't comply
+	// with the conventions
+	CommitMessagesViolationMessage = "#### Commit messages\nThe messages of the following commits don't comply with " +
+		"the conventions. As the commits are not squashed when merged, their messages become part of the git history. " +
+		"Please, reword them (e.g. using `git rebase -i`):\n\n"
+
+	// IssueReferencesViolationMessage is a beginning of the status message listing issue links which don't refer to
+	// an existing open issue
+	IssueReferencesViolationMessage = "#### Issue references\nThe following issues linked in the PR description " +
+		"with any of the [GitHub keywords](https://help.github.com/articles/closing-issues-using-keywords/) " +
+		"are not open issues that could be closed when the PR is merged:\n\n"
+
+	// TemplateViolationMessage is a beginning of the status message listing sections of the PR template which are
+	// missing in the PR description or which were not filled in
+	TemplateViolationMessage = "#### PR template\nThe PR description doesn't follow the template `%s` of your repository. " +
+		"Please, edit the description so it contains all the required sections with the information asked by the template:\n\n"
+)
+
+const (
+	// SemanticTitleCheck is a name of the check verifying that the PR title follows semantic message style
+	SemanticTitleCheck = "semantic_title"
+	// DescriptionLengthCheck is a name of the check verifying that the PR description is long enough
+	DescriptionLengthCheck = "description_length"
+	// IssueLinkCheck is a name of the check verifying that the PR description links an issue
+	IssueLinkCheck = "issue_link"
+	// CommitMessagesCheck is a name of the check verifying messages of all the commits of the PR. It's disabled by default
+	CommitMessagesCheck = "commit_messages"
+	// IssueReferencesCheck is a name of the check verifying that the issues linked in the PR description exist and are
+	// open. It's disabled by default
+	IssueReferencesCheck = "issue_references"
+	// TemplateComplianceCheck is a name of the check verifying that the PR description follows the PR template of the
+	// repository. It's disabled by default
+	TemplateComplianceCheck = "template_compliance"
 )
 
-type check func(pr *gogh.PullRequest, config PluginConfiguration, logger log.Logger) string
+// checkContext holds everything the checks may need to verify the PR
+type checkContext struct {
+	pr            *gogh.PullRequest
+	client        ghclient.Client
+	config        PluginConfiguration
+	logger        log.Logger
+	loadWipConfig func() wip.PluginConfiguration
+	// localOnly skips the checks which load additional data from GitHub
+	localOnly bool
+}
+
+// namedCheck is a check registered under the name which is used to configure it in the checks section of the configuration.
+// It returns a message describing the problem or an empty string if the PR passes, and an error when the PR cannot be
+// verified at all. Optional checks are executed only when enabled in the configuration. Remote checks load additional
+// data from GitHub
+type namedCheck struct {
+	name     string
+	optional bool
+	remote   bool
+	check    func(ctx *checkContext) (string, error)
+}
+
+// registeredChecks holds all the checks in the order they are executed
+var registeredChecks = []namedCheck{
+	{name: SemanticTitleCheck, check: func(ctx *checkContext) (string, error) {
+		return CheckSemanticTitle(ctx.pr, ctx.config, ctx.loadWipConfig), nil
+	}},
+	{name: DescriptionLengthCheck, check: func(ctx *checkContext) (string, error) {
+		return CheckDescriptionLength(ctx.pr, ctx.config, ctx.logger), nil
+	}},
+	{name: IssueLinkCheck, check: func(ctx *checkContext) (string, error) {
+		return CheckIssueLinkPresence(ctx.pr, ctx.config, ctx.logger), nil
+	}},
+	{name: CommitMessagesCheck, optional: true, remote: true, check: func(ctx *checkContext) (string, error) {
+		return CheckCommitMessages(ctx.client, ctx.pr, ctx.config)
+	}},
+	{name: IssueReferencesCheck, optional: true, remote: true, check: func(ctx *checkContext) (string, error) {
+		return CheckIssueReferences(ctx.client, ctx.pr, ctx.config, ctx.logger), nil
+	}},
+	{name: TemplateComplianceCheck, optional: true, remote: true, check: func(ctx *checkContext) (string, error) {
+		return CheckTemplateCompliance(ctx.client, ctx.pr, ctx.config, ctx.logger), nil
+	}},
+}
 
-func executeChecks(pr *gogh.PullRequest, config PluginConfiguration, logger log.Logger) []string {
-	checks := []check{CheckSemanticTitle, CheckDescriptionLength, CheckIssueLinkPresence}
-	var messages []string
-	for _, check := range checks {
-		msg := check(pr, config, logger)
-		if msg != "" {
-			messages = append(messages, msg)
+func isRegisteredCheck(name string) bool {
+	for _, registered := range registeredChecks {
+		if registered.name == name {
+			return true
 		}
 	}
-	return messages
+	return false
 }
 
-// CheckSemanticTitle checks if the given PR contains semantic title
-func CheckSemanticTitle(pr *gogh.PullRequest, config PluginConfiguration, logger log.Logger) string {
-	change := ghservice.NewRepositoryChangeForPR(pr)
+// checkResults holds messages and names of the failed checks split by their severity
+type checkResults struct {
+	errors, warnings           []string
+	failedChecks, warnedChecks []string
+}
+
+// executeChecks executes all the enabled checks. It stops at the first check which fails to verify the PR and returns its error
+func executeChecks(ctx *checkContext) (checkResults, error) {
+	var results checkResults
+	for _, registered := range registeredChecks {
+		enabled, severity := ctx.config.checkSettings(registered.name, !registered.optional)
+		if !enabled || (registered.remote && ctx.localOnly) {
+			continue
+		}
+		msg, err := registered.check(ctx)
+		if err != nil {
+			return results, errors.Wrapf(err, "%s check failed to verify the pull request", registered.name)
+		}
+		if msg == "" {
+			continue
+		}
+		if severity == WarningSeverity {
+			results.warnings = append(results.warnings, msg)
+			results.warnedChecks = append(results.warnedChecks, registered.name)
+		} else {
+			results.errors = append(results.errors, msg)
+			results.failedChecks = append(results.failedChecks, registered.name)
+		}
+	}
+	return results, nil
+}
+
+// CheckSemanticTitle checks if the given PR contains semantic title following the Conventional Commits grammar and
+// the scopes and the length of the title required by the configuration. The work-in-progress prefix defined by the
+// configuration of that plugin is ignored - the configuration is loaded only when the title itself is not semantic
+func CheckSemanticTitle(pr *gogh.PullRequest, config PluginConfiguration, loadWipConfig func() wip.PluginConfiguration) string {
 	prefixes := GetValidTitlePrefixes(config)
-	isTitleWithValidType := HasTitleWithValidType(prefixes, *pr.Title)
+	title := pr.GetTitle()
+	parsed, err := ParseConventionalTitle(prefixes, title)
 
-	if !isTitleWithValidType {
-		if prefix, ok := wip.GetWorkInProgressPrefix(*pr.Title, wip.LoadConfiguration(logger, change)); ok {
-			trimmedTitle := strings.TrimPrefix(*pr.Title, prefix)
-			isTitleWithValidType = HasTitleWithValidType(prefixes, trimmedTitle)
+	if err != nil {
+		if prefix, ok := wip.GetWorkInProgressPrefix(title, loadWipConfig()); ok {
+			title = strings.TrimPrefix(title, prefix)
+			parsed, err = ParseConventionalTitle(prefixes, title)
 		}
 	}
-	if !isTitleWithValidType {
+	if err == ErrMissingTitleType {
 		allPrefixes := "`" + strings.Join(prefixes, "`, `") + "`"
 		return fmt.Sprintf(TitleFailureMessage, pr.GetTitle(), allPrefixes)
 	}
+	if err != nil {
+		return fmt.Sprintf(TitleGrammarViolationMessage, pr.GetTitle(), err)
+	}
+	if violation := config.titleViolation(parsed, title); violation != "" {
+		return fmt.Sprintf(TitleConventionViolationMessage, pr.GetTitle(), violation)
+	}
 	return ""
 }
 
 // CheckDescriptionLength  checks if the given PR's description contains enough number of arguments
 func CheckDescriptionLength(pr *gogh.PullRequest, config PluginConfiguration, logger log.Logger) string {
-	actualLength := len(strings.TrimSpace(issueLinkRegexp.ReplaceAllString(pr.GetBody(), "")))
+	actualLength := len(strings.TrimSpace(config.stripIssueLinks(pr.GetBody())))
 	if actualLength < config.DescriptionContentLength {
 		return fmt.Sprintf(DescriptionLengthShortMessage, config.DescriptionContentLength, actualLength)
 	}
@@ -81,7 +213,7 @@ func CheckDescriptionLength(pr *gogh.PullRequest, config PluginConfiguration, lo
 
 // CheckIssueLinkPresence checks if the given PR's description contains an issue link
 func CheckIssueLinkPresence(pr *gogh.PullRequest, config PluginConfiguration, logger log.Logger) string {
-	if !issueLinkRegexp.MatchString(pr.GetBody()) {
+	if !config.containsIssueLink(pr.GetBody()) {
 		return IssueLinkMissingMessage
 	}
 	return ""
@@ -100,14 +232,9 @@ func GetValidTitlePrefixes(config PluginConfiguration) []string {
 	return prefixes
 }
 
-// HasTitleWithValidType checks if title prefix conforms with semantic message style.
+// HasTitleWithValidType checks if title conforms with semantic message style - it starts with any of the prefixes and
+// follows the Conventional Commits grammar
 func HasTitleWithValidType(prefixes []string, title string) bool {
-	pureTitle := strings.TrimSpace(title)
-	for _, prefix := range prefixes {
-		prefixRegexp := regexp.MustCompile(`(?i)^` + prefix + `(:| |\()+`)
-		if prefixRegexp.MatchString(pureTitle) {
-			return true
-		}
-	}
-	return false
+	_, err := ParseConventionalTitle(prefixes, title)
+	return err == nil
 }
@@ -0,0 +1,117 @@
+package prsanitizer
+
+import (
+	"fmt"
+	"strings"
+
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
+	gogh "github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// signOffTrailer is a trailer of the commit message certifying the Developer Certificate of Origin (https://developercertificate.org)
+const signOffTrailer = "Signed-off-by:"
+
+// autosquashPrefixes mark commits which are meant to be squashed into other ones by `git rebase --autosquash`
+var autosquashPrefixes = []string{"fixup!", "squash!", "amend!"}
+
+// CheckCommitMessages checks that the subjects of all commits of the given PR follow the same semantic rules as the title,
+// that the lines of the messages are not too long and that there are no fixup or squash commits left. When required by
+// the configuration every commit has to be signed off by its author. Merge commits are not verified. An error is returned
+// when the commits cannot be loaded
+func CheckCommitMessages(client ghclient.Client, pr *gogh.PullRequest, config PluginConfiguration) (string, error) {
+	change := ghservice.NewRepositoryChangeForPR(pr)
+	commits, err := client.ListPullRequestCommits(change.Owner, change.RepoName, pr.GetNumber())
+	if err != nil {
+		return "", errors.Wrap(err, "commits of the pull request were not loaded")
+	}
+
+	prefixes := GetValidTitlePrefixes(config)
+	var violations []string
+	for _, commit := range commits {
+		if len(commit.Parents) > 1 {
+			continue
+		}
+		message := commit.GetCommit().GetMessage()
+		if problems := config.commitMessageViolations(prefixes, commit.GetCommit()); len(problems) > 0 {
+			violations = append(violations, fmt.Sprintf("* %s `%s` - %s",
+				shortSHA(commit.GetSHA()), commitSubject(message), strings.Join(problems, "; ")))
+		}
+	}
+	if len(violations) == 0 {
+		return "", nil
+	}
+	return CommitMessagesViolationMessage + strings.Join(violations, "\n"), nil
+}
+
+// commitMessageViolations lists all problems of the message of the given commit
+func (c *PluginConfiguration) commitMessageViolations(prefixes []string, commit *gogh.Commit) []string {
+	var problems []string
+	lines := strings.Split(strings.Replace(commit.GetMessage(), "\r\n", "\n", -1), "\n")
+	subject := strings.TrimSpace(lines[0])
+
+	if isAutosquashCommit(subject) {
+		problems = append(problems, "the fixup or squash commit has to be squashed before merging")
+	} else if parsed, err := ParseConventionalTitle(prefixes, subject); err == ErrMissingTitleType {
+		problems = append(problems, "the subject doesn't start with any of the valid types")
+	} else if err != nil {
+		problems = append(problems, err.Error())
+	} else if violation := c.scopeViolation(parsed); violation != "" {
+		problems = append(problems, violation)
+	}
+
+	settings := c.CommitMessages
+	if length := len([]rune(subject)); settings.MaxSubjectLength > 0 && length > settings.MaxSubjectLength {
+		problems = append(problems, fmt.Sprintf("the subject has %d characters, but at most %d are allowed",
+			length, settings.MaxSubjectLength))
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		problems = append(problems, "the subject has to be separated from the body by a blank line")
+	}
+	for i, line := range lines[1:] {
+		// single words (such as links) cannot be wrapped
+		length := len([]rune(line))
+		if settings.MaxBodyLineLength > 0 && length > settings.MaxBodyLineLength && strings.Contains(strings.TrimSpace(line), " ") {
+			problems = append(problems, fmt.Sprintf("the line %d has %d characters, but at most %d are allowed",
+				i+2, length, settings.MaxBodyLineLength))
+		}
+	}
+	if settings.RequireSignOff && !isSignedOffBy(lines, commit.GetAuthor()) {
+		problems = append(problems, fmt.Sprintf("the `%s %s <%s>` trailer is missing",
+			signOffTrailer, commit.GetAuthor().GetName(), commit.GetAuthor().GetEmail()))
+	}
+	return problems
+}
+
+func isAutosquashCommit(subject string) bool {
+	for _, prefix := range autosquashPrefixes {
+		if strings.HasPrefix(subject, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSignedOffBy checks if any of the lines is the sign-off trailer with the name and the email of the given author
+func isSignedOffBy(lines []string, author *gogh.CommitAuthor) bool {
+	expected := fmt.Sprintf("%s <%s>", author.GetName(), author.GetEmail())
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, signOffTrailer) && strings.EqualFold(strings.TrimSpace(line[len(signOffTrailer):]), expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func commitSubject(message string) string {
+	return strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,116 @@
+package prsanitizer
+
+import (
+	"fmt"
+	"strings"
+
+	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	wip "github.com/arquillian/ike-prow-plugins/pkg/plugin/work-in-progress"
+	"github.com/arquillian/ike-prow-plugins/pkg/status/message"
+	gogh "github.com/google/go-github/github"
+)
+
+const (
+	// ConfigPreviewName is used in the title of the comment previewing the effect of the configuration changed in the PR
+	ConfigPreviewName = ProwPluginName + " configuration preview"
+
+	// ConfigPreviewMsg is a beginning of the comment previewing the effect of the configuration changed in the PR
+	ConfigPreviewMsg = "This PR changes the configuration of the plugin. Titles and descriptions of recently merged " +
+		"pull requests have been verified again using both the current configuration and the one coming with this PR. " +
+		"The checks loading additional data from GitHub (`" + CommitMessagesCheck + "`, `" + IssueReferencesCheck +
+		"` and `" + TemplateComplianceCheck + "`) are not part of the preview."
+	// ConfigPreviewNoChangesMsg is a part of the preview comment used when none of the merged pull requests is affected by the change
+	ConfigPreviewNoChangesMsg = "None of the %d most recently merged pull requests would be verified differently."
+	// ConfigPreviewChangesMsg is a part of the preview comment introducing the list of the affected merged pull requests
+	ConfigPreviewChangesMsg = "Following pull requests (out of %d most recently merged) would be verified differently:\n"
+
+	// previewedPullRequests is the number of recently merged pull requests verified when the configuration is changed
+	previewedPullRequests = 20
+)
+
+// verificationChange describes a merged pull request which is verified differently using the new configuration
+type verificationChange struct {
+	pr            *gogh.PullRequest
+	before, after string
+}
+
+// previewConfiguration checks if the pull request changes the configuration of the plugin. If so, it verifies recently
+// merged pull requests using both the configuration of the base branch and the one coming with the pull request and
+// adds (or updates) the comment listing pull requests which are affected by the change. The preview is made only once
+// for each head commit of the pull request. Any failure is only logged as the preview is not essential for the verification of the PR
+func (gh *GitHubPRSanitizerEventsHandler) previewConfiguration(logger log.Logger, pr *gogh.PullRequest,
+	commentsLoader *ghservice.IssueCommentsLazyLoader, newConfig PluginConfiguration, loadWipConfig func() wip.PluginConfiguration) {
+
+	change := ghservice.NewRepositoryChangeForPR(pr)
+	files, err := gh.Client.ListPullRequestFiles(change.Owner, change.RepoName, pr.GetNumber())
+	if err != nil {
+		logger.Errorf("failed to list files of the PR to find out if it changes the configuration. cause: %s", err)
+		return
+	}
+	if !ghservice.ChangesConfiguration(ProwPluginName, files) {
+		return
+	}
+
+	msgContext := message.NewStatusMessageContext(ConfigPreviewName, documentationSection, pr, &newConfig.PluginConfiguration)
+	msgService := message.NewStatusMessageService(gh.Client, logger, commentsLoader, msgContext)
+	previewedCommit := fmt.Sprintf(message.PreviewedCommitTemplate, pr.GetHead().GetSHA())
+	if msgService.StatusMessageContains(previewedCommit) {
+		return
+	}
+
+	currentConfig, err := LoadConfiguration(logger, gh.Client, ghservice.NewRepositoryChangeForPRBase(pr))
+	if err != nil {
+		logger.Warnf("skipping preview of the configuration change, the current one cannot be loaded. cause: %s", err)
+		return
+	}
+	mergedPRs, err := gh.Client.ListMergedPullRequests(change.Owner, change.RepoName, previewedPullRequests)
+	if err != nil {
+		logger.Errorf("failed to list merged pull requests to preview configuration change. cause: %s", err)
+		return
+	}
+
+	var changes []verificationChange
+	for _, mergedPR := range mergedPRs {
+		verify := func(config PluginConfiguration) string {
+			results, _ := executeChecks(&checkContext{pr: mergedPR, config: config, logger: logger,
+				loadWipConfig: loadWipConfig, localOnly: true})
+			return describeResults(results)
+		}
+		if before, after := verify(currentConfig), verify(newConfig); before != after {
+			changes = append(changes, verificationChange{pr: mergedPR, before: before, after: after})
+		}
+	}
+
+	err = msgService.StatusMessage(func() string {
+		return createConfigPreviewMessage(len(mergedPRs), changes) + "\n\n" + previewedCommit
+	}, true)
+	if err != nil {
+		logger.Errorf("failed to comment configuration preview on PR [%q]. cause: %s", *pr, err)
+	}
+}
+
+// describeResults describes the outcome of the checks in a short phrase naming the checks which didn't pass
+func describeResults(results checkResults) string {
+	switch {
+	case len(results.failedChecks) > 0:
+		return fmt.Sprintf("fails (%s)", strings.Join(results.failedChecks, ", "))
+	case len(results.warnedChecks) > 0:
+		return fmt.Sprintf("passes with warnings (%s)", strings.Join(results.warnedChecks, ", "))
+	default:
+		return "passes"
+	}
+}
+
+func createConfigPreviewMessage(verified int, changes []verificationChange) string {
+	if len(changes) == 0 {
+		return ConfigPreviewMsg + "\n\n" + fmt.Sprintf(ConfigPreviewNoChangesMsg, verified)
+	}
+
+	lines := []string{fmt.Sprintf(ConfigPreviewChangesMsg, verified)}
+	for _, change := range changes {
+		lines = append(lines, fmt.Sprintf("* [#%d](%s) %s: %s → %s", change.pr.GetNumber(), change.pr.GetHTMLURL(),
+			change.pr.GetTitle(), change.before, change.after))
+	}
+	return ConfigPreviewMsg + "\n\n" + strings.Join(lines, "\n")
+}
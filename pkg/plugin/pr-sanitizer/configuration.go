@@ -1,7 +1,13 @@
 package prsanitizer
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
 	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
 	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	"github.com/arquillian/ike-prow-plugins/pkg/scm"
@@ -11,30 +17,179 @@ import (
 // It's unmarshaled from pr-sanitizer.yml configuration file
 type PluginConfiguration struct {
 	config.PluginConfiguration `yaml:",inline,omitempty"`
-	TypePrefix                 []string `yaml:"type_prefixes,omitempty"`
-	Combine                    bool     `yaml:"combine_defaults,omitempty"`
-	DescriptionContentLength   int      `yaml:"description_content_length,omitempty"`
+	TypePrefix                 []string                       `yaml:"type_prefixes,omitempty"`
+	Combine                    bool                           `yaml:"combine_defaults,omitempty"`
+	DescriptionContentLength   int                            `yaml:"description_content_length,omitempty"`
+	Scopes                     []string                       `yaml:"scopes,omitempty"`
+	RequireScope               bool                           `yaml:"require_scope,omitempty"`
+	MaxTitleLength             int                            `yaml:"max_title_length,omitempty"`
+	CommitMessages             CommitMessagesConfiguration    `yaml:"commit_messages,omitempty"`
+	IssueReferences            IssueReferencesConfiguration   `yaml:"issue_references,omitempty"`
+	ExternalTrackers           []ExternalTrackerConfiguration `yaml:"external_trackers,omitempty"`
+	Template                   TemplateConfiguration          `yaml:"template,omitempty"`
+	Checks                     map[string]CheckConfiguration  `yaml:"checks,omitempty"`
+}
+
+// TemplateConfiguration defines the PR template the description is verified against when the template_compliance check
+// is enabled. All sections of the template are required when no required sections are listed. When no path is set,
+// the template is looked up in the default locations
+type TemplateConfiguration struct {
+	Path             string   `yaml:"path,omitempty"`
+	RequiredSections []string `yaml:"required_sections,omitempty"`
+}
+
+// IssueReferencesConfiguration relaxes rules the linked issues are verified against when the issue_references check
+// is enabled
+type IssueReferencesConfiguration struct {
+	AllowClosed       bool `yaml:"allow_closed,omitempty"`
+	AllowPullRequests bool `yaml:"allow_pull_requests,omitempty"`
+}
+
+// ExternalTrackerConfiguration defines JIRA-style tracker whose issues (such as `ARQ-123`) can be linked in the PR
+// description instead of GitHub issues. The URL template contains {key} placeholder,
+// e.g. https://issues.jboss.org/browse/{key}
+type ExternalTrackerConfiguration struct {
+	Projects    []string `yaml:"projects,omitempty"`
+	URLTemplate string   `yaml:"url_template,omitempty"`
+}
+
+// CommitMessagesConfiguration defines conventions the commit messages are verified against when the commit_messages
+// check is enabled
+type CommitMessagesConfiguration struct {
+	MaxSubjectLength  int  `yaml:"max_subject_length,omitempty"`
+	MaxBodyLineLength int  `yaml:"max_body_line_length,omitempty"`
+	RequireSignOff    bool `yaml:"require_sign_off,omitempty"`
+}
+
+const (
+	// ErrorSeverity makes the status fail when the check doesn't pass. It's the default one
+	ErrorSeverity = "error"
+	// WarningSeverity makes the check only reported in the status message without failing the status
+	WarningSeverity = "warning"
+)
+
+// CheckConfiguration enables or disables the check and sets its severity. Checks (apart from the optional ones) are enabled
+// with error severity by default
+type CheckConfiguration struct {
+	Enabled  *bool  `yaml:"enabled,omitempty"`
+	Severity string `yaml:"severity,omitempty"`
+}
+
+// checkSettings tells if the check of the given name is enabled and what is its severity
+func (c *PluginConfiguration) checkSettings(name string, enabledByDefault bool) (enabled bool, severity string) {
+	checkConfig := c.Checks[name]
+	severity = checkConfig.Severity
+	if severity == "" {
+		severity = ErrorSeverity
+	}
+	if checkConfig.Enabled == nil {
+		return enabledByDefault, severity
+	}
+	return *checkConfig.Enabled, severity
+}
+
+// Validate checks that the type prefixes are not empty and can be used in regular expressions, that the scopes are not
+// empty, that the lengths of the title, commit messages and description are not negative, that the external trackers
+// are well defined, that the required sections of the template are not empty and that only known checks are configured
+// with valid severities
+func (c *PluginConfiguration) Validate() []config.FieldError {
+	fieldErrors := c.PluginConfiguration.Validate()
+	for _, prefix := range c.TypePrefix {
+		if strings.TrimSpace(prefix) == "" {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "type_prefixes", Message: "type prefix must not be empty"})
+		} else if _, err := regexp.Compile(fmt.Sprintf(titleTypePattern, prefix)); err != nil {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "type_prefixes", Value: prefix,
+				Message: fmt.Sprintf("type prefix `%s` is not a valid regular expression: %s", prefix, err)})
+		}
+	}
+	names := make([]string, 0, len(c.Checks))
+	for name := range c.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		checkConfig := c.Checks[name]
+		if !isRegisteredCheck(name) {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "checks", Value: name,
+				Message: fmt.Sprintf("`%s` is not a known check", name)})
+		}
+		switch checkConfig.Severity {
+		case "", ErrorSeverity, WarningSeverity:
+		default:
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "checks", Value: checkConfig.Severity,
+				Message: fmt.Sprintf("severity of `%s` check %q is not one of %s, %s", name, checkConfig.Severity,
+					ErrorSeverity, WarningSeverity)})
+		}
+	}
+	for _, scope := range c.Scopes {
+		if strings.TrimSpace(scope) == "" {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "scopes", Message: "scope must not be empty"})
+		}
+	}
+	if c.CommitMessages.MaxSubjectLength < 0 {
+		fieldErrors = append(fieldErrors, config.FieldError{Field: "max_subject_length",
+			Message: fmt.Sprintf("must not be negative, but is %d", c.CommitMessages.MaxSubjectLength)})
+	}
+	if c.CommitMessages.MaxBodyLineLength < 0 {
+		fieldErrors = append(fieldErrors, config.FieldError{Field: "max_body_line_length",
+			Message: fmt.Sprintf("must not be negative, but is %d", c.CommitMessages.MaxBodyLineLength)})
+	}
+	for _, tracker := range c.ExternalTrackers {
+		if len(tracker.Projects) == 0 {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "external_trackers",
+				Message: "at least one project key is required for each tracker"})
+		}
+		for _, project := range tracker.Projects {
+			if !projectKeyRegexp.MatchString(project) {
+				fieldErrors = append(fieldErrors, config.FieldError{Field: "projects", Value: project,
+					Message: "project key has to consist of uppercase letters, digits and underscores starting with a letter"})
+			}
+		}
+		if tracker.URLTemplate != "" && !strings.Contains(tracker.URLTemplate, issueKeyPlaceholder) {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "url_template", Value: tracker.URLTemplate,
+				Message: fmt.Sprintf("has to contain %s placeholder", issueKeyPlaceholder)})
+		}
+	}
+	for _, section := range c.Template.RequiredSections {
+		if strings.TrimSpace(section) == "" {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "required_sections", Message: "section must not be empty"})
+		}
+	}
+	if c.MaxTitleLength < 0 {
+		fieldErrors = append(fieldErrors, config.FieldError{Field: "max_title_length",
+			Message: fmt.Sprintf("must not be negative, but is %d", c.MaxTitleLength)})
+	}
+	if c.DescriptionContentLength < 0 {
+		fieldErrors = append(fieldErrors, config.FieldError{Field: "description_content_length",
+			Message: fmt.Sprintf("must not be negative, but is %d", c.DescriptionContentLength)})
+	}
+	return fieldErrors
 }
 
-// LoadConfiguration loads a PluginConfiguration for the given change
-func LoadConfiguration(logger log.Logger, change scm.RepositoryChange) PluginConfiguration {
+// LoadConfiguration loads a PluginConfiguration for the given change. Defaults are used when there is no configuration
+// file in the repository. When the file exists, but cannot be loaded, the error is returned so it can be reported
+func LoadConfiguration(logger log.Logger, client ghclient.Client, change scm.RepositoryChange) (PluginConfiguration, error) {
 
 	configuration := PluginConfiguration{
 		Combine:                  true,
 		DescriptionContentLength: 50,
+		CommitMessages: CommitMessagesConfiguration{
+			MaxSubjectLength:  72,
+			MaxBodyLineLength: 72,
+		},
 	}
 	loadableConfig := &ghservice.LoadableConfig{
+		Client:     client,
 		PluginName: ProwPluginName,
 		Change:     change,
 		BaseConfig: &configuration.PluginConfiguration,
 	}
 
-	err := config.Load(&configuration, loadableConfig)
+	err := config.LoadLayers(&configuration, loadableConfig)
 
 	if err != nil {
 		logger.Errorf("Config file was not loaded. Cause: %s", err)
-		return configuration
 	}
 
-	return configuration
+	return configuration, err
 }
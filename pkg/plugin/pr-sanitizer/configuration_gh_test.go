@@ -1,6 +1,7 @@
 package prsanitizer_test
 
 import (
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
 	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	prsanitizer "github.com/arquillian/ike-prow-plugins/pkg/plugin/pr-sanitizer"
@@ -38,9 +39,10 @@ var _ = Describe("PR Sanitizer config loader features", func() {
 				ToChange(change)
 
 			// when
-			configuration := prsanitizer.LoadConfiguration(logger, change)
+			configuration, err := prsanitizer.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
 
 			// then
+			Ω(err).ShouldNot(HaveOccurred())
 			Expect(configuration.TypePrefix).To(ConsistOf(":star:", ":package:", ":hammer_and_wrench:"))
 			Expect(configuration.Combine).To(Equal(true))
 			Expect(configuration.DescriptionContentLength).To(Equal(40))
@@ -57,11 +59,35 @@ var _ = Describe("PR Sanitizer config loader features", func() {
 			}
 
 			// when
-			configuration := prsanitizer.LoadConfiguration(logger, change)
+			configuration, err := prsanitizer.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
 
 			// then
+			Ω(err).ShouldNot(HaveOccurred())
 			Expect(configuration.TypePrefix).To(BeEmpty())
 			Expect(configuration.Combine).To(Equal(true))
 		})
+
+		It("should return validation error when unknown check is configured", func() {
+			// given
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+			mocker.AddConfig(
+				ConfigYml(Containing(
+					Param("checks", "{issue_links: {enabled: false}, description_length: {severity: info}}")))).
+				ToChange(change)
+
+			// when
+			_, err := prsanitizer.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			validationErr := err.(*config.ValidationError)
+			Expect(validationErr.Errors).To(HaveLen(2))
+			Expect(validationErr.Errors[0].Message).To(ContainSubstring("severity of `description_length` check \"info\""))
+			Expect(validationErr.Errors[1].Message).To(Equal("`issue_links` is not a known check"))
+		})
 	})
 })
@@ -0,0 +1,117 @@
+package prsanitizer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/utils"
+)
+
+// ErrMissingTitleType is returned when the title doesn't start with any of the valid types
+var ErrMissingTitleType = errors.New("the title doesn't start with any of the valid types")
+
+// descriptionSeparator separates the type (with optional scope and breaking change mark) from the description
+const descriptionSeparator = ": "
+
+// ConventionalTitle holds parts of the title following the Conventional Commits grammar
+// `<type>[(<scope>)][!]: <description>`
+type ConventionalTitle struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+// ParseConventionalTitle parses the given title using the given types (regular expressions matched case insensitively).
+// When the title doesn't start with any of the types ErrMissingTitleType is returned, otherwise the error describes
+// the exact violation of the grammar. The type which itself ends with a colon (such as emoji type `:star:`) can be
+// followed directly by a space and the description
+func ParseConventionalTitle(types []string, title string) (ConventionalTitle, error) {
+	title = strings.TrimSpace(title)
+	titleType := matchTitleType(types, title)
+	rest := title[len(titleType):]
+	if titleType == "" || startsWithWordCharacter(rest) {
+		return ConventionalTitle{}, ErrMissingTitleType
+	}
+
+	parsed := ConventionalTitle{Type: titleType}
+	if strings.HasPrefix(rest, "(") {
+		end := strings.Index(rest, ")")
+		if end < 0 {
+			return parsed, fmt.Errorf("the scope after `%s` is not closed by `)`", titleType)
+		}
+		parsed.Scope = strings.TrimSpace(rest[1:end])
+		if parsed.Scope == "" {
+			return parsed, fmt.Errorf("the scope in `()` after `%s` must not be empty", titleType)
+		}
+		rest = rest[end+1:]
+	}
+	if strings.HasPrefix(rest, "!") {
+		parsed.Breaking = true
+		rest = rest[1:]
+	}
+
+	separator := descriptionSeparator
+	if rest == title[len(titleType):] && strings.HasSuffix(titleType, ":") {
+		separator = " "
+	}
+	if strings.TrimSpace(rest) == strings.TrimSpace(separator) {
+		return parsed, errors.New("the description must not be empty")
+	}
+	if !strings.HasPrefix(rest, separator) {
+		return parsed, fmt.Errorf("the description has to be separated by `%s`, but the title continues with `%s`", separator, rest)
+	}
+	parsed.Description = strings.TrimSpace(rest[len(separator):])
+	if parsed.Description == "" {
+		return parsed, errors.New("the description must not be empty")
+	}
+	return parsed, nil
+}
+
+// matchTitleType returns the longest beginning of the title matching any of the types
+func matchTitleType(types []string, title string) string {
+	var matched string
+	for _, titleType := range types {
+		typeRegexp, err := regexp.Compile(fmt.Sprintf(titleTypePattern, titleType))
+		if err != nil {
+			continue
+		}
+		if match := typeRegexp.FindString(title); len(match) > len(matched) {
+			matched = match
+		}
+	}
+	return matched
+}
+
+// startsWithWordCharacter tells if the rest of the title continues the word the type is part of (such as `fixes`)
+func startsWithWordCharacter(rest string) bool {
+	for _, r := range rest {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+	}
+	return false
+}
+
+// titleViolation checks the parsed title against the scopes and the length of the title defined in the configuration
+func (c *PluginConfiguration) titleViolation(parsed ConventionalTitle, title string) string {
+	if violation := c.scopeViolation(parsed); violation != "" {
+		return violation
+	}
+	if length := len([]rune(strings.TrimSpace(title))); c.MaxTitleLength > 0 && length > c.MaxTitleLength {
+		return fmt.Sprintf("the title has %d characters, but at most %d are allowed", length, c.MaxTitleLength)
+	}
+	return ""
+}
+
+// scopeViolation checks the scope of the parsed title (or commit subject) against the scopes defined in the configuration
+func (c *PluginConfiguration) scopeViolation(parsed ConventionalTitle) string {
+	if c.RequireScope && parsed.Scope == "" {
+		return "the scope is required, e.g. `" + parsed.Type + "(scope): " + parsed.Description + "`"
+	}
+	if parsed.Scope != "" && len(c.Scopes) != 0 && !utils.Contains(c.Scopes, parsed.Scope) {
+		return fmt.Sprintf("the scope `%s` is not one of the allowed scopes: `%s`", parsed.Scope, strings.Join(c.Scopes, "`, `"))
+	}
+	return ""
+}
@@ -5,6 +5,7 @@ import (
 	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
 	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	wip "github.com/arquillian/ike-prow-plugins/pkg/plugin/work-in-progress"
 	"github.com/arquillian/ike-prow-plugins/pkg/utils"
 	gogh "github.com/google/go-github/github"
 )
@@ -19,6 +20,8 @@ type GitHubPRSanitizerEventsHandler struct {
 var (
 	handledCommentActions = []string{"created", "edited"}
 	handledPrActions      = []string{"opened", "reopened", "edited", "synchronize"}
+	// previewedPrActions are the actions which may bring a change of the configuration to preview
+	previewedPrActions = []string{"opened", "reopened", "synchronize"}
 )
 
 const documentationSection = "#_pr_sanitizer_plugin"
@@ -29,7 +32,7 @@ func (gh *GitHubPRSanitizerEventsHandler) HandlePullRequestEvent(logger log.Logg
 	if !utils.Contains(handledPrActions, *event.Action) {
 		return nil
 	}
-	return gh.validatePullRequestTitleAndDescription(logger, event.PullRequest)
+	return gh.validatePullRequestTitleAndDescription(logger, event.PullRequest, utils.Contains(previewedPrActions, *event.Action))
 }
 
 // HandleIssueCommentEvent is an entry point for the plugin logic. This method is invoked by the Server when
@@ -52,7 +55,7 @@ func (gh *GitHubPRSanitizerEventsHandler) HandleIssueCommentEvent(logger log.Log
 				return err
 			}
 
-			return gh.validatePullRequestTitleAndDescription(logger, pullRequest)
+			return gh.validatePullRequestTitleAndDescription(logger, pullRequest, false)
 		}})
 
 	err := cmdHandler.Handle(logger, comment)
@@ -62,16 +65,37 @@ func (gh *GitHubPRSanitizerEventsHandler) HandleIssueCommentEvent(logger log.Log
 	return err
 }
 
-func (gh *GitHubPRSanitizerEventsHandler) validatePullRequestTitleAndDescription(logger log.Logger, pr *gogh.PullRequest) error {
+func (gh *GitHubPRSanitizerEventsHandler) validatePullRequestTitleAndDescription(logger log.Logger, pr *gogh.PullRequest,
+	previewConfig bool) error {
 	change := ghservice.NewRepositoryChangeForPR(pr)
-	config := LoadConfiguration(logger, change)
-	statusService := gh.newPrSanitizerStatusService(logger, pr, config)
+	config, err := LoadConfiguration(logger, gh.Client, change)
+	commentsLoader := ghservice.NewIssueCommentsLazyLoader(gh.Client, pr)
+	statusService := gh.newPrSanitizerStatusService(logger, pr, commentsLoader, config)
+	if err != nil {
+		return statusService.reportConfigError(err)
+	}
 
-	messages := executeChecks(pr, config, logger)
+	var wipConfig *wip.PluginConfiguration
+	loadWipConfig := func() wip.PluginConfiguration {
+		// the configuration is loaded at most once as it may be needed for each of the previewed pull requests
+		if wipConfig == nil {
+			// defaults are good enough to strip the work-in-progress prefix when its configuration cannot be loaded
+			loaded, _ := wip.LoadConfiguration(logger, gh.Client, change)
+			wipConfig = &loaded
+		}
+		return *wipConfig
+	}
+	if previewConfig {
+		gh.previewConfiguration(logger, pr, commentsLoader, config, loadWipConfig)
+	}
+	results, err := executeChecks(&checkContext{pr: pr, client: gh.Client, config: config, logger: logger, loadWipConfig: loadWipConfig})
+	if err != nil {
+		return statusService.reportError(err)
+	}
 
-	if len(messages) > 0 {
-		return statusService.fail(messages)
+	if len(results.errors) > 0 {
+		return statusService.fail(results)
 	}
 
-	return statusService.success()
+	return statusService.success(results)
 }
@@ -13,6 +13,7 @@ import (
 
 	"github.com/arquillian/ike-prow-plugins/pkg/github"
 	wip "github.com/arquillian/ike-prow-plugins/pkg/plugin/work-in-progress"
+	"github.com/arquillian/ike-prow-plugins/pkg/status/message"
 )
 
 const botName = "alien-ike"
@@ -35,6 +36,7 @@ var _ = Describe("PR Sanitizer Plugin features", func() {
 		It("should mark status as success if PR title prefixed with semantic commit message type", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle("feat: introduces dummy response").
 				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
 				WithoutConfigFiles().
@@ -53,6 +55,7 @@ var _ = Describe("PR Sanitizer Plugin features", func() {
 			// given
 			title := "introduces dummy response"
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle(title).
 				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
 				WithoutComments().
@@ -109,6 +112,7 @@ var _ = Describe("PR Sanitizer Plugin features", func() {
 		It("should mark status as success if PR title prefixed with wip and conforms with semantic commit message type", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle("WIP feat: introduces dummy response").
 				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
 				WithoutConfigFiles().
@@ -129,6 +133,7 @@ var _ = Describe("PR Sanitizer Plugin features", func() {
 			// given
 			title := "WIP introduces dummy response"
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle(title).
 				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
 				WithoutComments().
@@ -213,6 +218,7 @@ var _ = Describe("PR Sanitizer Plugin features", func() {
 		It("should mark status as failed (thus block PR merge) when PR doesn't have issue linked in the description", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle("feat: introduces dummy response").
 				WithDescription("This pr introduces dummy response which is adding new method.").
 				WithoutComments().
@@ -233,6 +239,7 @@ var _ = Describe("PR Sanitizer Plugin features", func() {
 		It("should mark status as failed (thus block PR merge) when PR doesn't have description", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle("fix: introduces dummy response").
 				WithDescription("this pr fixes: #3").
 				WithoutComments().
@@ -253,6 +260,7 @@ var _ = Describe("PR Sanitizer Plugin features", func() {
 		It("should mark status as failed (thus block PR merge) when PR doesn't have description length as per configuration", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle("feat: introduces dummy response").
 				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #3").
 				WithConfigFile(
@@ -273,4 +281,524 @@ var _ = Describe("PR Sanitizer Plugin features", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 	})
+
+	Context("Configurable checks", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+			handler = &prsanitizer.GitHubPRSanitizerEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should mark status as success when PR doesn't have issue linked but the check is disabled", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("This pr introduces dummy response which is adding new method.").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{issue_link: {enabled: false}}")))).
+				WithoutComments().
+				Expecting(Status(ToBe(github.StatusSuccess, prsanitizer.SuccessMessage, prsanitizer.SuccessDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should mark status as success and warn about short description when the check has warning severity", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("fix: introduces dummy response").
+				WithDescription("this pr fixes: #3").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{description_length: {severity: warning}}")))).
+				WithoutComments().
+				WithoutMessageFiles("pr-sanitizer_success_message.md").
+				Expecting(
+					Status(ToBe(github.StatusSuccess, prsanitizer.SuccessMessage, prsanitizer.SuccessDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(prsanitizer.WarningsStatusMessageBeginning),
+						HaveBodyThatContains(fmt.Sprintf(prsanitizer.DescriptionLengthShortMessage, 50, 7))))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should list warnings together with the errors when the status fails", func() {
+			// given
+			title := "introduces dummy response"
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle(title).
+				WithDescription("This pr introduces dummy response which is adding new method.").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{issue_link: {severity: warning}}")))).
+				WithoutComments().
+				WithoutMessageFiles("pr-sanitizer_failed_message.md").
+				WithoutConfigFilesForPlugin(wip.ProwPluginName).
+				Expecting(
+					Status(ToBe(github.StatusFailure, prsanitizer.FailureMessage, prsanitizer.FailureDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(title),
+						HaveBodyThatContains(prsanitizer.WarningsStatusMessageBeginning+prsanitizer.IssueLinkMissingMessage)))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("Conventional Commits grammar", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+			handler = &prsanitizer.GitHubPRSanitizerEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should mark status as failed and name the grammar violation when the description is not separated by colon", func() {
+			// given
+			title := "feat(api) introduces dummy response"
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle(title).
+				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
+				WithoutComments().
+				WithoutConfigFiles().
+				WithoutMessageFiles("pr-sanitizer_failed_message.md").
+				WithoutConfigFilesForPlugin(wip.ProwPluginName).
+				Expecting(
+					Status(ToBe(github.StatusFailure, prsanitizer.FailureMessage, prsanitizer.FailureDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(fmt.Sprintf(prsanitizer.TitleGrammarViolationMessage, title,
+							"the description has to be separated by `: `, but the title continues with ` introduces dummy response`"))))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should mark status as failed when the scope is not one of the configured scopes", func() {
+			// given
+			title := "feat(docs)!: introduces dummy response"
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle(title).
+				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("scopes", "[api, ui]")))).
+				WithoutComments().
+				WithoutMessageFiles("pr-sanitizer_failed_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, prsanitizer.FailureMessage, prsanitizer.FailureDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(fmt.Sprintf(prsanitizer.TitleConventionViolationMessage, title,
+							"the scope `docs` is not one of the allowed scopes: `api`, `ui`"))))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should mark status as success when the title with the required scope fits into the maximal length", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("fix(ui): introduces dummy response").
+				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("require_scope", "true"),
+						Param("max_title_length", "40")))).
+				WithoutComments().
+				Expecting(Status(ToBe(github.StatusSuccess, prsanitizer.SuccessMessage, prsanitizer.SuccessDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("Configuration preview", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+			handler = &prsanitizer.GitHubPRSanitizerEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should list recently merged pull requests verified differently using the configuration changed in the PR", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(`[{"filename": ".ike-prow/pr-sanitizer.yml", "status": "modified"}]`).
+				WithTitle("release: 1.0.0").
+				WithDescription("This pr introduces release type of the changes used when a new version is released.\r\n\r\n fixes: #2").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("type_prefixes", "[release]")))).
+				WithoutBaseConfigFiles().
+				WithoutConfigFilesForPlugin(wip.ProwPluginName).
+				WithMergedPullRequests(`[
+					{"number": 41, "title": "release: 0.9.0", "merged_at": "2018-06-04T10:36:21Z",
+					 "html_url": "https://github.com/bartoszmajsak/wfswarm-booster-pipeline-test/pull/41",
+					 "body": "Releases the version 0.9.0 containing all the fixes done since the last one.\r\n\r\n fixes: #1"},
+					{"number": 39, "title": "docs: updates documentation", "merged_at": "2018-06-01T08:12:45Z",
+					 "html_url": "https://github.com/bartoszmajsak/wfswarm-booster-pipeline-test/pull/39",
+					 "body": "Updates the documentation of the plugin configuration with the examples.\r\n\r\n fixes: #3"}]`).
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, prsanitizer.SuccessMessage, prsanitizer.SuccessDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(fmt.Sprintf(message.PluginTitleTemplate, prsanitizer.ConfigPreviewName)),
+						HaveBodyThatContains(fmt.Sprintf(prsanitizer.ConfigPreviewChangesMsg, 2)),
+						HaveBodyThatContains("release: 0.9.0: fails (semantic_title) → passes")))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("Commit messages", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+			handler = &prsanitizer.GitHubPRSanitizerEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should mark status as failed and list violations per commit when the check is enabled", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{commit_messages: {enabled: true}}"),
+						Param("commit_messages", "{require_sign_off: true}")))).
+				WithCommits(`[
+					{"sha": "3b0cd6e2a1bbd3c8a4fe36a1a46e0cda23fa0faf", "parents": [{"sha": "1"}],
+					 "commit": {"message": "feat: adds dummy response\n\nSigned-off-by: Luke Skywalker <luke@rebellion.org>",
+					            "author": {"name": "Luke Skywalker", "email": "luke@rebellion.org"}}},
+					{"sha": "46cb8fac44709e4ccaae97448c65e8f7320cfea7", "parents": [{"sha": "3b0cd6e"}],
+					 "commit": {"message": "fixup! feat: adds dummy response\n\nSigned-off-by: Luke Skywalker <luke@rebellion.org>",
+					            "author": {"name": "Luke Skywalker", "email": "luke@rebellion.org"}}},
+					{"sha": "8e6b5e8a0c4ef3b7ed1e4c4c2e1c59b9e1e6d0aa", "parents": [{"sha": "46cb8fa"}],
+					 "commit": {"message": "adds tests",
+					            "author": {"name": "Han Solo", "email": "han@falcon.org"}}},
+					{"sha": "b5bd0ffc2a66fa8e8ebbd1c2c4e0a5f2e8d1a0cc", "parents": [{"sha": "8e6b5e8"}, {"sha": "2"}],
+					 "commit": {"message": "Merge branch 'master' into dummy-response"}}]`).
+				WithoutComments().
+				WithoutMessageFiles("pr-sanitizer_failed_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, prsanitizer.FailureMessage, prsanitizer.FailureDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(prsanitizer.CommitMessagesViolationMessage+
+							"* 46cb8fa `fixup! feat: adds dummy response` - the fixup or squash commit has to be squashed before merging\n"+
+							"* 8e6b5e8 `adds tests` - the subject doesn't start with any of the valid types; "+
+							"the `Signed-off-by: Han Solo <han@falcon.org>` trailer is missing")))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should mark status as success when all commit messages comply with the conventions", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{commit_messages: {enabled: true}}")))).
+				WithCommits(`[{"sha": "3b0cd6e2a1bbd3c8a4fe36a1a46e0cda23fa0faf", "parents": [{"sha": "1"}],
+					"commit": {"message": "feat(api): adds dummy response\n\nThe response is used by the clients in tests."}}]`).
+				WithoutComments().
+				Expecting(Status(ToBe(github.StatusSuccess, prsanitizer.SuccessMessage, prsanitizer.SuccessDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should mark status as error when commits of the PR cannot be loaded", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes: #2").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{commit_messages: {enabled: true}}")))).
+				WithInaccessibleCommits().
+				Expecting(Status(ToBe(github.StatusError, prsanitizer.ErrorMessage, ""))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("Issue references", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+			handler = &prsanitizer.GitHubPRSanitizerEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should mark status as failed when linked issues don't exist, are closed or are pull requests", func() {
+			// given
+			gock.New("https://api.github.com").
+				Get("/repos/bartoszmajsak/wfswarm-booster-pipeline-test/issues/2$").
+				Reply(404).
+				BodyString(`{"message": "Not Found"}`)
+			gock.New("https://api.github.com").
+				Get("/repos/arquillian/smart-testing/issues/5$").
+				Reply(200).
+				BodyString(`{"number": 5, "state": "closed"}`)
+
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n"+
+					"fixes: #2, closes arquillian/smart-testing#5, resolves #7 and fixes #8").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{issue_references: {enabled: true}}")))).
+				WithIssue(7, `{"number": 7, "state": "open", "pull_request": {"url": "https://api.github.com/pulls/7"}}`).
+				WithIssue(8, `{"number": 8, "state": "open"}`).
+				WithoutComments().
+				WithoutMessageFiles("pr-sanitizer_failed_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, prsanitizer.FailureMessage, prsanitizer.FailureDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(prsanitizer.IssueReferencesViolationMessage+
+							"* `#2` doesn't exist\n"+
+							"* `arquillian/smart-testing#5` is already closed\n"+
+							"* `#7` is a pull request, not an issue")))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should mark status as success when the issue linked in other repository cannot be found", func() {
+			// given
+			gock.New("https://api.github.com").
+				Get("/repos/arquillian/private-repository/issues/5$").
+				Reply(404).
+				BodyString(`{"message": "Not Found"}`)
+
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n"+
+					"fixes #2 and closes arquillian/private-repository#5").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{issue_references: {enabled: true}}")))).
+				WithIssue(2, `{"number": 2, "state": "open"}`).
+				WithoutComments().
+				Expecting(Status(ToBe(github.StatusSuccess, prsanitizer.SuccessMessage, prsanitizer.SuccessDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should mark status as success when the issue of the external tracker is linked", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("This pr introduces dummy response which is adding new method.\r\n\r\n fixes ARQ-2154").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{issue_references: {enabled: true}}"),
+						Param("external_trackers", "[{projects: [ARQ], url_template: 'https://issues.jboss.org/browse/{key}'}]")))).
+				WithoutComments().
+				Expecting(Status(ToBe(github.StatusSuccess, prsanitizer.SuccessMessage, prsanitizer.SuccessDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("PR template compliance", func() {
+
+		const template = "## Motivation\r\n<!-- Why is this change needed? -->\r\n\r\n" +
+			"## How was this tested\r\n- [ ] unit tests\r\n- [ ] manually\r\n\r\n## Notes\r\n"
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+			handler = &prsanitizer.GitHubPRSanitizerEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should mark status as failed when required sections of the template are missing or not filled in", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("This pr introduces dummy response which is adding new method. fixes: #2\r\n\r\n"+
+					"## How was this tested\r\n- [ ] unit tests\r\n- [ ] manually\r\n").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{template_compliance: {enabled: true}}"),
+						Param("template", "{required_sections: [Motivation, How was this tested]}")))).
+				WithRawFileAtBase(prsanitizer.DefaultTemplatePaths[0], template).
+				WithoutComments().
+				WithoutMessageFiles("pr-sanitizer_failed_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, prsanitizer.FailureMessage, prsanitizer.FailureDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(fmt.Sprintf(prsanitizer.TemplateViolationMessage, prsanitizer.DefaultTemplatePaths[0])+
+							"* the section `Motivation` is missing\n"+
+							"* the section `How was this tested` is not filled in")))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should treat nested sections of the template as part of the enclosing section", func() {
+			// given
+			nestedTemplate := "## Changes\r\n### Motivation\r\n<!-- Why is this change needed? -->\r\n\r\n" +
+				"### Implementation\r\n<!-- How does it work? -->\r\n\r\n## Notes\r\n"
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("## Changes\r\n### Motivation\r\n<!-- Why is this change needed? -->\r\n\r\n"+
+					"### Implementation\r\nThis pr introduces dummy response which is adding new method.\r\n\r\n"+
+					"## Notes\r\nfixes: #2").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{template_compliance: {enabled: true}}")))).
+				WithRawFileAtBase(prsanitizer.DefaultTemplatePaths[0], nestedTemplate).
+				WithoutComments().
+				WithoutMessageFiles("pr-sanitizer_failed_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, prsanitizer.FailureMessage, prsanitizer.FailureDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(fmt.Sprintf(prsanitizer.TemplateViolationMessage, prsanitizer.DefaultTemplatePaths[0])+
+							"* the section `Motivation` is not filled in")))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should look up the template in the other default locations when it is not in the first one", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("## Motivation\r\n<!-- Why is this change needed? -->\r\n\r\nfixes: #2").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{template_compliance: {enabled: true}}")))).
+				WithoutRawFilesAtBase(prsanitizer.DefaultTemplatePaths[:5]...).
+				WithRawFileAtBase("docs/pull_request_template.md", template).
+				WithoutComments().
+				WithoutMessageFiles("pr-sanitizer_failed_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, prsanitizer.FailureMessage, prsanitizer.FailureDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(fmt.Sprintf(prsanitizer.TemplateViolationMessage, "docs/pull_request_template.md")+
+							"* the section `Motivation` is not filled in\n"+
+							"* the section `How was this tested` is missing\n"+
+							"* the section `Notes` is missing")))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should mark status as success when all sections of the template are filled in", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
+				WithTitle("feat: introduces dummy response").
+				WithDescription("## Motivation\r\nThis pr introduces dummy response which is adding new method.\r\n\r\n"+
+					"## How was this tested\r\n- [x] unit tests\r\n- [ ] manually\r\n\r\n## Notes:\r\nfixes: #2").
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("checks", "{template_compliance: {enabled: true}}")))).
+				WithRawFileAtBase(prsanitizer.DefaultTemplatePaths[0], template).
+				WithoutComments().
+				Expecting(Status(ToBe(github.StatusSuccess, prsanitizer.SuccessMessage, prsanitizer.SuccessDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
 })
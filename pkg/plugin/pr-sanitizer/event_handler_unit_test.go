@@ -38,6 +38,32 @@ var _ = Describe("PR-Sanitizer Plugin features", func() {
 			Entry("empty title", ""),
 			Entry("nil title", nil),
 		)
+
+		It("should parse all parts of the title following Conventional Commits grammar", func() {
+			// given
+			prefixes := prsanitizer.GetValidTitlePrefixes(prsanitizer.PluginConfiguration{})
+
+			// when
+			parsed, err := prsanitizer.ParseConventionalTitle(prefixes, "feat(api)!: drop v1 endpoints")
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(parsed).To(Equal(prsanitizer.ConventionalTitle{Type: "feat", Scope: "api", Breaking: true, Description: "drop v1 endpoints"}))
+		})
+
+		DescribeTable("should name the exact violation of Conventional Commits grammar",
+			func(title, expectedViolation string) {
+				prefixes := prsanitizer.GetValidTitlePrefixes(prsanitizer.PluginConfiguration{})
+				_, err := prsanitizer.ParseConventionalTitle(prefixes, title)
+				Expect(err).To(MatchError(expectedViolation))
+			},
+			Entry("unknown type", "fixes failing test", prsanitizer.ErrMissingTitleType.Error()),
+			Entry("scope not closed", "feat(api: add endpoint", "the scope after `feat` is not closed by `)`"),
+			Entry("empty scope", "feat(): add endpoint", "the scope in `()` after `feat` must not be empty"),
+			Entry("missing space after colon", "feat:add endpoint",
+				"the description has to be separated by `: `, but the title continues with `:add endpoint`"),
+			Entry("missing description", "feat(api)!: ", "the description must not be empty"),
+		)
 	})
 
 	Context("Description verifier", func() {
@@ -73,6 +99,22 @@ var _ = Describe("PR-Sanitizer Plugin features", func() {
 			Entry("additional words between keyword and issue link", "PR fixes bugs in #1"),
 		)
 
+		DescribeTable("should recognize issue link of the external tracker",
+			func(desc string, expectedPresence bool) {
+				pr := &github.PullRequest{Body: utils.String(desc)}
+				config := prsanitizer.PluginConfiguration{ExternalTrackers: []prsanitizer.ExternalTrackerConfiguration{
+					{Projects: []string{"ARQ", "SHRINKWRAP"}, URLTemplate: "https://issues.jboss.org/browse/{key}"}}}
+				msg := prsanitizer.CheckIssueLinkPresence(pr, config, log.NewTestLogger())
+				Expect(msg == "").To(Equal(expectedPresence))
+			},
+			Entry("key of the project", "PR fixes ARQ-2154", true),
+			Entry("key of the other project", "Resolves: SHRINKWRAP-1 issue", true),
+			Entry("URL of the issue", "closes https://issues.jboss.org/browse/ARQ-2154", true),
+			Entry("key of unknown project", "PR fixes JBOSS-2154", false),
+			Entry("lowercase key", "PR fixes arq-2154", false),
+			Entry("key without keyword", "PR related to ARQ-2154", false),
+		)
+
 		DescribeTable("should approve description that contains enough number of characters",
 			func(desc string) {
 				pr := &github.PullRequest{Body: utils.String(desc)}
@@ -0,0 +1,131 @@
+package prsanitizer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	gogh "github.com/google/go-github/github"
+)
+
+// issueKeyPlaceholder is replaced by the key of the issue (such as `ARQ-123`) in the URL template of the external tracker
+const issueKeyPlaceholder = "{key}"
+
+// projectKeyRegexp matches a key of the project in the JIRA-style tracker
+var projectKeyRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// issueReference is a link to the GitHub issue found in the PR description
+type issueReference struct {
+	text  string
+	issue scm.RepositoryIssue
+}
+
+// containsIssueLink checks if the description contains a link to the GitHub issue or to the issue in any of the
+// external trackers used with any of the closing keywords
+func (c *PluginConfiguration) containsIssueLink(description string) bool {
+	if issueLinkRegexp.MatchString(description) {
+		return true
+	}
+	for _, tracker := range c.ExternalTrackers {
+		if tracker.issueLinkRegexp().MatchString(description) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripIssueLinks removes all the links to the GitHub issues and to the issues in the external trackers from the description
+func (c *PluginConfiguration) stripIssueLinks(description string) string {
+	description = issueLinkRegexp.ReplaceAllString(description, "")
+	for _, tracker := range c.ExternalTrackers {
+		description = tracker.issueLinkRegexp().ReplaceAllString(description, "")
+	}
+	return description
+}
+
+// issueLinkRegexp matches the key of the issue (such as `ARQ-123`), or the URL created from the template for the key,
+// used with any of the closing keywords
+func (t *ExternalTrackerConfiguration) issueLinkRegexp() *regexp.Regexp {
+	keys := `((?:` + strings.Join(t.Projects, "|") + `)-[\d]+)\b`
+	if t.URLTemplate == "" {
+		return regexp.MustCompile(closingKeywordsPattern + keys)
+	}
+	parts := strings.SplitN(t.URLTemplate, issueKeyPlaceholder, 2)
+	urlPrefix := regexp.QuoteMeta(parts[0])
+	return regexp.MustCompile(closingKeywordsPattern + `(?:` + urlPrefix + `)?` + keys)
+}
+
+// isIn checks if the referenced issue belongs to the repository of the given change
+func (r issueReference) isIn(change scm.RepositoryChange) bool {
+	return strings.EqualFold(r.issue.Owner, change.Owner) && strings.EqualFold(r.issue.RepoName, change.RepoName)
+}
+
+// CheckIssueReferences checks that all GitHub issues linked in the description of the given PR with any of the closing
+// keywords exist, are open and are not pull requests (unless allowed by the configuration). Issues in the external
+// trackers are not verified, neither are those in other repositories which are not found as the bot may not have
+// access to them
+func CheckIssueReferences(client ghclient.Client, pr *gogh.PullRequest, config PluginConfiguration, logger log.Logger) string {
+	change := ghservice.NewRepositoryChangeForPR(pr)
+	var violations []string
+	for _, reference := range findIssueReferences(pr.GetBody(), change) {
+		if reference.issue.RepoName == "" {
+			violations = append(violations, fmt.Sprintf("* `%s` is not a valid reference - use `#number` or `owner/repo#number`",
+				reference.text))
+			continue
+		}
+		issue, err := client.GetIssue(reference.issue)
+		if scm.IsNotFound(err) && !reference.isIn(change) {
+			// GitHub doesn't distinguish missing repositories from the private ones the bot has no access to
+			logger.Warnf("Issue %s was not found in the repository other than the one of the PR so it cannot be verified",
+				reference.text)
+			continue
+		}
+		if scm.IsNotFound(err) {
+			violations = append(violations, fmt.Sprintf("* `%s` doesn't exist", reference.text))
+			continue
+		}
+		if err != nil {
+			logger.Errorf("Issue %s was not loaded so it cannot be verified. Cause: %s", reference.text, err)
+			continue
+		}
+		if issue.IsPullRequest() && !config.IssueReferences.AllowPullRequests {
+			violations = append(violations, fmt.Sprintf("* `%s` is a pull request, not an issue", reference.text))
+		} else if issue.GetState() == "closed" && !config.IssueReferences.AllowClosed {
+			violations = append(violations, fmt.Sprintf("* `%s` is already closed", reference.text))
+		}
+	}
+	if len(violations) == 0 {
+		return ""
+	}
+	return IssueReferencesViolationMessage + strings.Join(violations, "\n")
+}
+
+// findIssueReferences finds all distinct links to the GitHub issues in the description. The links without the repository
+// refer to the repository of the given change. The reference with malformed repository has an empty RepoName
+func findIssueReferences(description string, change scm.RepositoryChange) []issueReference {
+	var references []issueReference
+	found := make(map[string]bool)
+	for _, match := range issueLinkRegexp.FindAllStringSubmatch(description, -1) {
+		repository, number := match[1], match[2]
+		text := repository + "#" + number
+		if found[text] {
+			continue
+		}
+		found[text] = true
+
+		reference := issueReference{text: text}
+		reference.issue.Number, _ = strconv.Atoi(number)
+		if repository == "" {
+			reference.issue.Owner, reference.issue.RepoName = change.Owner, change.RepoName
+		} else if parts := strings.Split(repository, "/"); len(parts) == 2 && parts[0] != "" && parts[1] != "" {
+			reference.issue.Owner, reference.issue.RepoName = parts[0], parts[1]
+		}
+		references = append(references, reference)
+	}
+	return references
+}
@@ -31,6 +31,10 @@ const (
 	// SuccessMessage is a message used in GH Status as description when the PR title and description conforms to the PR sanitizer checks.
 	SuccessMessage = "This PR complies with PR conventions :)"
 
+	// ErrorMessage is a message used in GH Status as description when the PR cannot be verified, e.g. when the data needed
+	// by any of the checks cannot be loaded
+	ErrorMessage = "Failed while verifying PR conventions"
+
 	// SuccessDetailsPageName is a name of a documentation page that contains additional status details for success state
 	SuccessDetailsPageName = "pr-sanitizer-success"
 
@@ -40,15 +44,19 @@ const (
 
 	// SuccessStatusMessage is a status message used when the PR is good
 	SuccessStatusMessage = "This pull request complies with the PR conventions given by the `pr-sanitizer` plugin. :)"
+
+	// WarningsStatusMessageBeginning is a beginning of the part of the status message listing checks with warning severity
+	// which didn't pass
+	WarningsStatusMessageBeginning = "The following items are not required, but you should consider fixing them:\n\n"
 )
 
-func (gh *GitHubPRSanitizerEventsHandler) newPrSanitizerStatusService(logger log.Logger, pr *gogh.PullRequest, config PluginConfiguration) prSanitizerStatusService {
+func (gh *GitHubPRSanitizerEventsHandler) newPrSanitizerStatusService(logger log.Logger, pr *gogh.PullRequest,
+	commentsLoader *ghservice.IssueCommentsLazyLoader, config PluginConfiguration) prSanitizerStatusService {
 	statusContext := github.StatusContext{BotName: gh.BotName, PluginName: ProwPluginName}
 
 	change := ghservice.NewRepositoryChangeForPR(pr)
-	statusService := status.NewStatusService(gh.Client, logger, change, statusContext)
+	statusService := status.NewConfiguredStatusService(gh.Client, logger, change, statusContext, &config.PluginConfiguration)
 
-	commentsLoader := ghservice.NewIssueCommentsLazyLoader(gh.Client, pr)
 	msgContext := message.NewStatusMessageContext(ProwPluginName, documentationSection, pr, &config.PluginConfiguration)
 	msgService := message.NewStatusMessageService(gh.Client, logger, commentsLoader, msgContext)
 
@@ -59,13 +67,41 @@ func (gh *GitHubPRSanitizerEventsHandler) newPrSanitizerStatusService(logger log
 	}
 }
 
-func (ss *prSanitizerStatusService) success() error {
-	ss.statusMsgService.HappyStatusMessage(SuccessStatusMessage, "success", false)
-	return ss.statusService.Success(SuccessMessage, SuccessDetailsPageName)
+// reportConfigError sets the error status when the configuration of the plugin cannot be loaded and lists the problems
+// in the status message when the configuration is invalid. The cause is returned so it is propagated to the caller
+func (ss *prSanitizerStatusService) reportConfigError(cause error) error {
+	return status.ReportConfigError(ss.statusService, ss.statusMsgService, ss.logger, cause)
+}
+
+// reportError sets the error status when the PR cannot be verified. The cause is logged and returned so it is propagated
+// to the caller
+func (ss *prSanitizerStatusService) reportError(cause error) error {
+	ss.logger.Errorf("failed to verify the pull request. cause: %s", cause)
+	if statusErr := ss.statusService.Error(ErrorMessage); statusErr != nil {
+		ss.logger.Errorf("failed to report error status. cause: %s", statusErr)
+	}
+	return cause
+}
+
+func (ss *prSanitizerStatusService) success(results checkResults) error {
+	msg := withWarnings(SuccessStatusMessage, results)
+	// the comment is added only when there is something to warn about
+	ss.statusMsgService.HappyStatusMessage(msg, "success", len(results.warnings) > 0)
+	report := scm.CheckReport{Summary: msg}
+	return status.WithReport(ss.statusService, report).Success(SuccessMessage, SuccessDetailsPageName)
 }
 
-func (ss *prSanitizerStatusService) fail(messages []string) error {
-	msg := FailureStatusMessageBeginning + strings.Join(messages, "\n\n")
+func (ss *prSanitizerStatusService) fail(results checkResults) error {
+	msg := withWarnings(FailureStatusMessageBeginning+strings.Join(results.errors, "\n\n"), results)
 	ss.statusMsgService.SadStatusMessage(msg, "failed", true)
-	return ss.statusService.Failure(FailureMessage, FailureDetailsPageName)
+	report := scm.CheckReport{Summary: msg}
+	return status.WithReport(ss.statusService, report).Failure(FailureMessage, FailureDetailsPageName)
+}
+
+// withWarnings appends messages of the checks with warning severity which didn't pass to the given status message
+func withWarnings(msg string, results checkResults) string {
+	if len(results.warnings) == 0 {
+		return msg
+	}
+	return msg + "\n\n" + WarningsStatusMessageBeginning + strings.Join(results.warnings, "\n\n")
 }
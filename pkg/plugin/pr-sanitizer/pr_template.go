@@ -0,0 +1,146 @@
+package prsanitizer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	gogh "github.com/google/go-github/github"
+)
+
+var (
+	// DefaultTemplatePaths are the locations of the PR template recognized by GitHub. When no path is configured,
+	// the first of them which exists in the repository is used
+	DefaultTemplatePaths = []string{
+		".github/PULL_REQUEST_TEMPLATE.md", ".github/pull_request_template.md",
+		"PULL_REQUEST_TEMPLATE.md", "pull_request_template.md",
+		"docs/PULL_REQUEST_TEMPLATE.md", "docs/pull_request_template.md",
+	}
+
+	headingRegexp     = regexp.MustCompile(`^(#{1,6})\s+(.*?)[\s#]*$`)
+	htmlCommentRegexp = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// markdownSections maps normalized headings of the markdown document to the normalized content below them
+type markdownSections map[string]string
+
+// CheckTemplateCompliance checks that the description of the given PR contains all the required sections of the PR
+// template stored in the repository at the base of the PR and that each of them was filled in with a text which differs
+// from the one in the template. When no sections are required by the configuration, all sections of the template are
+// required
+func CheckTemplateCompliance(client ghclient.Client, pr *gogh.PullRequest, config PluginConfiguration, logger log.Logger) string {
+	change := ghservice.NewRepositoryChangeForPRBase(pr)
+	paths := DefaultTemplatePaths
+	if config.Template.Path != "" {
+		paths = []string{config.Template.Path}
+	}
+	path, content, err := loadTemplate(client, change, paths)
+	if scm.IsNotFound(err) {
+		logger.Warnf("PR template was not found at any of %v so the description cannot be verified against it", paths)
+		return ""
+	}
+	if err != nil {
+		logger.Errorf("PR template %s was not loaded so the description cannot be verified against it. Cause: %s", path, err)
+		return ""
+	}
+
+	templateSections, headings := parseMarkdownSections(string(content))
+	if len(config.Template.RequiredSections) != 0 {
+		headings = config.Template.RequiredSections
+	}
+	descriptionSections, _ := parseMarkdownSections(pr.GetBody())
+
+	var violations []string
+	for _, heading := range headings {
+		key := normalizeHeading(heading)
+		filled, present := descriptionSections[key]
+		if !present {
+			violations = append(violations, fmt.Sprintf("* the section `%s` is missing", heading))
+		} else if filled == "" || filled == templateSections[key] {
+			violations = append(violations, fmt.Sprintf("* the section `%s` is not filled in", heading))
+		}
+	}
+	if len(violations) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(TemplateViolationMessage, path) + strings.Join(violations, "\n")
+}
+
+// loadTemplate loads the first of the given paths which exists at the given change. The not found error is returned
+// when there is none of them
+func loadTemplate(client ghclient.Client, change scm.RepositoryChange, paths []string) (string, []byte, error) {
+	var err error
+	for _, path := range paths {
+		var content []byte
+		content, err = client.GetFileContent(change.Owner, change.RepoName, change.Hash, path)
+		if !scm.IsNotFound(err) {
+			return path, content, err
+		}
+	}
+	return "", nil, err
+}
+
+// parseMarkdownSections splits the given markdown document into sections by its headings. Each section ends at the
+// next heading of the same or higher level, so it contains also all its nested sections. The headings are returned
+// in the order they are defined in the document. Headings within fenced code blocks are ignored
+func parseMarkdownSections(document string) (markdownSections, []string) {
+	type openSection struct {
+		heading string
+		level   int
+		content []string
+	}
+	sections := make(markdownSections)
+	var (
+		headings []string
+		open     []*openSection
+		inCode   bool
+	)
+	closeSections := func(level int) {
+		for len(open) > 0 && open[len(open)-1].level >= level {
+			closed := open[len(open)-1]
+			sections[normalizeHeading(closed.heading)] = normalizeSectionContent(closed.content)
+			open = open[:len(open)-1]
+		}
+	}
+
+	lines := strings.Split(strings.Replace(document, "\r\n", "\n", -1), "\n")
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+		}
+		if match := headingRegexp.FindStringSubmatch(line); match != nil && !inCode {
+			level := len(match[1])
+			closeSections(level)
+			open = append(open, &openSection{heading: match[2], level: level})
+			headings = append(headings, match[2])
+			continue
+		}
+		for _, section := range open {
+			section.content = append(section.content, line)
+		}
+	}
+	closeSections(1)
+	return sections, headings
+}
+
+// normalizeHeading makes headings comparable regardless of their case, whitespaces and trailing colon
+func normalizeHeading(heading string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.TrimSuffix(strings.TrimSpace(heading), ":")), " "))
+}
+
+// normalizeSectionContent removes html comments (usually used as hints in templates), surrounding whitespaces
+// and empty lines from the content of the section
+func normalizeSectionContent(lines []string) string {
+	content := htmlCommentRegexp.ReplaceAllString(strings.Join(lines, "\n"), "")
+	var normalized []string
+	for _, line := range strings.Split(content, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			normalized = append(normalized, trimmed)
+		}
+	}
+	return strings.Join(normalized, "\n")
+}
@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/journal"
+	"github.com/arquillian/ike-prow-plugins/pkg/server"
+	"github.com/sirupsen/logrus" //nolint:depguard
+)
+
+// nolint
+var (
+	journalDir      = flag.String("journal-dir", "", "Directory where validated webhook events are journaled. Journal is disabled when empty.")
+	journalMaxSize  = flag.Int64("journal-max-size", 10, "Size in megabytes after which journal file is rotated.")
+	journalMaxFiles = flag.Int("journal-max-files", 5, "Number of rotated journal files to keep.")
+	replay          = flag.Bool("replay", false, "Replays events stored in --journal-dir instead of starting the server.")
+	replayGUIDs     = flag.String("replay-guids", "", "Comma-separated list of GUIDs of the events to replay.")
+	replayRepo      = flag.String("replay-repo", "", "Replays only events of the given repository (owner/name).")
+	replaySince     = flag.String("replay-since", "", "Replays only events received at or after given time (RFC3339).")
+	replayUntil     = flag.String("replay-until", "", "Replays only events received at or before given time (RFC3339).")
+)
+
+// openJournal sets up the file journal of the server when --journal-dir is set. The returned journal (nil when disabled)
+// has to be closed when the server is shut down
+func openJournal(pluginServer *server.Server, logger *logrus.Entry) *journal.FileJournal {
+	if *journalDir == "" {
+		return nil
+	}
+	fileJournal, err := journal.NewFileJournal(*journalDir, *journalMaxSize*1024*1024, *journalMaxFiles)
+	if err != nil {
+		logger.WithError(err).Fatalf("unable to open journal in %q", *journalDir)
+	}
+	pluginServer.Journal = fileJournal
+	return fileJournal
+}
+
+func replayJournal(pluginServer *server.Server, logger *logrus.Entry) {
+	filter, err := replayFilter()
+	if err != nil {
+		logger.WithError(err).Fatal("invalid replay criteria")
+	}
+
+	entries, err := journal.Read(*journalDir, filter)
+	if err != nil {
+		logger.WithError(err).Fatalf("unable to read journal from %q", *journalDir)
+	}
+
+	logger.Infof("Replaying %d events from %q", len(entries), *journalDir)
+	pluginServer.Replay(entries)
+}
+
+func replayFilter() (journal.Filter, error) {
+	filter := journal.Filter{Repo: *replayRepo}
+	for _, guid := range strings.Split(*replayGUIDs, ",") {
+		if guid = strings.TrimSpace(guid); guid != "" {
+			filter.GUIDs = append(filter.GUIDs, guid)
+		}
+	}
+
+	var err error
+	if filter.Since, err = parseTime(*replaySince); err != nil {
+		return filter, err
+	}
+	filter.Until, err = parseTime(*replayUntil)
+
+	return filter, err
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
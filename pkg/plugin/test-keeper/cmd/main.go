@@ -1,6 +1,9 @@
 package main
 
 import (
+	"flag"
+	"strings"
+
 	testkeeper "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
 	"github.com/arquillian/ike-prow-plugins/pkg/server"
 	"k8s.io/test-infra/prow/pluginhelp"
@@ -9,12 +12,16 @@ import (
 	pluginBootstrap "github.com/arquillian/ike-prow-plugins/pkg/plugin"
 )
 
+var coverageReportHosts = flag.String("coverage-report-hosts", "",
+	"Comma-separated list of hosts coverage reports can be downloaded from either using report_url or the target URL of status_context. None is allowed when empty.")
+
 func main() {
 	pluginBootstrap.InitPlugin(testkeeper.ProwPluginName, eventHandler, eventServer, helpProvider)
 }
 
 func eventHandler(githubClient ghclient.Client, botName string) server.GitHubEventHandler {
-	return &testkeeper.GitHubTestEventsHandler{Client: githubClient, BotName: botName}
+	return &testkeeper.GitHubTestEventsHandler{Client: githubClient, BotName: botName,
+		CoverageReportHosts: strings.Split(*coverageReportHosts, ",")}
 }
 
 func eventServer(webhookSecret []byte, eventHandler server.GitHubEventHandler) (*server.Server, []error) {
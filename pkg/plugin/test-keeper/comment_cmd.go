@@ -1,7 +1,10 @@
 package testkeeper
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
+	"time"
 
 	is "github.com/arquillian/ike-prow-plugins/pkg/command"
 	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
@@ -10,8 +13,61 @@ import (
 	gogh "github.com/google/go-github/github"
 )
 
-// BypassCheckComment is used as a command to bypass test presence validation
-const BypassCheckComment = "/ok-without-tests"
+const (
+	// BypassCheckComment is used as a command to bypass test presence validation
+	BypassCheckComment = "/ok-without-tests"
+	// RequireTestsComment is used as a command to revoke all earlier bypasses of test presence validation
+	RequireTestsComment = "/require-tests"
+)
+
+// BypassConfiguration restricts the bypass command. When RequireReason is set, the command is accepted only with a reason
+// such as "/ok-without-tests only docs typos". When ExpireOnNewCommits is set, the bypass is not valid anymore as soon as
+// a commit changing production files is pushed after it
+type BypassConfiguration struct {
+	RequireReason      bool `yaml:"require_reason,omitempty"`
+	ExpireOnNewCommits bool `yaml:"expire_on_new_commits,omitempty"`
+}
+
+// bypass holds who approved the PR without tests, when and why
+type bypass struct {
+	user       string
+	reason     string
+	approvedAt time.Time
+}
+
+func newBypass(comment *gogh.IssueComment) bypass {
+	approvedAt := comment.GetCreatedAt()
+	if comment.GetUpdatedAt().After(approvedAt) {
+		approvedAt = comment.GetUpdatedAt()
+	}
+	return bypass{user: comment.GetUser().GetLogin(), reason: BypassReason(comment.GetBody()), approvedAt: approvedAt}
+}
+
+// approvedByActionRegexp matches the comment recording the approval given using the check run action (see ApprovedByActionMsg)
+var approvedByActionRegexp = regexp.MustCompile("^" + strings.Replace(regexp.QuoteMeta(ApprovedByActionMsg), "@%s", `@(\S+)`, 1) + "$")
+
+// approverOf returns the user who approved the PR without tests using the check run action when the comment is the one
+// recording such approval added by the bot (either the user or the GitHub App). Otherwise it returns an empty string
+func approverOf(comment *gogh.IssueComment, botName string) string {
+	if login := comment.GetUser().GetLogin(); login != botName && login != fmt.Sprintf("%s[bot]", botName) {
+		return ""
+	}
+	if match := approvedByActionRegexp.FindStringSubmatch(strings.TrimSpace(comment.GetBody())); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// BypassReason returns the reason given after the "/ok-without-tests" command or an empty string if there is none
+func BypassReason(body string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(body), BypassCheckComment))
+}
+
+// isBypassCmd checks if the comment starts with the bypass command, which can be followed by the reason on the same or on the next line
+func isBypassCmd(body string) bool {
+	fields := strings.Fields(body)
+	return len(fields) > 0 && fields[0] == BypassCheckComment
+}
 
 // BypassCmd represents a command that is triggered by "/ok-without-tests"
 type BypassCmd struct {
@@ -35,27 +91,74 @@ func (c *BypassCmd) Perform(client ghclient.Client, logger log.Logger, comment *
 	return BypassCommand.Execute(client, logger, comment)
 }
 
-// Matches returns true when the given IssueCommentEvent content is same as "/ok-without-tests"
+// Matches returns true when the given IssueCommentEvent content is "/ok-without-tests" optionally followed by a reason
 func (c *BypassCmd) Matches(comment *gogh.IssueCommentEvent) bool {
-	body := strings.TrimSpace(*comment.Comment.Body)
-	return body == BypassCheckComment
+	return isBypassCmd(*comment.Comment.Body)
 }
 
 func whoCanTrigger(user *is.PermissionService) []is.PermissionCheck {
 	return []is.PermissionCheck{is.AnyOf(user.Admin, user.PRReviewer, user.PRApprover), is.Not(user.PRCreator)}
 }
 
-// IsValidBypassCmd checks if the given comment contains expected string and was added by user with sufficient permissions
-func IsValidBypassCmd(comment *gogh.IssueComment, prLoader *ghservice.PullRequestLazyLoader) bool {
-	if BypassCheckComment != strings.TrimSpace(*comment.Body) {
+// RequireTestsCmd represents a command that is triggered by "/require-tests"
+type RequireTestsCmd struct {
+	userPermissionService  *is.PermissionService
+	whenDeletedOrTriggered is.DoFunction
+}
+
+// Perform executes the set DoFunctions for the given IssueCommentEvent (when all conditions are fulfilled)
+func (c *RequireTestsCmd) Perform(client ghclient.Client, logger log.Logger, comment *gogh.IssueCommentEvent) error {
+	user := c.userPermissionService
+	var RequireTestsCommand = &is.CmdExecutor{Command: RequireTestsComment}
+
+	RequireTestsCommand.When(is.Deleted).By(is.Anybody).Then(c.whenDeletedOrTriggered)
+
+	RequireTestsCommand.
+		When(is.Triggered).
+		By(whoCanRevoke(user)).
+		Then(c.whenDeletedOrTriggered)
+
+	return RequireTestsCommand.Execute(client, logger, comment)
+}
+
+// Matches returns true when the given IssueCommentEvent content is same as "/require-tests"
+func (c *RequireTestsCmd) Matches(comment *gogh.IssueCommentEvent) bool {
+	return strings.TrimSpace(*comment.Comment.Body) == RequireTestsComment
+}
+
+func whoCanRevoke(user *is.PermissionService) is.PermissionCheck {
+	return is.AnyOf(user.Admin, user.PRReviewer, user.PRApprover)
+}
+
+// IsValidRequireTestsCmd checks if the given comment contains "/require-tests" and was added by user with sufficient permissions
+func IsValidRequireTestsCmd(comment *gogh.IssueComment, prLoader *ghservice.PullRequestLazyLoader) bool {
+	if RequireTestsComment != strings.TrimSpace(*comment.Body) {
 		return false
 	}
 
 	user := is.NewPermissionService(prLoader.Client, *comment.User.Login, prLoader)
 
-	status, err := is.AllOf(whoCanTrigger(user)...)(true)
+	status, err := whoCanRevoke(user)(true)
 	if err != nil || !status.UserIsApproved {
 		return false
 	}
 	return true
 }
+
+// isValidApprover checks if the given user has sufficient permissions to approve the PR without tests
+func isValidApprover(approver string, prLoader *ghservice.PullRequestLazyLoader) bool {
+	user := is.NewPermissionService(prLoader.Client, approver, prLoader)
+
+	status, err := is.AllOf(whoCanTrigger(user)...)(true)
+	return err == nil && status.UserIsApproved
+}
+
+// IsValidBypassCmd checks if the given comment contains expected string (with a reason if it's required by the configuration)
+// and was added by user with sufficient permissions
+func IsValidBypassCmd(comment *gogh.IssueComment, prLoader *ghservice.PullRequestLazyLoader,
+	configuration BypassConfiguration) bool {
+	if !isBypassCmd(*comment.Body) || (configuration.RequireReason && BypassReason(*comment.Body) == "") {
+		return false
+	}
+	return isValidApprover(*comment.User.Login, prLoader)
+}
@@ -0,0 +1,176 @@
+package testkeeper
+
+import (
+	"fmt"
+	"strings"
+
+	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	"github.com/arquillian/ike-prow-plugins/pkg/status/message"
+	gogh "github.com/google/go-github/github"
+)
+
+const (
+	// ConfigPreviewName is used in the title of the comment previewing the effect of the configuration changed in the PR
+	ConfigPreviewName = ProwPluginName + " configuration preview"
+
+	// ConfigPreviewMsg is a beginning of the comment previewing the effect of the configuration changed in the PR
+	ConfigPreviewMsg = "This PR changes the configuration of the plugin. Recently merged pull requests have been verified " +
+		"again using both the current configuration and the one coming with this PR."
+	// ConfigPreviewNoChangesMsg is a part of the preview comment used when none of the merged pull requests is affected by the change
+	ConfigPreviewNoChangesMsg = "None of the %d most recently merged pull requests would be verified differently."
+	// ConfigPreviewChangesMsg is a part of the preview comment introducing the list of the affected merged pull requests
+	ConfigPreviewChangesMsg = "Following pull requests (out of %d most recently merged) would be verified differently:\n"
+
+	// previewedPullRequests is the number of recently merged pull requests verified when the configuration is changed
+	previewedPullRequests = 20
+
+	testCategory       = "test"
+	skippedCategory    = "skipped"
+	productionCategory = "production"
+)
+
+// categoryChange describes a file which falls into a different category when verified using the new configuration
+type categoryChange struct {
+	file          string
+	before, after string
+}
+
+// verificationChange describes a merged pull request which is verified differently using the new configuration
+type verificationChange struct {
+	pr            *gogh.PullRequest
+	before, after string
+	files         []categoryChange
+}
+
+// previewConfiguration verifies recently merged pull requests using both the configuration of the base branch and
+// the one coming with the pull request and adds (or updates) the comment listing pull requests and files which are
+// affected by the change. The preview is made only once for each head commit of the pull request. Any failure is only
+// logged as the preview is not essential for the verification of the PR
+func (gh *GitHubTestEventsHandler) previewConfiguration(logger log.Logger, pr *gogh.PullRequest,
+	commentsLoader *ghservice.IssueCommentsLazyLoader, newConfig *PluginConfiguration, languages []string) {
+
+	msgContext := message.NewStatusMessageContext(ConfigPreviewName, documentationSection, pr, &newConfig.PluginConfiguration)
+	msgService := message.NewStatusMessageService(gh.Client, logger, commentsLoader, msgContext)
+	previewedCommit := fmt.Sprintf(message.PreviewedCommitTemplate, pr.GetHead().GetSHA())
+	if msgService.StatusMessageContains(previewedCommit) {
+		return
+	}
+
+	currentConfig, err := LoadConfiguration(logger, gh.Client, ghservice.NewRepositoryChangeForPRBase(pr))
+	if err != nil {
+		logger.Warnf("skipping preview of the configuration change, the current one cannot be loaded. cause: %s", err)
+		return
+	}
+	change := ghservice.NewRepositoryChangeForPR(pr)
+	mergedPRs, err := gh.Client.ListMergedPullRequests(change.Owner, change.RepoName, previewedPullRequests)
+	if err != nil {
+		logger.Errorf("failed to list merged pull requests to preview configuration change. cause: %s", err)
+		return
+	}
+
+	var changes []verificationChange
+	for _, mergedPR := range mergedPRs {
+		files, err := gh.Client.ListPullRequestFiles(change.Owner, change.RepoName, mergedPR.GetNumber())
+		if err != nil {
+			logger.Errorf("failed to list files of PR #%d to preview configuration change. cause: %s", mergedPR.GetNumber(), err)
+			return
+		}
+		// languages might be detected from the files of the merged PR, so the verifiers cannot be shared.
+		// Coverage reports of merged PRs are not available, so coverage policy falls back to the default one
+		current, err := newVerifier(currentConfig, languages, files, nil)
+		if err != nil {
+			logger.Errorf("failed to preview configuration change. cause: %s", err)
+			return
+		}
+		updated, err := newVerifier(newConfig, languages, files, nil)
+		if err != nil {
+			logger.Errorf("failed to preview configuration change. cause: %s", err)
+			return
+		}
+		if verification, changed := compareVerification(mergedPR, files, current, updated); changed {
+			changes = append(changes, verification)
+		}
+	}
+
+	err = msgService.StatusMessage(func() string {
+		return createConfigPreviewMessage(len(mergedPRs), changes) + "\n\n" + previewedCommit
+	}, true)
+	if err != nil {
+		logger.Errorf("failed to comment configuration preview on PR [%q]. cause: %s", *pr, err)
+	}
+}
+
+func compareVerification(pr *gogh.PullRequest, files []scm.ChangedFile, current, updated verifier) (verificationChange, bool) {
+	verification := verificationChange{
+		pr:     pr,
+		before: current.result(files),
+		after:  updated.result(files),
+	}
+	for _, file := range files {
+		before, after := current.categoryOf(file), updated.categoryOf(file)
+		if before != after {
+			verification.files = append(verification.files, categoryChange{file: file.Name, before: before, after: after})
+		}
+	}
+	return verification, verification.before != verification.after || len(verification.files) > 0
+}
+
+func (v verifier) result(files []scm.ChangedFile) string {
+	fileCategoryCounter := FileCategoryCounter{Matcher: v.matcher}
+	fileCategories, err := fileCategoryCounter.Count(files)
+	if err != nil {
+		return "failure"
+	}
+	measurements, sufficient := v.policy.evaluate(fileCategories)
+	return describeResult(fileCategories, measurements, sufficient)
+}
+
+// describeResult describes the outcome of the verification of the changed files in a short phrase
+func describeResult(fileCategories FileCategories, measurements testMeasurements, sufficient bool) string {
+	if fileCategories.OnlySkippedFiles() {
+		return "only skipped files"
+	}
+	result := "without tests"
+	if sufficient {
+		result = "with tests"
+	} else if fileCategories.TestsExist() {
+		result = "not enough tests"
+	}
+	if len(measurements) > 0 {
+		result += fmt.Sprintf(" (%s)", measurements)
+	}
+	return result
+}
+
+func (v verifier) categoryOf(file scm.ChangedFile) string {
+	switch {
+	case v.matcher.MatchesExclusion(file.Name):
+		return skippedCategory
+	case v.matcher.MatchesInclusion(file.Name):
+		return testCategory
+	default:
+		return productionCategory
+	}
+}
+
+func createConfigPreviewMessage(verified int, changes []verificationChange) string {
+	if len(changes) == 0 {
+		return ConfigPreviewMsg + "\n\n" + fmt.Sprintf(ConfigPreviewNoChangesMsg, verified)
+	}
+
+	lines := []string{fmt.Sprintf(ConfigPreviewChangesMsg, verified)}
+	for _, change := range changes {
+		result := change.before
+		if change.before != change.after {
+			result += " → " + change.after
+		}
+		lines = append(lines, fmt.Sprintf("* [#%d](%s) %s: %s", change.pr.GetNumber(), change.pr.GetHTMLURL(),
+			change.pr.GetTitle(), result))
+		for _, file := range change.files {
+			lines = append(lines, fmt.Sprintf("  * `%s`: %s → %s", file.file, file.before, file.after))
+		}
+	}
+	return ConfigPreviewMsg + "\n\n" + strings.Join(lines, "\n")
+}
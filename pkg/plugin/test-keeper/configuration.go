@@ -1,7 +1,10 @@
 package testkeeper
 
 import (
+	"fmt"
+
 	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
 	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	"github.com/arquillian/ike-prow-plugins/pkg/scm"
@@ -11,23 +14,79 @@ import (
 // It's unmarshaled from test-keeper.yml configuration file
 type PluginConfiguration struct {
 	config.PluginConfiguration `yaml:",inline,omitempty"`
-	Inclusions                 []string `yaml:"test_patterns,omitempty"`
-	Exclusions                 []string `yaml:"skip_validation_for,omitempty"`
-	Combine                    bool     `yaml:"combine_defaults,omitempty"`
+	Inclusions                 []string              `yaml:"test_patterns,omitempty"`
+	Exclusions                 []string              `yaml:"skip_validation_for,omitempty"`
+	Combine                    bool                  `yaml:"combine_defaults,omitempty"`
+	Policy                     string                `yaml:"policy,omitempty"`
+	MinTestRatio               float64               `yaml:"min_test_ratio,omitempty"`
+	ProductionFilesPerTest     int                   `yaml:"production_files_per_test,omitempty"`
+	Pairing                    []PairingRule         `yaml:"test_pairing,omitempty"`
+	Languages                  []LanguagePatterns    `yaml:"languages,omitempty"`
+	Modules                    []ModuleConfiguration `yaml:"modules,omitempty"`
+	Coverage                   CoverageConfiguration `yaml:"coverage,omitempty"`
+	Bypass                     BypassConfiguration   `yaml:"bypass,omitempty"`
+}
+
+// Validate checks that the patterns (including those of pairing rules) are not empty and can be turned into valid regular
+// expressions and that the policy deciding if the PR comes with enough tests is properly defined
+func (c *PluginConfiguration) Validate() []config.FieldError {
+	fieldErrors := c.PluginConfiguration.Validate()
+	fieldErrors = append(fieldErrors, validateFilePatterns("test_patterns", c.Inclusions)...)
+	fieldErrors = append(fieldErrors, validateFilePatterns("skip_validation_for", c.Exclusions)...)
+	fieldErrors = append(fieldErrors, validatePairingRules("test_pairing", c.Pairing)...)
+	fieldErrors = append(fieldErrors, validateLanguages("languages", c.Languages)...)
+	fieldErrors = append(fieldErrors, c.validateModules("modules")...)
+	return append(fieldErrors, c.validatePolicy()...)
+}
+
+func (c *PluginConfiguration) validatePolicy() []config.FieldError {
+	var fieldErrors []config.FieldError
+	switch c.Policy {
+	case "", AnyTestPolicy, PairedPolicy:
+	case CoveragePolicy:
+		fieldErrors = append(fieldErrors, c.Coverage.validate("coverage")...)
+	case ProportionalPolicy:
+		if c.MinTestRatio == 0 && c.ProductionFilesPerTest == 0 {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "policy", Value: c.Policy,
+				Message: fmt.Sprintf("%s policy requires min_test_ratio or production_files_per_test to be set", ProportionalPolicy)})
+		}
+	default:
+		fieldErrors = append(fieldErrors, config.FieldError{Field: "policy", Value: c.Policy,
+			Message: fmt.Sprintf("%q is not one of %s, %s, %s, %s", c.Policy, AnyTestPolicy, ProportionalPolicy, PairedPolicy, CoveragePolicy)})
+	}
+	if c.MinTestRatio < 0 {
+		fieldErrors = append(fieldErrors, config.FieldError{Field: "min_test_ratio",
+			Message: fmt.Sprintf("must not be negative, but is %g", c.MinTestRatio)})
+	}
+	if c.ProductionFilesPerTest < 0 {
+		fieldErrors = append(fieldErrors, config.FieldError{Field: "production_files_per_test",
+			Message: fmt.Sprintf("must not be negative, but is %d", c.ProductionFilesPerTest)})
+	}
+	return fieldErrors
+}
+
+func validateFilePatterns(field string, patterns []string) []config.FieldError {
+	var fieldErrors []config.FieldError
+	for _, pattern := range patterns {
+		if err := ValidateFilePattern(pattern); err != nil {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: field, Value: pattern, Message: err.Error()})
+		}
+	}
+	return fieldErrors
 }
 
-// LoadConfiguration loads a PluginConfiguration for the given change
-func LoadConfiguration(logger log.Logger, change scm.RepositoryChange) *PluginConfiguration {
+// LoadConfiguration loads a PluginConfiguration for the given change. Defaults are used when there is no configuration
+// file in the repository. When the file exists, but cannot be loaded, the error is returned so it can be reported
+func LoadConfiguration(logger log.Logger, client ghclient.Client, change scm.RepositoryChange) (*PluginConfiguration, error) {
 
 	configuration := PluginConfiguration{Combine: true}
-	loadableConfig := &ghservice.LoadableConfig{PluginName: ProwPluginName, Change: change, BaseConfig: &configuration.PluginConfiguration}
+	loadableConfig := &ghservice.LoadableConfig{Client: client, PluginName: ProwPluginName, Change: change, BaseConfig: &configuration.PluginConfiguration}
 
-	err := config.Load(&configuration, loadableConfig)
+	err := config.LoadLayers(&configuration, loadableConfig)
 
 	if err != nil {
 		logger.Errorf("Config file was not loaded. Cause: %s", err)
-		return &configuration
 	}
 
-	return &configuration
+	return &configuration, err
 }
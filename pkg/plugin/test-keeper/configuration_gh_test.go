@@ -1,6 +1,9 @@
 package testkeeper_test
 
 import (
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
+	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
 	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	testkeeper "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
@@ -35,17 +38,18 @@ var _ = Describe("Test keeper config loader features", func() {
 			mocker.AddConfig(
 				ConfigYml(Containing(
 					Param("test_patterns", "['*my', 'test.go', 'pattern.js']"),
-					Param("skip_validation_for", "['pom.xml', 'regex{{*\\.adoc}}']")))).
+					Param("skip_validation_for", "['pom.xml', 'regex{{.*\\.adoc}}']")))).
 				ToChange(change)
 
 			// when
-			configuration := testkeeper.LoadConfiguration(logger, change)
+			configuration, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
 
 			// then
+			Ω(err).ShouldNot(HaveOccurred())
 			Expect(configuration.LocationURL).To(Equal("https://github.com/owner/repo/blob/46cb8fac44709e4ccaae97448c65e8f7320cfea7/.ike-prow/test-keeper.yml"))
 			Expect(configuration.PluginName).To(Equal(testkeeper.ProwPluginName))
 			Expect(configuration.Inclusions).To(ConsistOf("*my", "test.go", "pattern.js"))
-			Expect(configuration.Exclusions).To(ConsistOf("pom.xml", "regex{{*\\.adoc}}"))
+			Expect(configuration.Exclusions).To(ConsistOf("pom.xml", "regex{{.*\\.adoc}}"))
 			Expect(configuration.Combine).To(BeTrue())
 		})
 
@@ -62,15 +66,16 @@ var _ = Describe("Test keeper config loader features", func() {
 			mocker.AddConfig(
 				ConfigYaml(Containing(
 					Param("test_patterns", "['*my', 'test.go', 'pattern.js']"),
-					Param("skip_validation_for", "['pom.xml', 'regex{{*\\.adoc}}']")))).
+					Param("skip_validation_for", "['pom.xml', 'regex{{.*\\.adoc}}']")))).
 				ToChange(change)
 
 			// when
-			configuration := testkeeper.LoadConfiguration(logger, change)
+			configuration, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
 
 			// then
+			Ω(err).ShouldNot(HaveOccurred())
 			Expect(configuration.Inclusions).To(ConsistOf("*my", "test.go", "pattern.js"))
-			Expect(configuration.Exclusions).To(ConsistOf("pom.xml", "regex{{*\\.adoc}}"))
+			Expect(configuration.Exclusions).To(ConsistOf("pom.xml", "regex{{.*\\.adoc}}"))
 			Expect(configuration.Combine).To(BeTrue())
 		})
 
@@ -85,13 +90,278 @@ var _ = Describe("Test keeper config loader features", func() {
 			}
 
 			// when
-			configuration := testkeeper.LoadConfiguration(logger, change)
+			configuration, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
 
 			// then
+			Ω(err).ShouldNot(HaveOccurred())
 			Expect(configuration.LocationURL).To(BeEmpty())
 			Expect(configuration.Inclusions).To(BeEmpty())
 			Expect(configuration.Exclusions).To(BeEmpty())
 			Expect(configuration.Combine).To(BeTrue())
 		})
+
+		It("should return validation error pointing to the line with invalid regular expression", func() {
+			// given
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+
+			mocker.AddConfig(
+				ConfigYml(Containing(
+					Param("test_patterns", "['*my', 'regex{{*IT.java}}']")))).
+				ToChange(change)
+
+			// when
+			_, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			validationErr := err.(*config.ValidationError)
+			Expect(validationErr.Errors).To(HaveLen(1))
+			Expect(validationErr.Errors[0].Line).To(Equal(2))
+			Expect(validationErr.Errors[0].Field).To(Equal("test_patterns"))
+			Expect(validationErr.Errors[0].Message).To(ContainSubstring("regex{{*IT.java}}"))
+		})
+
+		It("should return validation error when proportional policy comes without any threshold", func() {
+			// given
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+
+			mocker.AddConfig(
+				ConfigYml(Containing(
+					Param("policy", "proportional")))).
+				ToChange(change)
+
+			// when
+			_, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			validationErr := err.(*config.ValidationError)
+			Expect(validationErr.Errors).To(HaveLen(1))
+			Expect(validationErr.Errors[0].Field).To(Equal("policy"))
+			Expect(validationErr.Errors[0].Message).To(ContainSubstring("requires min_test_ratio or production_files_per_test"))
+		})
+
+		It("should return validation error pointing to the module with improperly defined policy", func() {
+			// given
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+
+			mocker.AddConfig(
+				ConfigYml(Containing(
+					Param("modules", "[{path: 'services/', policy: strict}]")))).
+				ToChange(change)
+
+			// when
+			_, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			validationErr := err.(*config.ValidationError)
+			Expect(validationErr.Errors).To(HaveLen(1))
+			Expect(validationErr.Errors[0].Field).To(Equal("modules"))
+			Expect(validationErr.Errors[0].Message).To(Equal("module `services/`: \"strict\" is not one of any, proportional, paired, coverage"))
+		})
+
+		It("should return validation error when the module path is not a directory located in the repository", func() {
+			// given
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+
+			mocker.AddConfig(
+				ConfigYml(Containing(
+					Param("modules", "[{path: './', policy: paired}]")))).
+				ToChange(change)
+
+			// when
+			_, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			validationErr := err.(*config.ValidationError)
+			Expect(validationErr.Errors).To(HaveLen(1))
+			Expect(validationErr.Errors[0].Value).To(Equal("./"))
+			Expect(validationErr.Errors[0].Message).To(Equal("module path has to be a directory located in the repository"))
+		})
+	})
+
+	Context("Loading test-keeper configuration file from GitHub Enterprise repository", func() {
+
+		logger := log.NewTestLogger()
+
+		BeforeEach(func() {
+			endpoints, err := github.NewEndpoints("https://github.example.com/api/v3")
+			Ω(err).ShouldNot(HaveOccurred())
+			github.UseEndpoints(endpoints)
+		})
+
+		AfterEach(func() {
+			github.UseEndpoints(github.DefaultEndpoints)
+		})
+
+		It("should load test-keeper configuration file using contents API of the enterprise instance", func() {
+			// given
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+
+			mocker.AddConfig(
+				ConfigYml(Containing(
+					Param("test_patterns", "['*my', 'test.go', 'pattern.js']")))).
+				ToChange(change)
+
+			// when
+			configuration, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(configuration.LocationURL).To(Equal("https://github.example.com/owner/repo/blob/46cb8fac44709e4ccaae97448c65e8f7320cfea7/.ike-prow/test-keeper.yml"))
+			Expect(configuration.Inclusions).To(ConsistOf("*my", "test.go", "pattern.js"))
+		})
+	})
+
+	Context("Loading test-keeper configuration with organization-wide defaults", func() {
+
+		logger := log.NewTestLogger()
+
+		BeforeEach(func() {
+			ghservice.UseOrganizationConfigRepository(".ike-prow")
+		})
+
+		AfterEach(func() {
+			ghservice.UseOrganizationConfigRepository("")
+		})
+
+		It("should merge test-keeper configuration of the repository on top of organization defaults", func() {
+			// given
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+
+			gock.New("https://api.github.com").
+				Get("/repos/owner/.ike-prow/contents/test-keeper.yml").
+				Reply(200).
+				BodyString(FileContentResponse(Containing(
+					Param("test_patterns", "['*IT.java']"),
+					Param("skip_validation_for", "['*.adoc']"),
+					Param("combine_defaults", "false"))))
+
+			mocker.AddConfig(
+				ConfigYml(Containing(
+					Param("test_patterns", "['*my', 'test.go']")))).
+				ToChange(change)
+
+			// when
+			configuration, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(configuration.LocationURL).To(Equal("https://github.com/owner/repo/blob/46cb8fac44709e4ccaae97448c65e8f7320cfea7/.ike-prow/test-keeper.yml"))
+			Expect(configuration.LocationURLs).To(Equal([]string{
+				"https://github.com/owner/.ike-prow/blob/HEAD/test-keeper.yml",
+				"https://github.com/owner/repo/blob/46cb8fac44709e4ccaae97448c65e8f7320cfea7/.ike-prow/test-keeper.yml"}))
+			Expect(configuration.Inclusions).To(ConsistOf("*my", "test.go"))
+			Expect(configuration.Exclusions).To(ConsistOf("*.adoc"))
+			Expect(configuration.Combine).To(BeFalse())
+		})
+
+		It("should validate the policy of organization defaults merged with the repository configuration", func() {
+			// given
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+
+			gock.New("https://api.github.com").
+				Get("/repos/owner/.ike-prow/contents/test-keeper.yml").
+				Reply(200).
+				BodyString(FileContentResponse(Containing(Param("policy", "proportional"))))
+
+			mocker.AddConfig(
+				ConfigYml(Containing(
+					Param("min_test_ratio", "0.5")))).
+				ToChange(change)
+
+			// when
+			configuration, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(configuration.Policy).To(Equal(testkeeper.ProportionalPolicy))
+			Expect(configuration.MinTestRatio).To(Equal(0.5))
+		})
+
+		It("should report invalid policy resulting from organization defaults merged with the repository configuration", func() {
+			// given
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+
+			gock.New("https://api.github.com").
+				Get("/repos/owner/.ike-prow/contents/test-keeper.yml").
+				Reply(200).
+				BodyString(FileContentResponse(Containing(Param("min_test_ratio", "0.5"))))
+
+			mocker.AddConfig(
+				ConfigYml(Containing(
+					Param("policy", "proportional"),
+					Param("min_test_ratio", "0")))).
+				ToChange(change)
+
+			// when
+			_, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).Should(BeAssignableToTypeOf(&config.ValidationError{}))
+			validationErr := err.(*config.ValidationError)
+			Expect(validationErr.Errors).To(HaveLen(1))
+			Expect(validationErr.Errors[0].Field).To(Equal("policy"))
+			Expect(validationErr.Errors[0].Line).To(Equal(2))
+		})
+
+		It("should use organization defaults when repository has no test-keeper configuration", func() {
+			// given
+			NonExistingRawGitHubFiles(".ike-prow/test-keeper.yml", ".ike-prow/test-keeper.yaml", "/.ike-prow/contents/test-keeper.yml")
+
+			change := scm.RepositoryChange{
+				Owner:    "owner",
+				RepoName: "repo",
+				Hash:     "46cb8fac44709e4ccaae97448c65e8f7320cfea7",
+			}
+
+			gock.New("https://api.github.com").
+				Get("/repos/owner/.ike-prow/contents/test-keeper.yaml").
+				Reply(200).
+				BodyString(FileContentResponse(Containing(Param("test_patterns", "['*IT.java']"))))
+
+			// when
+			configuration, err := testkeeper.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(configuration.LocationURL).To(Equal("https://github.com/owner/.ike-prow/blob/HEAD/test-keeper.yaml"))
+			Expect(configuration.Inclusions).To(ConsistOf("*IT.java"))
+			Expect(configuration.Combine).To(BeTrue())
+		})
 	})
 })
@@ -0,0 +1,396 @@
+package testkeeper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	"github.com/arquillian/ike-prow-plugins/pkg/utils"
+	gogh "github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CoberturaFormat identifies coverage reports in Cobertura XML format
+	CoberturaFormat = "cobertura"
+	// JaCoCoFormat identifies coverage reports in JaCoCo XML format
+	JaCoCoFormat = "jacoco"
+	// GoCoverFormat identifies coverage profiles created by go test -coverprofile
+	GoCoverFormat = "go"
+
+	// maxReportRedirects is the maximal number of redirects followed when the coverage report is downloaded
+	maxReportRedirects = 10
+)
+
+// CoverageConfiguration defines where the coverage report of the PR is taken from and the minimal ratio of the added
+// production lines which have to be covered by tests. The report is downloaded either from the target URL of the commit
+// status set by CI under StatusContext or from ReportURL - only one of them can be set. The host of ReportURL has to be
+// allowed by the plugin server. It's unmarshaled from coverage section of test-keeper.yml configuration file
+type CoverageConfiguration struct {
+	Format          string  `yaml:"format,omitempty"`
+	StatusContext   string  `yaml:"status_context,omitempty"`
+	ReportURL       string  `yaml:"report_url,omitempty"`
+	MinDiffCoverage float64 `yaml:"min_diff_coverage,omitempty"`
+}
+
+func (c *CoverageConfiguration) validate(field string) []config.FieldError {
+	var fieldErrors []config.FieldError
+	switch c.Format {
+	case CoberturaFormat, JaCoCoFormat, GoCoverFormat:
+	default:
+		fieldErrors = append(fieldErrors, config.FieldError{Field: field, Value: c.Format,
+			Message: fmt.Sprintf("format %q is not one of %s, %s, %s", c.Format, CoberturaFormat, JaCoCoFormat, GoCoverFormat)})
+	}
+	if (c.StatusContext == "") == (c.ReportURL == "") {
+		fieldErrors = append(fieldErrors, config.FieldError{Field: field,
+			Message: "exactly one of status_context or report_url has to be set"})
+	}
+	if c.MinDiffCoverage <= 0 || c.MinDiffCoverage > 1 {
+		fieldErrors = append(fieldErrors, config.FieldError{Field: field,
+			Message: fmt.Sprintf("min_diff_coverage has to be greater than 0 and at most 1, but is %g", c.MinDiffCoverage)})
+	}
+	return fieldErrors
+}
+
+// usesCoverage tells if the coverage report is needed to verify the PR, either by top-level policy or by any of the modules
+func (c *PluginConfiguration) usesCoverage() bool {
+	if c.Policy == CoveragePolicy {
+		return true
+	}
+	for _, module := range c.Modules {
+		if module.Policy == CoveragePolicy {
+			return true
+		}
+	}
+	return false
+}
+
+// CoverageReport holds coverage of the lines of the source files listed in the report. The paths of the files are kept
+// as they are in the report, so they might be relative to a source directory or prefixed by the import path of the module
+type CoverageReport struct {
+	files map[string]map[int]bool
+}
+
+// ParseCoverageReport parses the content of the coverage report in the given format
+func ParseCoverageReport(format string, content []byte) (*CoverageReport, error) {
+	report := &CoverageReport{files: make(map[string]map[int]bool)}
+	var err error
+	switch format {
+	case CoberturaFormat:
+		err = report.parseCobertura(content)
+	case JaCoCoFormat:
+		err = report.parseJaCoCo(content)
+	case GoCoverFormat:
+		err = report.parseGoCover(content)
+	default:
+		err = errors.Errorf("unknown coverage report format %q", format)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse %s coverage report", format)
+	}
+	if len(report.files) == 0 {
+		return nil, errors.Errorf("%s coverage report doesn't contain any source file", format)
+	}
+	return report, nil
+}
+
+// LineCoverage tells if the line of the file (its path in the repository) is covered by tests and if the line is present
+// in the report at all - lines which are not instrumented (such as comments) are not subject to the coverage
+func (r *CoverageReport) LineCoverage(file string, line int) (covered, instrumented bool) {
+	lines := r.linesOf(file)
+	covered, instrumented = lines[line]
+	return covered, instrumented
+}
+
+// linesOf finds the file in the report. The path in the report is either a suffix of the path in the repository or the
+// other way round, if there are more candidates the longest one is taken
+func (r *CoverageReport) linesOf(file string) map[int]bool {
+	var found string
+	for reported := range r.files {
+		if reported == file || strings.HasSuffix(file, "/"+reported) || strings.HasSuffix(reported, "/"+file) {
+			if len(reported) > len(found) {
+				found = reported
+			}
+		}
+	}
+	return r.files[found]
+}
+
+func (r *CoverageReport) addLine(file string, line int, covered bool) {
+	file = strings.TrimPrefix(path.Clean(file), "./")
+	lines, ok := r.files[file]
+	if !ok {
+		lines = make(map[int]bool)
+		r.files[file] = lines
+	}
+	lines[line] = lines[line] || covered
+}
+
+type coberturaReport struct {
+	Classes []struct {
+		Filename string `xml:"filename,attr"`
+		Lines    []struct {
+			Number int `xml:"number,attr"`
+			Hits   int `xml:"hits,attr"`
+		} `xml:"lines>line"`
+	} `xml:"packages>package>classes>class"`
+}
+
+func (r *CoverageReport) parseCobertura(content []byte) error {
+	var report coberturaReport
+	if err := xml.Unmarshal(content, &report); err != nil {
+		return err
+	}
+	for _, class := range report.Classes {
+		for _, line := range class.Lines {
+			r.addLine(class.Filename, line.Number, line.Hits > 0)
+		}
+	}
+	return nil
+}
+
+type jacocoGroup struct {
+	Groups   []jacocoGroup `xml:"group"`
+	Packages []struct {
+		Name        string `xml:"name,attr"`
+		SourceFiles []struct {
+			Name  string `xml:"name,attr"`
+			Lines []struct {
+				Number             int `xml:"nr,attr"`
+				CoveredInstruction int `xml:"ci,attr"`
+			} `xml:"line"`
+		} `xml:"sourcefile"`
+	} `xml:"package"`
+}
+
+func (r *CoverageReport) parseJaCoCo(content []byte) error {
+	var report jacocoGroup
+	if err := xml.Unmarshal(content, &report); err != nil {
+		return err
+	}
+	r.addJaCoCoGroup(report)
+	return nil
+}
+
+func (r *CoverageReport) addJaCoCoGroup(group jacocoGroup) {
+	for _, subGroup := range group.Groups {
+		r.addJaCoCoGroup(subGroup)
+	}
+	for _, pkg := range group.Packages {
+		for _, sourceFile := range pkg.SourceFiles {
+			for _, line := range sourceFile.Lines {
+				r.addLine(path.Join(pkg.Name, sourceFile.Name), line.Number, line.CoveredInstruction > 0)
+			}
+		}
+	}
+}
+
+var goCoverBlock = regexp.MustCompile(`^(.+):(\d+)\.\d+,(\d+)\.\d+ \d+ (\d+)$`)
+
+func (r *CoverageReport) parseGoCover(content []byte) error {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		block := goCoverBlock.FindStringSubmatch(line)
+		if block == nil {
+			return errors.Errorf("unexpected content at line %d", lineNumber)
+		}
+		start, _ := strconv.Atoi(block[2])
+		end, _ := strconv.Atoi(block[3])
+		count, _ := strconv.Atoi(block[4])
+		for number := start; number <= end; number++ {
+			r.addLine(block[1], number, count > 0)
+		}
+	}
+	return scanner.Err()
+}
+
+// loadCoverageReport loads the coverage report of the PR from the source defined in the configuration. It returns
+// scm.NotFoundError when the report is not available (yet), e.g. when CI hasn't finished, and config.ValidationError
+// when the report_url or the target URL of the status points to a host which is not among the allowed ones
+func loadCoverageReport(client ghclient.Client, pr *gogh.PullRequest, configuration CoverageConfiguration,
+	allowedHosts []string) (*CoverageReport, error) {
+	var (
+		content []byte
+		err     error
+	)
+	if configuration.StatusContext != "" {
+		content, err = downloadReportOfStatus(client, pr, configuration.StatusContext, allowedHosts)
+	} else {
+		reportURL := expandReportURL(configuration.ReportURL, pr)
+		if err := validateReportHost(reportURL, "report_url", allowedHosts); err != nil {
+			return nil, err
+		}
+		content, err = downloadReport(reportURL, allowedHosts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseCoverageReport(configuration.Format, content)
+}
+
+func downloadReportOfStatus(client ghclient.Client, pr *gogh.PullRequest, statusContext string, allowedHosts []string) ([]byte, error) {
+	combinedStatus, err := client.GetCombinedStatus(ghservice.NewRepositoryChangeForPR(pr))
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range combinedStatus.Statuses {
+		if status.GetContext() == statusContext && status.GetState() != "pending" && status.GetTargetURL() != "" {
+			if err := validateReportHost(status.GetTargetURL(), "status_context", allowedHosts); err != nil {
+				return nil, err
+			}
+			return downloadReport(status.GetTargetURL(), allowedHosts)
+		}
+	}
+	return nil, &scm.NotFoundError{Resource: fmt.Sprintf("coverage report of status %q", statusContext)}
+}
+
+// downloadReport downloads the report from the given URL. Redirects are followed only to the allowed hosts
+func downloadReport(reportURL string, allowedHosts []string) ([]byte, error) {
+	client := &http.Client{
+		Timeout: time.Second * 10,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= maxReportRedirects {
+				return errors.Errorf("stopped after %d redirects", maxReportRedirects)
+			}
+			return validateReportHost(request.URL.String(), "report_url", allowedHosts)
+		},
+	}
+	content, err := utils.GetFileFromURLUsing(client, reportURL)
+	if urlErr, ok := err.(*url.Error); ok && config.IsValidationError(urlErr.Err) {
+		return nil, urlErr.Err
+	}
+	if httpErr, ok := err.(*utils.HTTPError); ok && httpErr.StatusCode == 404 {
+		return nil, &scm.NotFoundError{Resource: fmt.Sprintf("coverage report %s", reportURL)}
+	}
+	return content, err
+}
+
+// validateReportHost checks that the report is downloaded over http(s) from one of the hosts allowed by the plugin
+// server, so neither the configuration stored in the repository nor the statuses set by CI can make the plugin send
+// requests to arbitrary addresses. The given field is the one of the configuration the URL comes from
+func validateReportHost(reportURL, field string, allowedHosts []string) error {
+	parsed, err := url.Parse(reportURL)
+	if err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") {
+		for _, host := range allowedHosts {
+			if host = strings.TrimSpace(host); host != "" && strings.EqualFold(host, parsed.Hostname()) {
+				return nil
+			}
+		}
+	}
+	return &config.ValidationError{Errors: []config.FieldError{{Field: field,
+		Message: "the coverage report has to be downloaded using http(s) from one of the hosts allowed by the plugin server"}}}
+}
+
+// expandReportURL replaces {owner}, {repo}, {sha} and {number} placeholders with the values of the PR
+func expandReportURL(reportURL string, pr *gogh.PullRequest) string {
+	change := ghservice.NewRepositoryChangeForPR(pr)
+	return strings.NewReplacer(
+		"{owner}", change.Owner,
+		"{repo}", change.RepoName,
+		"{sha}", change.Hash,
+		"{number}", strconv.Itoa(pr.GetNumber()),
+	).Replace(reportURL)
+}
+
+// coveragePolicy requires the minimal ratio of production lines added in the PR to be covered by tests according
+// to the coverage report. When there is no report or none of the added lines is present in it, there is nothing to measure,
+// so it falls back to the default policy
+type coveragePolicy struct {
+	report          *CoverageReport
+	minDiffCoverage float64
+}
+
+func (p *coveragePolicy) evaluate(fileCategories FileCategories) (testMeasurements, bool) {
+	if p.report == nil || fileCategories.Files == nil {
+		return anyTestPolicy{}.evaluate(fileCategories)
+	}
+	var (
+		covered, instrumented int
+		uncovered             []uncoveredLines
+	)
+	for _, file := range *fileCategories.Files {
+		if !utils.Contains(fileCategories.Production, file.Name) {
+			continue
+		}
+		var uncoveredInFile []int
+		for _, line := range file.AddedLines() {
+			lineCovered, lineInstrumented := p.report.LineCoverage(file.Name, line)
+			if !lineInstrumented {
+				continue
+			}
+			instrumented++
+			if lineCovered {
+				covered++
+			} else {
+				uncoveredInFile = append(uncoveredInFile, line)
+			}
+		}
+		if len(uncoveredInFile) > 0 {
+			uncovered = append(uncovered, uncoveredLines{file: file.Name, ranges: toLineRanges(uncoveredInFile)})
+		}
+	}
+	if instrumented == 0 {
+		return anyTestPolicy{}.evaluate(fileCategories)
+	}
+	measurement := testMeasurement{
+		name:      "diff coverage",
+		measured:  float64(covered) / float64(instrumented),
+		threshold: p.minDiffCoverage,
+		uncovered: uncovered,
+	}
+	return testMeasurements{measurement}, measurement.satisfied()
+}
+
+// uncoveredLines holds ranges of lines added to the file which are not covered by tests
+type uncoveredLines struct {
+	file   string
+	ranges []lineRange
+}
+
+type lineRange struct {
+	start, end int
+}
+
+func (r lineRange) String() string {
+	if r.start == r.end {
+		return strconv.Itoa(r.start)
+	}
+	return fmt.Sprintf("%d-%d", r.start, r.end)
+}
+
+func (u uncoveredLines) String() string {
+	ranges := make([]string, 0, len(u.ranges))
+	for _, lines := range u.ranges {
+		ranges = append(ranges, lines.String())
+	}
+	return fmt.Sprintf("`%s` lines %s", u.file, strings.Join(ranges, ", "))
+}
+
+// toLineRanges joins consecutive numbers of the sorted lines into ranges
+func toLineRanges(lines []int) []lineRange {
+	var ranges []lineRange
+	for _, line := range lines {
+		if last := len(ranges) - 1; last >= 0 && ranges[last].end+1 == line {
+			ranges[last].end = line
+			continue
+		}
+		ranges = append(ranges, lineRange{start: line, end: line})
+	}
+	return ranges
+}
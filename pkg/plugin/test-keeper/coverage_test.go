@@ -0,0 +1,77 @@
+package testkeeper_test
+
+import (
+	"io/ioutil"
+
+	testkeeper "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Coverage report features", func() {
+
+	loadReport := func(format, file string) *testkeeper.CoverageReport {
+		content, err := ioutil.ReadFile("test_fixtures/coverage/" + file)
+		Ω(err).ShouldNot(HaveOccurred())
+		report, err := testkeeper.ParseCoverageReport(format, content)
+		Ω(err).ShouldNot(HaveOccurred())
+		return report
+	}
+
+	Context("Parsing coverage reports", func() {
+
+		table.DescribeTable("should find coverage of the line of the file in the repository",
+			func(format, reportFile, file string, line int, expectedCovered, expectedInstrumented bool) {
+				// given
+				report := loadReport(format, reportFile)
+
+				// when
+				covered, instrumented := report.LineCoverage(file, line)
+
+				// then
+				Expect(covered).To(Equal(expectedCovered))
+				Expect(instrumented).To(Equal(expectedInstrumented))
+			},
+			table.Entry("Cobertura covered line", testkeeper.CoberturaFormat, "cobertura.xml",
+				"src/main/java/io/openshift/booster/service/Greeting.java", 5, true, true),
+			table.Entry("Cobertura uncovered line", testkeeper.CoberturaFormat, "cobertura.xml",
+				"src/main/java/io/openshift/booster/service/Greeting.java", 6, false, true),
+			table.Entry("Cobertura line not instrumented", testkeeper.CoberturaFormat, "cobertura.xml",
+				"src/main/java/io/openshift/booster/service/Greeting.java", 4, false, false),
+			table.Entry("JaCoCo covered line", testkeeper.JaCoCoFormat, "jacoco.xml",
+				"src/main/java/io/openshift/booster/service/Greeting.java", 6, true, true),
+			table.Entry("JaCoCo file not present in the report", testkeeper.JaCoCoFormat, "jacoco.xml",
+				"src/main/java/io/openshift/booster/service/GreetingService.java", 10, false, false),
+			table.Entry("Go covered line of a block", testkeeper.GoCoverFormat, "coverage.out",
+				"pkg/greeter/greeter.go", 9, true, true),
+			table.Entry("Go uncovered line of a block", testkeeper.GoCoverFormat, "coverage.out",
+				"pkg/greeter/greeter.go", 11, false, true),
+		)
+
+		It("should fail when the report is not in the expected format", func() {
+			// given
+			content, err := ioutil.ReadFile("test_fixtures/coverage/coverage.out")
+			Ω(err).ShouldNot(HaveOccurred())
+
+			// when
+			_, err = testkeeper.ParseCoverageReport(testkeeper.JaCoCoFormat, content)
+
+			// then
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should report the line of unexpected content without including the content itself", func() {
+			// given
+			content := []byte("mode: set\npkg/greeter/greeter.go:8.2,9.3 1 1\nsecret-token\n")
+
+			// when
+			_, err := testkeeper.ParseCoverageReport(testkeeper.GoCoverFormat, content)
+
+			// then
+			Ω(err).Should(HaveOccurred())
+			Ω(err.Error()).Should(ContainSubstring("line 3"))
+			Ω(err.Error()).ShouldNot(ContainSubstring("secret-token"))
+		})
+	})
+})
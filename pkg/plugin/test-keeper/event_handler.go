@@ -1,7 +1,13 @@
 package testkeeper
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/arquillian/ike-prow-plugins/pkg/command"
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
 	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
 	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
@@ -15,6 +21,8 @@ import (
 type GitHubTestEventsHandler struct {
 	Client  ghclient.Client
 	BotName string
+	// CoverageReportHosts lists hosts the coverage reports can be downloaded from using report_url
+	CoverageReportHosts []string
 }
 
 // ProwPluginName is an external prow plugin name used to register this service
@@ -63,9 +71,24 @@ func (gh *GitHubTestEventsHandler) HandleIssueCommentEvent(logger log.Logger, co
 			if err != nil {
 				return err
 			}
-			reportBypassCommand(pullRequest)
-			statusService := gh.newTestStatusService(logger, pullRequest)
-			return statusService.okWithoutTests(*comment.Sender.Login)
+			configuration, err := LoadConfiguration(logger, gh.Client, ghservice.NewRepositoryChangeForPR(pullRequest))
+			statusService := gh.newTestStatusService(logger, pullRequest, configuration)
+			if err != nil {
+				return statusService.reportConfigError(err)
+			}
+			reason := BypassReason(*comment.Comment.Body)
+			if configuration.Bypass.RequireReason && reason == "" {
+				msg := fmt.Sprintf(MissingBypassReasonMsg, *comment.Sender.Login)
+				return ghservice.NewCommentService(gh.Client, comment).AddComment(&msg)
+			}
+			reportBypassCommand(pullRequest, reason)
+			return statusService.okWithoutTests(bypass{user: *comment.Sender.Login, reason: reason})
+		}})
+
+	cmdHandler.Register(&RequireTestsCmd{
+		userPermissionService: userPerm,
+		whenDeletedOrTriggered: func() error {
+			return gh.checkTestsAndSetStatus(logger, prLoader)
 		}})
 
 	err := cmdHandler.Handle(logger, comment)
@@ -75,21 +98,173 @@ func (gh *GitHubTestEventsHandler) HandleIssueCommentEvent(logger log.Logger, co
 	return err
 }
 
+// HandleCheckRunEvent approves the pull request without tests when the user with sufficient permissions requests
+// such action from the check run created by the plugin. The approval is recorded in a comment, so it is found
+// by checkIfBypassed (and can be revoked) the same way as the bypass command
+func (gh *GitHubTestEventsHandler) HandleCheckRunEvent(logger log.Logger, event *gogh.CheckRunEvent) error {
+	if event.GetAction() != github.ActionRequested || event.GetRequestedAction() == nil ||
+		event.GetRequestedAction().Identifier != OkWithoutTestsActionID ||
+		event.GetCheckRun().GetName() != fmt.Sprintf("%s/%s", gh.BotName, ProwPluginName) {
+		return nil
+	}
+
+	sender := event.GetSender().GetLogin()
+	for _, pr := range event.GetCheckRun().PullRequests {
+		prLoader := &ghservice.PullRequestLazyLoader{
+			Client:    gh.Client,
+			RepoOwner: event.GetRepo().GetOwner().GetLogin(),
+			RepoName:  event.GetRepo().GetName(),
+			Number:    pr.GetNumber(),
+		}
+		permissionStatus, err := command.AllOf(whoCanTrigger(command.NewPermissionService(gh.Client, sender, prLoader))...)(true)
+		if err != nil {
+			return err
+		}
+		if !permissionStatus.UserIsApproved {
+			logger.Warnf("user %q is not allowed to approve PR #%d without tests", sender, pr.GetNumber())
+			continue
+		}
+
+		pullRequest, err := prLoader.Load()
+		if err != nil {
+			return err
+		}
+		configuration, err := LoadConfiguration(logger, gh.Client, ghservice.NewRepositoryChangeForPR(pullRequest))
+		statusService := gh.newTestStatusService(logger, pullRequest, configuration)
+		if err != nil {
+			return statusService.reportConfigError(err)
+		}
+		if configuration.Bypass.RequireReason {
+			logger.Warnf("PR #%d cannot be approved without tests by %q using the check run action as a reason is required",
+				pr.GetNumber(), sender)
+			continue
+		}
+		approvalMsg := fmt.Sprintf(ApprovedByActionMsg, sender)
+		issue := scm.NewRepositoryIssue(prLoader.RepoOwner, prLoader.RepoName, pr.GetNumber())
+		if err := gh.Client.CreateIssueComment(*issue, &approvalMsg); err != nil {
+			return err
+		}
+		reportBypassCommand(pullRequest, "")
+		if err := statusService.okWithoutTests(bypass{user: sender}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIfBypassed goes through the history of bypass and revoke commands commented on the PR (in the order the comments
+// were created) and tells if the PR is bypassed by the latest valid bypass command which hasn't been revoked since.
+// The approvals given using the check run action are recorded by the comments of the bot, so they are taken into account as well.
+// When the bypass expires on new commits, it's ignored if any commit changing production files has been pushed after it
 func (gh *GitHubTestEventsHandler) checkIfBypassed(logger log.Logger, commentsLoader *ghservice.IssueCommentsLazyLoader,
-	pr *gogh.PullRequest) (found bool, comment string) {
+	pr *gogh.PullRequest, configuration *PluginConfiguration, languages []string) (bypass, bool) {
 	comments, err := commentsLoader.Load()
 	if err != nil {
 		logger.Errorf("Getting all comments failed with an error: %s", err)
-		return false, ""
+		return bypass{}, false
 	}
 
 	prLoader := ghservice.NewPullRequestLazyLoaderWithPR(gh.Client, pr)
+	var approval *bypass
 	for _, comment := range comments {
-		if IsValidBypassCmd(comment, prLoader) {
-			return true, *comment.User.Login
+		if IsValidBypassCmd(comment, prLoader, configuration.Bypass) {
+			latest := newBypass(comment)
+			approval = &latest
+		} else if approver := approverOf(comment, gh.BotName); approver != "" && !configuration.Bypass.RequireReason &&
+			isValidApprover(approver, prLoader) {
+			latest := newBypass(comment)
+			latest.user, latest.reason = approver, ""
+			approval = &latest
+		} else if approval != nil && IsValidRequireTestsCmd(comment, prLoader) {
+			approval = nil
+		}
+	}
+	if approval == nil {
+		return bypass{}, false
+	}
+
+	if configuration.Bypass.ExpireOnNewCommits &&
+		gh.productionChangedAfter(logger, pr, configuration, languages, approval.approvedAt) {
+		logger.Warnf("bypass by %q of PR #%d expired as production files have been changed after it", approval.user, *pr.Number)
+		return bypass{}, false
+	}
+	return *approval, true
+}
+
+// productionChangedAfter tells if any of the commits of the PR committed after the given time changes any production
+// file. When the commits cannot be retrieved, the failure is only logged and the bypass is kept
+func (gh *GitHubTestEventsHandler) productionChangedAfter(logger log.Logger, pr *gogh.PullRequest,
+	configuration *PluginConfiguration, languages []string, approvedAt time.Time) bool {
+	change := ghservice.NewRepositoryChangeForPR(pr)
+	commits, err := gh.Client.ListPullRequestCommits(change.Owner, change.RepoName, *pr.Number)
+	if err != nil {
+		logger.Errorf("failed to list commits of PR [%q]. cause: %s", *pr, err)
+		return false
+	}
+
+	var changedFiles []scm.ChangedFile
+	for _, prCommit := range commits {
+		if !prCommit.GetCommit().GetCommitter().GetDate().After(approvedAt) {
+			continue
+		}
+		commit, err := gh.Client.GetCommit(scm.RepositoryChange{Owner: change.Owner, RepoName: change.RepoName, Hash: prCommit.GetSHA()})
+		if err != nil {
+			logger.Errorf("failed to get commit %s of PR [%q]. cause: %s", prCommit.GetSHA(), *pr, err)
+			return false
+		}
+		for _, file := range commit.Files {
+			changedFiles = append(changedFiles, *scm.NewChangedFile(&file))
+		}
+	}
+	if len(changedFiles) == 0 {
+		return false
+	}
+
+	matcher, err := LoadMatcherFor(configuration, languages, changedFiles)
+	if err != nil {
+		logger.Errorf("failed to load test matcher for PR [%q]. cause: %s", *pr, err)
+		return false
+	}
+	fileCategoryCounter := FileCategoryCounter{Matcher: matcher}
+	fileCategories, err := fileCategoryCounter.Count(changedFiles)
+	if err != nil {
+		logger.Errorf("failed to categorize files of commits of PR [%q]. cause: %s", *pr, err)
+		return false
+	}
+	return len(fileCategories.Production) > 0
+}
+
+// HandleStatusEvent verifies again the open pull requests whose head commit the status has been set for, when the
+// coverage report of their repository is taken from the status of this context. So the pending status waiting for the report
+// is resolved as soon as CI publishes it
+func (gh *GitHubTestEventsHandler) HandleStatusEvent(logger log.Logger, event *gogh.StatusEvent) error {
+	if event.GetState() == "pending" || strings.HasPrefix(event.GetContext(), gh.BotName+"/") {
+		return nil
+	}
+	// the configuration is loaded from the commit the status is set for, which is the head of the pull requests to verify
+	change := scm.RepositoryChange{Owner: event.GetRepo().GetOwner().GetLogin(), RepoName: event.GetRepo().GetName(), Hash: event.GetSHA()}
+	configuration, err := LoadConfiguration(logger, gh.Client, change)
+	if err != nil {
+		logger.Warnf("configuration of commit %s was not loaded, so it's not verified on %q status. cause: %s",
+			change.Hash, event.GetContext(), err)
+		return nil
+	}
+	if !configuration.usesCoverage() || configuration.Coverage.StatusContext != event.GetContext() {
+		return nil
+	}
+	prs, err := gh.Client.ListPullRequestsWithCommit(change.Owner, change.RepoName, change.Hash)
+	if err != nil {
+		return err
+	}
+	for _, pr := range prs {
+		if pr.GetState() != "open" || pr.GetHead().GetSHA() != change.Hash {
+			continue
+		}
+		if err := gh.checkTestsAndSetStatus(logger, ghservice.NewPullRequestLazyLoaderWithPR(gh.Client, pr)); err != nil {
+			return err
 		}
 	}
-	return false, ""
+	return nil
 }
 
 func (gh *GitHubTestEventsHandler) checkTestsAndSetStatus(logger log.Logger, prLoader *ghservice.PullRequestLazyLoader) error {
@@ -98,11 +273,15 @@ func (gh *GitHubTestEventsHandler) checkTestsAndSetStatus(logger log.Logger, prL
 		return err
 	}
 	change := ghservice.NewRepositoryChangeForPR(pr)
-	configuration := LoadConfiguration(logger, change)
-	fileCategories, err := gh.checkTests(logger, change, configuration, *pr.Number)
+	configuration, err := LoadConfiguration(logger, gh.Client, change)
 	commentsLoader := ghservice.NewIssueCommentsLazyLoader(gh.Client, pr)
-
 	statusService := gh.newTestStatusServiceWithMessages(logger, pr, commentsLoader, configuration)
+	if err != nil {
+		return statusService.reportConfigError(err)
+	}
+
+	languages := gh.listLanguages(logger, change)
+	results, err := gh.checkTests(logger, change, configuration, languages, *pr.Number)
 	if err != nil {
 		if statusErr := statusService.reportError(); statusErr != nil {
 			logger.Errorf("failed to report error status on PR [%q]. cause: %s", *pr, statusErr)
@@ -110,52 +289,95 @@ func (gh *GitHubTestEventsHandler) checkTestsAndSetStatus(logger log.Logger, prL
 		return err
 	}
 
-	if fileCategories.OnlySkippedFiles() {
+	if ghservice.ChangesConfiguration(ProwPluginName, results.files) {
+		gh.previewConfiguration(logger, pr, commentsLoader, configuration, languages)
+	}
+
+	if results.onlySkippedFiles() {
 		statusService.onlySkippedMessage()
 		return statusService.okOnlySkippedFiles()
 	}
 
-	if fileCategories.TestsExist() {
+	if configuration.usesCoverage() {
+		report, err := loadCoverageReport(gh.Client, pr, configuration.Coverage, gh.CoverageReportHosts)
+		if config.IsValidationError(err) {
+			return statusService.reportConfigError(err)
+		}
+		if scm.IsNotFound(err) {
+			if approval, bypassed := gh.checkIfBypassed(logger, commentsLoader, pr, configuration, languages); bypassed {
+				reportBypassCommand(pr, approval.reason)
+				return statusService.okWithoutTests(approval)
+			}
+			if configuration.Coverage.StatusContext != "" {
+				// the check is run again when CI sets the status, see HandleStatusEvent
+				return statusService.waitForCoverageReport()
+			}
+			return statusService.failCoverageReportNotFound()
+		}
+		if err != nil {
+			logger.Errorf("failed to load coverage report of PR [%q]. cause: %s", *pr, err)
+			if statusErr := statusService.reportError(); statusErr != nil {
+				logger.Errorf("failed to report error status on PR [%q]. cause: %s", *pr, statusErr)
+			}
+			return err
+		}
+		if results, err = verifyModules(configuration, languages, results.files, report); err != nil {
+			logger.Error(err)
+			if statusErr := statusService.reportError(); statusErr != nil {
+				logger.Errorf("failed to report error status on PR [%q]. cause: %s", *pr, statusErr)
+			}
+			return err
+		}
+	}
+
+	if results.sufficient() {
 		reportPullRequest(logger, pr, WithTests)
-		statusService.withTestsMessage()
-		return statusService.okTestsExist()
+		statusService.withTestsMessage(results)
+		return statusService.okTestsExist(results)
 	}
 
-	bypassed, user := gh.checkIfBypassed(logger, commentsLoader, pr)
-	if bypassed {
-		reportBypassCommand(pr)
-		return statusService.okWithoutTests(user)
+	if approval, bypassed := gh.checkIfBypassed(logger, commentsLoader, pr, configuration, languages); bypassed {
+		reportBypassCommand(pr, approval.reason)
+		return statusService.okWithoutTests(approval)
 	}
 
 	reportPullRequest(logger, pr, WithoutTests)
-	statusService.withoutTestsMessage()
-	err = statusService.failNoTests()
+	statusService.withoutTestsMessage(results)
+	err = statusService.failNoTests(results, !configuration.Bypass.RequireReason)
 	if err != nil {
 		logger.Errorf("failed to report status on PR [%q]. cause: %s", *pr, err)
 	}
 	return err
 }
 
-func (gh *GitHubTestEventsHandler) checkTests(logger log.Logger, change scm.RepositoryChange,
-	config *PluginConfiguration, prNumber int) (FileCategories, error) {
-	matcher, err := LoadMatcher(config)
+// listLanguages lists languages used in the repository so only their test patterns are applied. Any failure is only
+// logged, as then the languages are detected using the changed files instead
+func (gh *GitHubTestEventsHandler) listLanguages(logger log.Logger, change scm.RepositoryChange) []string {
+	repoLanguages, err := gh.Client.ListLanguages(change.Owner, change.RepoName)
 	if err != nil {
-		logger.Error(err)
-		return FileCategories{}, err
+		logger.Warnf("failed to list languages of the repository %s/%s. cause: %s", change.Owner, change.RepoName, err)
+		return nil
 	}
+	languages := make([]string, 0, len(repoLanguages))
+	for language := range repoLanguages {
+		languages = append(languages, language)
+	}
+	return languages
+}
 
-	fileCategoryCounter := FileCategoryCounter{Matcher: matcher}
-
+// checkTests verifies the changed files of the PR without the coverage report, which is loaded only when it is needed
+func (gh *GitHubTestEventsHandler) checkTests(logger log.Logger, change scm.RepositoryChange,
+	config *PluginConfiguration, languages []string, prNumber int) (verificationResults, error) {
 	changedFiles, err := gh.Client.ListPullRequestFiles(change.Owner, change.RepoName, prNumber)
 	if err != nil {
 		logger.Error(err)
-		return FileCategories{}, err
+		return verificationResults{}, err
 	}
 
-	fileCategories, err := fileCategoryCounter.Count(changedFiles)
+	results, err := verifyModules(config, languages, changedFiles, nil)
 	if err != nil {
 		logger.Error(err)
 	}
 
-	return fileCategories, err
+	return results, err
 }
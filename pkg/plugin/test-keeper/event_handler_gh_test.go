@@ -4,11 +4,13 @@ import (
 	"fmt"
 
 	"github.com/arquillian/ike-prow-plugins/pkg/command"
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
 	"github.com/arquillian/ike-prow-plugins/pkg/github"
 	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
 	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	testkeeper "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
+	"github.com/arquillian/ike-prow-plugins/pkg/status/message"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	gock "gopkg.in/h2non/gock.v1"
@@ -27,7 +29,8 @@ var _ = Describe("Test Keeper Plugin features", func() {
 
 		BeforeEach(func() {
 			defer gock.OffAll()
-			handler = &testkeeper.GitHubTestEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+			handler = &testkeeper.GitHubTestEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName,
+				CoverageReportHosts: []string{"ci.example.com"}}
 		})
 
 		AfterEach(EnsureGockRequestsHaveBeenMatched)
@@ -78,9 +81,18 @@ var _ = Describe("Test Keeper Plugin features", func() {
 				WithConfigFile(
 					ConfigYml(Containing(
 						Param("skip_validation_for", "['**/Randomfile']")))).
+				WithoutBaseConfigFiles().
+				WithMergedPullRequests(LoadedFrom("test_fixtures/github_calls/prs/merged_prs.json")).
+				WithFilesOfPullRequest(41, LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes-with-test-keeper-config-excluding-other-file-from-PR.json")).
+				WithFilesOfPullRequest(39, LoadedFrom("test_fixtures/github_calls/prs/without_tests/build_and_docs_only_changes.json")).
 				WithoutComments().
 				Expecting(
 					Status(ToBe(github.StatusSuccess, testkeeper.OkOnlySkippedFilesMessage, testkeeper.OkOnlySkippedFilesDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains(fmt.Sprintf(message.PluginTitleTemplate, testkeeper.ConfigPreviewName)),
+						HaveBodyThatContains(fmt.Sprintf(testkeeper.ConfigPreviewChangesMsg, 2)),
+						HaveBodyThatContains("Adds Randomfile: without tests → only skipped files"),
+						HaveBodyThatContains("* `Randomfile`: production → skipped"))),
 					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
 				Create()
 
@@ -131,14 +143,21 @@ var _ = Describe("Test Keeper Plugin features", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
-		It("should not block newly created pull request when documentation and build files are the only changes", func() {
+		It("should block pull request when tests are not proportional to the changes of the production code", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
-				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/build_and_docs_only_changes.json")).
-				WithoutConfigFiles().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_small_test.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "proportional"),
+						Param("min_test_ratio", "0.5")))).
+				WithoutMessageFiles("test-keeper_insufficient_tests_message.md").
 				WithoutComments().
 				Expecting(
-					Status(ToBe(github.StatusSuccess, testkeeper.OkOnlySkippedFilesMessage, testkeeper.OkOnlySkippedFilesDetailsPageName))).
+					Status(ToBe(github.StatusFailure, "Not enough tests: test/production lines 0.01 (required 0.50)",
+						testkeeper.InsufficientTestsDetailsPageName)),
+					Comment(ContainingStatusMessage("not proportional to the changes of the production code: "+
+						"test/production lines 0.01 (required 0.50)"))).
 				Create()
 
 			// when
@@ -148,16 +167,18 @@ var _ = Describe("Test Keeper Plugin features", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
-		It("should block newly created pull request when deletions in the tests are the only changes", func() {
+		It("should approve pull request when tests are proportional to the changes of the production code", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
-				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/deletions_only_changes_in_tests.json")).
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_small_test.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "proportional"),
+						Param("production_files_per_test", "2")))).
 				WithoutComments().
-				WithoutConfigFiles().
-				WithoutMessageFiles("test-keeper_without_tests_message.md").
 				Expecting(
-					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg)),
-					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName))).
+					Status(ToBe(github.StatusSuccess, "Tests are proportional to the changes: test/production files 0.50 (required 0.50)",
+						testkeeper.TestsExistDetailsPageName))).
 				Create()
 
 			// when
@@ -167,16 +188,20 @@ var _ = Describe("Test Keeper Plugin features", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
-		It("should block newly created pull request when there are changes in the business logic but only deletions in the tests", func() {
+		It("should block newly created pull request and list production files without a matching test when paired policy is used", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
-				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/prod_code_changes_with_deletion_only_in_tests.json")).
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_unpaired_file.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "paired")))).
+				WithoutMessageFiles("test-keeper_insufficient_tests_message.md").
 				WithoutComments().
-				WithoutConfigFiles().
-				WithoutMessageFiles("test-keeper_without_tests_message.md").
 				Expecting(
-					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg)),
-					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName))).
+					Status(ToBe(github.StatusFailure, "Not enough tests: paired production files 0.50 (required 1.00)",
+						testkeeper.InsufficientTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.MissingTestsMsg+
+						"\n* `src/main/java/io/openshift/booster/service/Greeting.java`"))).
 				Create()
 
 			// when
@@ -186,37 +211,78 @@ var _ = Describe("Test Keeper Plugin features", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
-		It("should send ok status when PR contains no test but a comment with bypass command is present", func() {
-			approvedBy := fmt.Sprintf(testkeeper.ApprovedByMessage, "bartoszmajsak")
+		It("should block newly created pull request when lines added to production code are not covered according to the report of CI", func() {
+			// given
+			gock.New("https://ci.example.com").
+				Get("/coverage/cobertura.xml").
+				Reply(200).
+				File("test_fixtures/coverage/cobertura.xml")
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_patches.json")).
+				WithCombinedStatus(`{"state": "success", "statuses": [`+
+					`{"context": "ci/coverage", "state": "success", "target_url": "https://ci.example.com/coverage/cobertura.xml"}]}`).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "coverage"),
+						Param("coverage", "{format: cobertura, status_context: 'ci/coverage', min_diff_coverage: 0.8}")))).
+				WithoutMessageFiles("test-keeper_insufficient_tests_message.md").
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusFailure, "Not enough tests: diff coverage 0.75 (required 0.80)",
+						testkeeper.InsufficientTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.UncoveredLinesMsg+
+						"\n* `src/main/java/io/openshift/booster/service/Greeting.java` lines 6"))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
 
+		It("should approve pull request when all lines added to production code are covered according to the report", func() {
+			// given
+			gock.New("https://ci.example.com").
+				Get("/bartoszmajsak/wfswarm-booster-pipeline-test/pulls/1/jacoco.xml").
+				Reply(200).
+				File("test_fixtures/coverage/jacoco.xml")
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
-				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
-				WithUsers(Admin("bartoszmajsak")).
-				WithComments(`[{"user":{"login":"bartoszmajsak"}, "body":"` + testkeeper.BypassCheckComment + `"}]`).
-				WithoutReviews().
-				WithoutConfigFiles().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_patches.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "coverage"),
+						Param("coverage", "{format: jacoco, report_url: 'https://ci.example.com/{owner}/{repo}/pulls/{number}/jacoco.xml', "+
+							"min_diff_coverage: 0.8}")))).
+				WithoutComments().
 				Expecting(
-					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+					Status(ToBe(github.StatusSuccess, "Tests are proportional to the changes: diff coverage 1.00 (required 0.80)",
+						testkeeper.TestsExistDetailsPageName))).
 				Create()
 
 			// when
 			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
 
-			// then - should not expect any additional request mocking
+			// then - implicit verification of /statuses call occurrence with proper payload
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
-		It("should block pull request without tests and with comments containing bypass message added by user with insufficient permissions", func() {
+		It("should mark pull request as pending when CI hasn't set the status carrying the coverage report yet", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
-				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
-				WithUsers(ExternalUser("bartoszmajsak-test")).
-				WithComments(LoadedFrom("test_fixtures/github_calls/prs/comments_with_no_test_status_msg.json")).
-				WithoutReviews().
-				WithoutConfigFiles().
-				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_patches.json")).
+				WithCombinedStatus(`{"state": "pending", "statuses": [{"context": "ci/coverage", "state": "pending"}]}`).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "coverage"),
+						Param("coverage", "{format: cobertura, status_context: 'ci/coverage', min_diff_coverage: 0.8}")))).
+				WithoutComments().
 				Expecting(
-					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName))).
+					Status(func(builder *MockPrBuilder) SoftMatcher {
+						return SoftlySatisfyAll(
+							HaveState(github.StatusPending),
+							HaveDescription(testkeeper.WaitingForCoverageMessage))
+					})).
 				Create()
 
 			// when
@@ -225,58 +291,836 @@ var _ = Describe("Test Keeper Plugin features", func() {
 			// then - implicit verification of /statuses call occurrence with proper payload
 			Ω(err).ShouldNot(HaveOccurred())
 		})
-	})
 
-	Context("Pull Request comment event handling", func() {
+		It("should block pull request when the coverage report is not found at the configured URL", func() {
+			// given
+			gock.New("https://ci.example.com").
+				Get("/coverage/coverage.out").
+				Reply(404)
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_patches.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "coverage"),
+						Param("coverage", "{format: go, report_url: 'https://ci.example.com/coverage/coverage.out', min_diff_coverage: 0.8}")))).
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.CoverageReportNotFoundMessage,
+						testkeeper.CoverageReportNotFoundDetailsPageName))).
+				Create()
 
-		BeforeEach(func() {
-			defer gock.OffAll()
-			handler = &testkeeper.GitHubTestEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
 		})
 
-		AfterEach(EnsureGockRequestsHaveBeenMatched)
+		It("should not load the coverage report when only files skipped from the verification are changed", func() {
+			// given - no coverage report is mocked, so any attempt to download it fails the test
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/build_and_docs_only_changes.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "coverage"),
+						Param("coverage", "{format: go, report_url: 'https://ci.example.com/coverage/coverage.out', min_diff_coverage: 0.8}")))).
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, testkeeper.OkOnlySkippedFilesMessage, testkeeper.OkOnlySkippedFilesDetailsPageName))).
+				Create()
 
-		It("should skip test existence check when "+testkeeper.BypassCheckComment+" command is used by admin user", func() {
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should verify pull request again when CI sets the status carrying the coverage report", func() {
 			// given
-			approvedBy := fmt.Sprintf(testkeeper.ApprovedByMessage, "bartoszmajsak")
+			gock.New("https://ci.example.com").
+				Get("/coverage/cobertura.xml").
+				Reply(200).
+				File("test_fixtures/coverage/cobertura.xml")
+			coverageConfig := ConfigYml(Containing(
+				Param("policy", "coverage"),
+				Param("coverage", "{format: cobertura, status_context: 'ci/coverage', min_diff_coverage: 0.7}")))
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
-				WithUsers(Admin("bartoszmajsak")).
-				WithoutReviews().
+				WithPullRequestsOfHeadCommit().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_patches.json")).
+				WithCombinedStatus(`{"state": "success", "statuses": [` +
+					`{"context": "ci/coverage", "state": "success", "target_url": "https://ci.example.com/coverage/cobertura.xml"}]}`).
+				// loaded once to find out if the status carries the coverage report and once to verify the pull request
+				WithConfigFile(coverageConfig).
+				WithConfigFile(coverageConfig).
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, "Tests are proportional to the changes: diff coverage 0.75 (required 0.70)",
+						testkeeper.TestsExistDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandleStatusEvent(log,
+				prMock.CreateStatusEvent("ci/coverage", "success", "https://ci.example.com/coverage/cobertura.xml"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should ignore status of other context than the one carrying the coverage report", func() {
+			// given - nothing but the configuration is mocked, so neither pull requests are looked up nor verified
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "coverage"),
+						Param("coverage", "{format: cobertura, status_context: 'ci/coverage', min_diff_coverage: 0.7}")))).
+				Create()
+
+			// when
+			err := handler.HandleStatusEvent(log,
+				prMock.CreateStatusEvent("ci/build", "success", "https://ci.example.com/build/1"))
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should report error status when the coverage report is configured to be downloaded from a host which is not allowed", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_patches.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "coverage"),
+						Param("coverage", "{format: go, report_url: 'http://169.254.169.254/latest/meta-data', min_diff_coverage: 0.8}")))).
+				WithoutMessageFiles("test-keeper_invalid_config_message.md").
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusError, config.InvalidConfigMessage, "")),
+					Comment(ContainingStatusMessage("field `report_url`: the coverage report has to be downloaded using http(s) "+
+						"from one of the hosts allowed"))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should report error status when the status carrying the coverage report targets a host which is not allowed", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_patches.json")).
+				WithCombinedStatus(`{"state": "success", "statuses": [`+
+					`{"context": "ci/coverage", "state": "success", "target_url": "http://169.254.169.254/latest/meta-data"}]}`).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "coverage"),
+						Param("coverage", "{format: cobertura, status_context: 'ci/coverage', min_diff_coverage: 0.8}")))).
+				WithoutMessageFiles("test-keeper_invalid_config_message.md").
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusError, config.InvalidConfigMessage, "")),
+					Comment(ContainingStatusMessage("field `status_context`: the coverage report has to be downloaded using http(s) "+
+						"from one of the hosts allowed"))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should report error status when the coverage report is redirected to a host which is not allowed", func() {
+			// given
+			gock.New("https://ci.example.com").
+				Get("/coverage/coverage.out").
+				Reply(302).
+				SetHeader("Location", "http://169.254.169.254/latest/meta-data")
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_patches.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("policy", "coverage"),
+						Param("coverage", "{format: go, report_url: 'https://ci.example.com/coverage/coverage.out', min_diff_coverage: 0.8}")))).
+				WithoutMessageFiles("test-keeper_invalid_config_message.md").
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusError, config.InvalidConfigMessage, "")),
+					Comment(ContainingStatusMessage("field `report_url`: the coverage report has to be downloaded using http(s) "+
+						"from one of the hosts allowed"))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).Should(HaveOccurred())
+		})
+
+		It("should approve pull request and report the number of new test cases", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/changes_with_new_test_cases.json")).
 				WithoutConfigFiles().
+				WithoutComments().
 				Expecting(
-					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+					Status(ToBe(github.StatusSuccess, fmt.Sprintf(testkeeper.NewTestCasesMessage, 2), testkeeper.TestsExistDetailsPageName))).
 				Create()
 
-			event := prMock.CreateCommentEvent(SentBy("bartoszmajsak"), testkeeper.BypassCheckComment, "created")
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should block pull request when tests are only reformatted, commented or renamed", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes_with_reformatted_test.json")).
+				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
+				Create()
 
 			// when
-			err := handler.HandleIssueCommentEvent(log, event)
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
 
 			// then - implicit verification of /statuses call occurrence with proper payload
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
-		It("should ignore "+testkeeper.BypassCheckComment+" when used by non-admin user", func() {
+		It("should block newly created pull request when the only test matches patterns of a language not used in the repository", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
-				WithUsers(ExternalUser("bartoszmajsak-test")).
-				WithoutReviews().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes_with_python_script.json")).
+				WithLanguages(`{"Java": 24680, "Shell": 135}`).
 				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should block pull request when one of the changed modules violates its policy and break the results down per module", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/with_tests/monorepo_changes.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("modules", "[{path: 'infra/', skip_validation_for: ['*']}, "+
+							"{path: 'services/', policy: paired}, {path: 'frontend/'}]")))).
+				WithoutMessageFiles("test-keeper_modules_without_tests_message.md").
+				WithoutComments().
 				Expecting(
+					Status(ToBe(github.StatusFailure, "Not enough tests in modules: frontend/",
+						testkeeper.InsufficientTestsDetailsPageName)),
 					Comment(To(
-						HaveBodyThatContains("Hey @bartoszmajsak-test! It seems you tried to trigger `/ok-without-tests` command"),
-						HaveBodyThatContains("You have to be admin or requested reviewer or pull request approver, but not pull request creator"))),
-					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName))).
+						HaveBodyThatContains("| `infra/` | :white_check_mark: only skipped files |"),
+						HaveBodyThatContains("| `services/` | :white_check_mark: with tests "+
+							"(paired production files 1.00 (required 1.00)) |"),
+						HaveBodyThatContains("| `frontend/` | :x: without tests |"),
+						HaveBodyThatContains("| other files | :white_check_mark: only skipped files |")))).
 				Create()
 
-			event := prMock.CreateCommentEvent(SentBy("bartoszmajsak-test"), testkeeper.BypassCheckComment, "created")
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should not assign files of nested directories with the same name to the module", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/nested_module_lookalike_changes.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("modules", "[{path: 'api/', skip_validation_for: ['*']}]")))).
+				WithoutMessageFiles("test-keeper_modules_without_tests_message.md").
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusFailure, "Not enough tests in modules: other files",
+						testkeeper.InsufficientTestsDetailsPageName)),
+					Comment(To(
+						HaveBodyThatContains("| `api/` | :white_check_mark: only skipped files |"),
+						HaveBodyThatContains("| other files | :x: without tests |")))).
+				Create()
 
 			// when
-			err := handler.HandleIssueCommentEvent(log, event)
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should not block newly created pull request when documentation and build files are the only changes", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/build_and_docs_only_changes.json")).
+				WithoutConfigFiles().
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, testkeeper.OkOnlySkippedFilesMessage, testkeeper.OkOnlySkippedFilesDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
 
 			// then - implicit verification of /statuses call occurrence with proper payload
 			Ω(err).ShouldNot(HaveOccurred())
 		})
+
+		It("should block newly created pull request when deletions in the tests are the only changes", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/deletions_only_changes_in_tests.json")).
+				WithoutComments().
+				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				Expecting(
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg)),
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should block newly created pull request when there are changes in the business logic but only deletions in the tests", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/prod_code_changes_with_deletion_only_in_tests.json")).
+				WithoutComments().
+				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				Expecting(
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg)),
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should send ok status when PR contains no test but a comment with bypass command is present", func() {
+			approvedBy := fmt.Sprintf(testkeeper.ApprovedByMessage, "bartoszmajsak")
+
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithUsers(Admin("bartoszmajsak")).
+				WithComments(`[{"user":{"login":"bartoszmajsak"}, "body":"` + testkeeper.BypassCheckComment + `"}]`).
+				WithoutReviews().
+				WithoutConfigFiles().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - should not expect any additional request mocking
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should send ok status with the reason given in the bypass command", func() {
+			// given
+			approvedBy := fmt.Sprintf(testkeeper.ApprovedByWithReasonMessage, "bartoszmajsak", "only the pipeline version is bumped")
+
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithUsers(Admin("bartoszmajsak")).
+				WithComments(`[{"user":{"login":"bartoszmajsak"}, "body":"` + testkeeper.BypassCheckComment +
+					` only the pipeline version is bumped"}]`).
+				WithoutReviews().
+				WithoutConfigFiles().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - should not expect any additional request mocking
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should send ok status with the reason given on the next line after the bypass command", func() {
+			// given
+			approvedBy := fmt.Sprintf(testkeeper.ApprovedByWithReasonMessage, "bartoszmajsak", "only the pipeline version is bumped")
+
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("bypass", "{require_reason: true}")))).
+				WithUsers(Admin("bartoszmajsak")).
+				WithComments(`[{"user":{"login":"bartoszmajsak"}, "body":"` + testkeeper.BypassCheckComment +
+					`\r\nonly the pipeline version is bumped"}]`).
+				WithoutReviews().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - should not expect any additional request mocking
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should block pull request without tests when production files have been changed by any commit pushed after the bypass command", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("bypass", "{expire_on_new_commits: true}")))).
+				WithUsers(Admin("bartoszmajsak")).
+				WithComments(`[{"user":{"login":"bartoszmajsak"}, "created_at": "2018-06-15T13:37:36Z", `+
+					`"body":"`+testkeeper.BypassCheckComment+`"}]`).
+				WithCommits(`[{"sha": "1f3c2b0", "commit": {"committer": {"date": "2018-06-15T10:00:00Z"}}}, `+
+					`{"sha": "7a9e4d1", "commit": {"committer": {"date": "2018-06-16T08:00:00Z"}}}, `+
+					`{"sha": "df8e5cd", "commit": {"committer": {"date": "2018-06-16T09:00:00Z"}}}]`).
+				WithCommit("7a9e4d1", `{"sha": "7a9e4d1", "files": [{"filename": "Randomfile", "status": "modified", "additions": 1, "deletions": 1}]}`).
+				WithCommit("df8e5cd", `{"sha": "df8e5cd", "files": [{"filename": "README.adoc", "status": "modified", "additions": 1, "deletions": 1}]}`).
+				WithoutReviews().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("synchronize"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should keep the bypass when only files skipped from the validation have been changed after the bypass command", func() {
+			// given
+			approvedBy := fmt.Sprintf(testkeeper.ApprovedByMessage, "bartoszmajsak")
+
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("bypass", "{expire_on_new_commits: true}")))).
+				WithUsers(Admin("bartoszmajsak")).
+				WithComments(`[{"user":{"login":"bartoszmajsak"}, "created_at": "2018-06-15T13:37:36Z", `+
+					`"body":"`+testkeeper.BypassCheckComment+`"}]`).
+				WithCommits(`[{"sha": "1f3c2b0", "commit": {"committer": {"date": "2018-06-15T10:00:00Z"}}}, `+
+					`{"sha": "df8e5cd", "commit": {"committer": {"date": "2018-06-16T08:00:00Z"}}}]`).
+				WithCommit("df8e5cd", `{"sha": "df8e5cd", "files": [{"filename": "README.adoc", "status": "modified", "additions": 1, "deletions": 1}]}`).
+				WithoutReviews().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("synchronize"))
+
+			// then - should not expect any additional request mocking
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should block pull request without tests when the bypass has been revoked by "+testkeeper.RequireTestsComment+" command", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithUsers(Admin("bartoszmajsak"), Admin("matousjobanek")).
+				WithComments(`[{"user":{"login":"bartoszmajsak"}, "body":"`+testkeeper.BypassCheckComment+`"},`+
+					`{"user":{"login":"matousjobanek"}, "body":"`+testkeeper.RequireTestsComment+`"}]`).
+				WithoutReviews().
+				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should send ok status when the bypass command is used again after it has been revoked", func() {
+			// given
+			approvedBy := fmt.Sprintf(testkeeper.ApprovedByMessage, "bartoszmajsak")
+
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithUsers(Admin("bartoszmajsak"), Admin("matousjobanek")).
+				WithComments(`[{"user":{"login":"matousjobanek"}, "body":"` + testkeeper.BypassCheckComment + `"},` +
+					`{"user":{"login":"matousjobanek"}, "body":"` + testkeeper.RequireTestsComment + `"},` +
+					`{"user":{"login":"bartoszmajsak"}, "body":"` + testkeeper.BypassCheckComment + `"}]`).
+				WithoutReviews().
+				WithoutConfigFiles().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - should not expect any additional request mocking
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should block pull request without tests and with comments containing bypass message added by user with insufficient permissions", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithUsers(ExternalUser("bartoszmajsak-test")).
+				WithComments(LoadedFrom("test_fixtures/github_calls/prs/comments_with_no_test_status_msg.json")).
+				WithoutReviews().
+				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("Pull Request comment event handling", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+			handler = &testkeeper.GitHubTestEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should skip test existence check when "+testkeeper.BypassCheckComment+" command is used by admin user", func() {
+			// given
+			approvedBy := fmt.Sprintf(testkeeper.ApprovedByMessage, "bartoszmajsak")
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithUsers(Admin("bartoszmajsak")).
+				WithoutReviews().
+				WithoutConfigFiles().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+				Create()
+
+			event := prMock.CreateCommentEvent(SentBy("bartoszmajsak"), testkeeper.BypassCheckComment, "created")
+
+			// when
+			err := handler.HandleIssueCommentEvent(log, event)
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should ask for the reason when "+testkeeper.BypassCheckComment+" is used without it but the reason is required", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithUsers(Admin("bartoszmajsak")).
+				WithoutReviews().
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("bypass", "{require_reason: true}")))).
+				Expecting(
+					Comment(To(
+						HaveBodyThatContains(fmt.Sprintf(testkeeper.MissingBypassReasonMsg, "bartoszmajsak"))))).
+				Create()
+
+			event := prMock.CreateCommentEvent(SentBy("bartoszmajsak"), testkeeper.BypassCheckComment, "created")
+
+			// when
+			err := handler.HandleIssueCommentEvent(log, event)
+
+			// then - implicit verification of /comments call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should ignore "+testkeeper.BypassCheckComment+" when used by non-admin user", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithUsers(ExternalUser("bartoszmajsak-test")).
+				WithoutReviews().
+				WithoutConfigFiles().
+				Expecting(
+					Comment(To(
+						HaveBodyThatContains("Hey @bartoszmajsak-test! It seems you tried to trigger `/ok-without-tests` command"),
+						HaveBodyThatContains("You have to be admin or requested reviewer or pull request approver, but not pull request creator"))),
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName))).
+				Create()
+
+			event := prMock.CreateCommentEvent(SentBy("bartoszmajsak-test"), testkeeper.BypassCheckComment, "created")
+
+			// when
+			err := handler.HandleIssueCommentEvent(log, event)
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should check tests again when "+testkeeper.RequireTestsComment+" command is used by admin user", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithUsers(Admin("bartoszmajsak"), Admin("matousjobanek")).
+				WithComments(`[{"user":{"login":"bartoszmajsak"}, "body":"`+testkeeper.BypassCheckComment+`"},`+
+					`{"user":{"login":"matousjobanek"}, "body":"`+testkeeper.RequireTestsComment+`"}]`).
+				WithoutReviews().
+				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
+				Create()
+
+			event := prMock.CreateCommentEvent(SentBy("matousjobanek"), testkeeper.RequireTestsComment, "created")
+
+			// when
+			err := handler.HandleIssueCommentEvent(log, event)
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should ignore "+testkeeper.RequireTestsComment+" when used by external user", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithUsers(ExternalUser("bartoszmajsak-test")).
+				WithoutReviews().
+				Expecting(
+					Comment(To(
+						HaveBodyThatContains("Hey @bartoszmajsak-test! It seems you tried to trigger `/require-tests` command"),
+						HaveBodyThatContains("You have to be admin or requested reviewer or pull request approver")))).
+				Create()
+
+			event := prMock.CreateCommentEvent(SentBy("bartoszmajsak-test"), testkeeper.RequireTestsComment, "created")
+
+			// when
+			err := handler.HandleIssueCommentEvent(log, event)
+
+			// then - implicit verification of /comments call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should report error status and list the problems when configuration file exists but is invalid", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithConfigFile(ConfigYml(Containing(
+					Param("test_patterns", "['regex{{*IT.java}}']"),
+					Param("test_pattern", "['*IT.java']")))).
+				WithoutMessageFiles("test-keeper_invalid_config_message.md").
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusError, config.InvalidConfigMessage, "")),
+					Comment(ContainingStatusMessage("line 3, field `test_pattern`: unknown field"))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).Should(HaveOccurred())
+		})
+	})
+
+	Context("Pull Request event handling on GitHub Enterprise", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+			endpoints, err := github.NewEndpoints("https://github.example.com/api/v3")
+			Ω(err).ShouldNot(HaveOccurred())
+			github.UseEndpoints(endpoints)
+			handler = &testkeeper.GitHubTestEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+		})
+
+		AfterEach(func() {
+			EnsureGockRequestsHaveBeenMatched()
+			github.UseEndpoints(github.DefaultEndpoints)
+		})
+
+		It("should block newly created pull request when no tests are included", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				WithoutComments().
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+	})
+
+	Context("Check run reporting", func() {
+
+		BeforeEach(func() {
+			defer gock.OffAll()
+			handler = &testkeeper.GitHubTestEventsHandler{Client: NewDefaultGitHubClient(), BotName: botName}
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+		It("should report missing tests as a check run with annotated production files and bypass action", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithConfigFile(
+					ConfigYml(Containing(
+						Param("status_report", "checks")))).
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				WithoutComments().
+				Expecting(
+					CheckRun(To(
+						HaveName(botName+"/"+testkeeper.ProwPluginName),
+						HaveConclusion(github.ConclusionFailure),
+						HaveOutputTitle(testkeeper.NoTestsMessage),
+						HaveAnnotatedFiles("Randomfile"),
+						HaveActions(testkeeper.OkWithoutTestsActionID))),
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /check-runs call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should approve pull request without tests when admin requests the bypass action from the check run", func() {
+			// given
+			approvedBy := fmt.Sprintf(testkeeper.ApprovedByMessage, "bartoszmajsak")
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithUsers(Admin("bartoszmajsak")).
+				WithoutReviews().
+				WithoutConfigFiles().
+				Expecting(
+					Comment(To(HaveBodyThatContains(fmt.Sprintf(testkeeper.ApprovedByActionMsg, "bartoszmajsak")))),
+					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+				Create()
+
+			event := prMock.CreateCheckRunEvent(SentBy("bartoszmajsak"),
+				botName+"/"+testkeeper.ProwPluginName, testkeeper.OkWithoutTestsActionID)
+
+			// when
+			err := handler.HandleCheckRunEvent(log, event)
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should keep the approval given using the check run action when the pull request is verified again", func() {
+			// given
+			approvedBy := fmt.Sprintf(testkeeper.ApprovedByMessage, "bartoszmajsak")
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithUsers(Admin("bartoszmajsak")).
+				WithComments(`[{"user":{"login":"` + botName + `"}, "body":"` +
+					fmt.Sprintf(testkeeper.ApprovedByActionMsg, "bartoszmajsak") + `"}]`).
+				WithoutReviews().
+				WithoutConfigFiles().
+				Expecting(
+					Status(ToBe(github.StatusSuccess, approvedBy, testkeeper.ApprovedByDetailsPageName))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("synchronize"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should block pull request without tests when the approval given using the check run action has been revoked", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithUsers(Admin("bartoszmajsak"), Admin("matousjobanek")).
+				WithComments(`[{"user":{"login":"`+botName+`"}, "body":"`+
+					fmt.Sprintf(testkeeper.ApprovedByActionMsg, "bartoszmajsak")+`"},`+
+					`{"user":{"login":"matousjobanek"}, "body":"`+testkeeper.RequireTestsComment+`"}]`).
+				WithoutReviews().
+				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("synchronize"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should ignore the approval of the check run action when it's not commented by the bot", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(LoadedFrom("test_fixtures/github_calls/prs/without_tests/changes.json")).
+				WithComments(`[{"user":{"login":"bartoszmajsak-test"}, "body":"`+
+					fmt.Sprintf(testkeeper.ApprovedByActionMsg, "bartoszmajsak")+`"}]`).
+				WithoutConfigFiles().
+				WithoutMessageFiles("test-keeper_without_tests_message.md").
+				Expecting(
+					Status(ToBe(github.StatusFailure, testkeeper.NoTestsMessage, testkeeper.NoTestsDetailsPageName)),
+					Comment(ContainingStatusMessage(testkeeper.WithoutTestsMsg))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("synchronize"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should ignore bypass action requested from the check run by user with insufficient permissions", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithUsers(ExternalUser("bartoszmajsak-test")).
+				WithoutReviews().
+				Expecting(NoStatus()).
+				Create()
+
+			event := prMock.CreateCheckRunEvent(SentBy("bartoszmajsak-test"),
+				botName+"/"+testkeeper.ProwPluginName, testkeeper.OkWithoutTestsActionID)
+
+			// when
+			err := handler.HandleCheckRunEvent(log, event)
+
+			// then - implicit verification that no /statuses call occurred
+			Ω(err).ShouldNot(HaveOccurred())
+		})
 	})
 
 	Context("Trigger test-keeper plugin by triggering comment on pull request", func() {
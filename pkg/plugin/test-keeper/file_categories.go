@@ -13,15 +13,25 @@ type FileCategoryCounter struct {
 }
 
 // FileCategories holds information about the total files coming in the changeset, skipped files (those which are excluded from test verification)
-// and tests
+// and tests. Production lists names of the changed files which are neither tests nor skipped - it's complete only when no test has been found
+// or when all the files have been counted. TestAdditions and ProductionAdditions hold the number of lines added (or modified) in tests
+// and production files respectively and TestFiles lists names of the tests - all of them are counted only when all the files are counted.
+// Test files are counted only when their patch brings real test code and TestCases holds the number of test cases declared in them.
+// TestsWithoutCodeChanges holds the number of test files which are changed, but don't bring any real test code (e.g. reformatted
+// tests or edited comments) - they don't need any other tests, as no code has been changed.
 type FileCategories struct {
-	Total, Skipped, Tests int
-	Files                 *[]scm.ChangedFile
+	Total, Skipped, Tests, TestCases   int
+	TestsWithoutCodeChanges            int
+	TestAdditions, ProductionAdditions int
+	Files                              *[]scm.ChangedFile
+	Production                         []string
+	TestFiles                          []string
 }
 
-// OnlySkippedFiles indicates if changeset contains only files which are excluded from test verification
+// OnlySkippedFiles indicates if changeset contains only files which are excluded from test verification. Tests which
+// don't bring any real test code are not subject of the verification either
 func (f *FileCategories) OnlySkippedFiles() bool {
-	return f.Total > 0 && f.Skipped == f.Total
+	return f.Total > 0 && f.Skipped+f.TestsWithoutCodeChanges == f.Total
 }
 
 // TestsExist answers if any test files are found
@@ -35,21 +45,34 @@ func NewFileTypes(files []scm.ChangedFile) FileCategories {
 }
 
 // Count counts files in the changeset which are tests (included files) and should not be considered for
-// verification (excluded). When first test is found it stops, as this is enough to unblock PR
+// verification (excluded). It also sums up the lines added in tests and in production files
 func (t *FileCategoryCounter) Count(files []scm.ChangedFile) (FileCategories, error) {
 	types := NewFileTypes(files)
 	for _, file := range files {
 		if file.Name == "" {
 			return types, errors.New("can't have empty file name")
 		}
-		excluded := t.Matcher.MatchesExclusion(file.Name)
+		matchedName := file.Name
+		if file.Status == "renamed" && file.Additions == 0 && file.PreviousFilename != "" {
+			// a file which has been only moved keeps the category of its previous path, so e.g. a test moved out
+			// of the test directory doesn't turn into a production file
+			matchedName = file.PreviousFilename
+		}
+		excluded := t.Matcher.MatchesExclusion(matchedName)
 		if !excluded {
-			if t.Matcher.MatchesInclusion(file.Name) {
-				onlyDeletions := file.Additions == 0 && file.Deletions > 0
-				if !(file.Status == "removed" || onlyDeletions) {
+			if t.Matcher.MatchesInclusion(matchedName) {
+				changesCode, testCases := t.Matcher.AnalyzeTestChange(file)
+				if file.Status != "removed" && changesCode {
 					types.Tests++
-					return types, nil // As we found the first test and we don't care about the amount of them, we can return
+					types.TestCases += testCases
+					types.TestAdditions += file.Additions
+					types.TestFiles = append(types.TestFiles, file.Name)
+				} else if file.Status != "removed" && !t.Matcher.ChangesTestCode(file) {
+					types.TestsWithoutCodeChanges++
 				}
+			} else if file.Status != "removed" {
+				types.Production = append(types.Production, file.Name)
+				types.ProductionAdditions += file.Additions
 			}
 		} else {
 			types.Skipped++
@@ -58,24 +81,61 @@ func (t *FileCategoryCounter) Count(files []scm.ChangedFile) (FileCategories, er
 	return types, nil
 }
 
-// LoadMatcher loads list of FilePattern either from the provided configuration or from languages retrieved from the given function
+// LoadMatcher loads list of FilePattern from the provided configuration combined with the predefined patterns of all the languages
 func LoadMatcher(configuration *PluginConfiguration) (TestMatcher, error) {
-	matcher, err := LoadDefaultMatcher()
+	return LoadMatcherFor(configuration, nil, nil)
+}
+
+// LoadMatcherFor loads list of FilePattern from the provided configuration combined with the predefined patterns of languages
+// used in the repository (as reported by GitHub) or, when none of them is known, of languages the changed files are written in.
+// If the languages cannot be detected at all, predefined patterns of all the languages are used.
+// The loaded matchers are cached, so the patterns of the same configuration are compiled only once
+func LoadMatcherFor(configuration *PluginConfiguration, repositoryLanguages []string, changedFiles []scm.ChangedFile) (TestMatcher, error) {
+	defaults, err := LoadDefaultPatterns()
+	if err != nil {
+		return TestMatcher{}, err
+	}
+	languages := DetectLanguages(mergeLanguages(defaults.Languages, configuration.Languages), repositoryLanguages, changedFiles)
+
+	key, err := matcherKey(configuration, languages, defaults.Exclusions)
 	if err != nil {
-		return matcher, err
+		return TestMatcher{}, err
+	}
+	if matcher, found := cachedMatchers.get(key); found {
+		return matcher, nil
+	}
+
+	matcher, err := loadMatcher(configuration, languages, defaults.Exclusions)
+	if err != nil {
+		return TestMatcher{}, err
+	}
+	cachedMatchers.put(key, matcher)
+	return matcher, nil
+}
+
+func loadMatcher(configuration *PluginConfiguration, languages []LanguagePatterns, defaultExclusions []string) (TestMatcher, error) {
+	matcher, err := newMatcher(languages, defaultExclusions)
+	if err != nil {
+		return TestMatcher{}, err
 	}
 
 	if len(configuration.Inclusions) != 0 {
-		exclusions := ParseFilePatterns(configuration.Inclusions)
+		inclusions, err := ParseFilePatterns(configuration.Inclusions)
+		if err != nil {
+			return TestMatcher{}, err
+		}
 		if configuration.Combine {
-			matcher.Inclusion = append(matcher.Inclusion, exclusions...)
+			matcher.Inclusion = append(matcher.Inclusion, inclusions...)
 		} else {
-			matcher.Inclusion = exclusions
+			matcher.Inclusion = inclusions
 		}
 	}
 
 	if len(configuration.Exclusions) != 0 {
-		exclusions := ParseFilePatterns(configuration.Exclusions)
+		exclusions, err := ParseFilePatterns(configuration.Exclusions)
+		if err != nil {
+			return TestMatcher{}, err
+		}
 		if configuration.Combine {
 			matcher.Exclusion = append(matcher.Exclusion, exclusions...)
 		} else {
@@ -83,5 +143,18 @@ func LoadMatcher(configuration *PluginConfiguration) (TestMatcher, error) {
 		}
 	}
 
+	if len(configuration.Pairing) != 0 {
+		pairing, err := ParseTestPairing(configuration.Pairing)
+		if err != nil {
+			return TestMatcher{}, err
+		}
+		if configuration.Combine {
+			// the rules defined in the repository take precedence as the first applicable one is used
+			matcher.Pairing = append(pairing, matcher.Pairing...)
+		} else {
+			matcher.Pairing = pairing
+		}
+	}
+
 	return matcher, nil
 }
@@ -297,6 +297,97 @@ var _ = Describe("Test fileCategoryCounter features", func() {
 
 	})
 
+	Context("Counting all files within file changeset", func() {
+
+		It("should not require any other tests when tests are only reformatted", func() {
+			// given
+			changedFiles := []scm.ChangedFile{
+				{Name: "pkg/bar/bar_test.go", Status: "modified", Additions: 1, Deletions: 1,
+					Patch: "@@ -12,1 +12,1 @@\n-  assert.True(t,  bar())\n+\tassert.True(t, bar())"},
+				{Name: "src/test/java/org/my/ServiceTest.java", Status: "modified", Additions: 1,
+					Patch: "@@ -5,0 +6,1 @@\n+    // verifies the service against the in-memory repository"},
+				{Name: "README.adoc", Status: "modified", Additions: 3},
+			}
+
+			fileCategoryCounter := testkeeper.FileCategoryCounter{Matcher: defaultMatcher}
+
+			// when
+			fileCategories, err := fileCategoryCounter.Count(changedFiles)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(fileCategories.TestsExist()).To(BeFalse())
+			Expect(fileCategories.TestsWithoutCodeChanges).To(Equal(2))
+			Expect(fileCategories.OnlySkippedFiles()).To(BeTrue())
+		})
+
+		It("should require tests when production files come with reformatted tests only", func() {
+			// given
+			changedFiles := []scm.ChangedFile{
+				{Name: "src/main/java/org/my/Service.java", Status: "modified", Additions: 12, Deletions: 1},
+				{Name: "src/test/java/org/my/ServiceTest.java", Status: "modified", Additions: 1,
+					Patch: "@@ -5,0 +6,1 @@\n+    // verifies the service against the in-memory repository"},
+			}
+
+			fileCategoryCounter := testkeeper.FileCategoryCounter{Matcher: defaultMatcher}
+
+			// when
+			fileCategories, err := fileCategoryCounter.Count(changedFiles)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(fileCategories.TestsExist()).To(BeFalse())
+			Expect(fileCategories.OnlySkippedFiles()).To(BeFalse())
+			Expect(fileCategories.Production).To(ConsistOf("src/main/java/org/my/Service.java"))
+		})
+
+		It("should count all tests and lines added in tests and production files", func() {
+			// given
+			changedFiles := []scm.ChangedFile{
+				{Name: "src/main/java/org/my/Service.java", Status: "modified", Additions: 120, Deletions: 10},
+				{Name: "src/main/java/org/my/Repository.java", Status: "added", Additions: 80},
+				{Name: "src/main/java/org/my/Legacy.java", Status: "removed", Deletions: 300},
+				{Name: "src/test/java/org/my/ServiceTest.java", Status: "modified", Additions: 30, Deletions: 5},
+				{Name: "src/test/java/org/my/RepositoryTest.java", Status: "added", Additions: 20},
+				{Name: "src/test/java/org/my/LegacyTest.java", Status: "removed", Deletions: 100},
+				{Name: "README.adoc", Status: "modified", Additions: 15}}
+
+			fileCategoryCounter := testkeeper.FileCategoryCounter{Matcher: defaultMatcher}
+
+			// when
+			fileCategories, err := fileCategoryCounter.Count(changedFiles)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(fileCategories.Tests).To(Equal(2))
+			Expect(fileCategories.TestAdditions).To(Equal(50))
+			Expect(fileCategories.Production).To(ConsistOf("src/main/java/org/my/Service.java", "src/main/java/org/my/Repository.java"))
+			Expect(fileCategories.ProductionAdditions).To(Equal(200))
+			Expect(fileCategories.Skipped).To(Equal(1))
+		})
+
+		It("should categorize files which have been only renamed by their previous path", func() {
+			// given
+			changedFiles := []scm.ChangedFile{
+				{Name: "src/main/java/org/my/ServiceFixture.java", PreviousFilename: "src/test/java/org/my/ServiceFixtureTest.java",
+					Status: "renamed"},
+				{Name: "docs/service.adoc", PreviousFilename: "README.adoc", Status: "renamed"},
+				{Name: "src/main/java/org/my/Repository.java", PreviousFilename: "src/test/java/org/my/RepositoryTest.java",
+					Status: "renamed", Additions: 12, Deletions: 3}}
+
+			fileCategoryCounter := testkeeper.FileCategoryCounter{Matcher: defaultMatcher}
+
+			// when
+			fileCategories, err := fileCategoryCounter.Count(changedFiles)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(fileCategories.Tests).To(Equal(0))
+			Expect(fileCategories.Production).To(ConsistOf("src/main/java/org/my/Repository.java"))
+			Expect(fileCategories.Skipped).To(Equal(1))
+		})
+	})
+
 })
 
 func changedFilesSet(names ...string) []scm.ChangedFile {
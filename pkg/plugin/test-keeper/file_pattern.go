@@ -1,6 +1,8 @@
 package testkeeper
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
 	"strings"
 )
@@ -17,16 +19,21 @@ const (
 	directorySeparator     = "/"
 )
 
-// FilePattern contains regexp that matches a file
+// FilePattern contains regexp that matches a file. When it's created by ParseFilePattern(s) the regexp is already compiled
 type FilePattern struct {
-	Regexp string
+	Regexp   string
+	compiled *regexp.Regexp
 }
 
-// Matches checks if the given string (representing path to a file) contains a substring that matches Regexp stored in this matcher
+// Matches checks if the given string (representing path to a file) contains a substring that matches Regexp stored in this matcher.
+// The Regexp of the pattern which hasn't been parsed by ParseFilePattern(s) is compiled on every call
 func (matcher *FilePattern) Matches(filename string) bool {
-	exp, err := regexp.Compile(matcher.Regexp)
-	if err != nil {
-		return false
+	exp := matcher.compiled
+	if exp == nil {
+		var err error
+		if exp, err = regexp.Compile(matcher.Regexp); err != nil {
+			return false
+		}
 	}
 	return exp.MatchString(filename)
 }
@@ -46,15 +53,39 @@ func (f *FilePatterns) Matches(filename string) bool {
 	return false
 }
 
-// ParseFilePatterns takes the given patterns and parses to an array of FilePattern instances
-func ParseFilePatterns(filePatterns []string) FilePatterns {
+// ParseFilePatterns takes the given patterns and parses them to an array of FilePattern instances with compiled regexps.
+// It fails on the first pattern which is empty or which doesn't result in a valid regular expression
+func ParseFilePatterns(filePatterns []string) (FilePatterns, error) {
 	patterns := make([]FilePattern, 0, len(filePatterns))
 	for _, pattern := range filePatterns {
-		patterns = append(patterns, FilePattern{
-			Regexp: parseFilePattern(strings.TrimSpace(pattern)),
-		})
+		filePattern, err := ParseFilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, filePattern)
+	}
+	return patterns, nil
+}
+
+// ParseFilePattern takes the given pattern and parses it to a FilePattern instance with compiled regexp. It fails when
+// the pattern is empty or when it doesn't result in a valid regular expression
+func ParseFilePattern(pattern string) (FilePattern, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return FilePattern{}, errors.New("pattern must not be empty")
+	}
+	expr := parseFilePattern(pattern)
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		return FilePattern{}, fmt.Errorf("pattern `%s` is not a valid regular expression: %s", pattern, err)
 	}
-	return patterns
+	return FilePattern{Regexp: expr, compiled: compiled}, nil
+}
+
+// ValidateFilePattern checks that the given pattern is not empty and that it results in a valid regular expression
+func ValidateFilePattern(pattern string) error {
+	_, err := ParseFilePattern(pattern)
+	return err
 }
 
 func parseFilePattern(pattern string) string {
@@ -2,6 +2,7 @@ package testkeeper_test
 
 import (
 	"fmt"
+	"testing"
 
 	testkeeper "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
 	. "github.com/onsi/ginkgo"
@@ -18,17 +19,40 @@ var _ = Describe("File pattern features", func() {
 			regexpDef := []string{"regex{{my-regexp}}"}
 
 			// when
-			parsed := testkeeper.ParseFilePatterns(regexpDef)
+			parsed, err := testkeeper.ParseFilePatterns(regexpDef)
 
 			// then
-			Expect(parsed).To(ConsistOf(testkeeper.FilePattern{Regexp: "my-regexp"}))
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(parsed).To(HaveLen(1))
+			Expect(parsed[0].Regexp).To(Equal("my-regexp"))
+		})
+
+		It("should fail when the pattern is not a valid regexp", func() {
+			// given
+			regexpDef := []string{"**/*Test.java", "regex{{*IT.java}}"}
+
+			// when
+			_, err := testkeeper.ParseFilePatterns(regexpDef)
+
+			// then
+			Ω(err).Should(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("pattern `regex{{*IT.java}}` is not a valid regular expression"))
+		})
+
+		It("should fail when the pattern is empty", func() {
+			// when
+			_, err := testkeeper.ParseFilePatterns([]string{" "})
+
+			// then
+			Ω(err).Should(HaveOccurred())
 		})
 	})
 
 	Context("File pattern matching", func() {
 
 		var assertThat = func(file, pattern string) {
-			parsed := testkeeper.ParseFilePatterns([]string{pattern})
+			parsed, err := testkeeper.ParseFilePatterns([]string{pattern})
+			Ω(err).ShouldNot(HaveOccurred())
 			Expect(parsed.Matches(file)).To(BeTrue())
 		}
 
@@ -64,3 +88,63 @@ func file(fileName string) filePatternProvider {
 func (f filePatternProvider) matches(simplifiedRegExp string) table.TableEntry {
 	return table.Entry(fmt.Sprintf(patternAssertionMsg, f(), simplifiedRegExp), f(), simplifiedRegExp)
 }
+
+// benchmarkFileNames creates names of files of a large PR touching production code, tests and build files
+func benchmarkFileNames() []string {
+	fileNames := make([]string, 0, 3000)
+	for i := 0; i < 1000; i++ {
+		fileNames = append(fileNames,
+			fmt.Sprintf("src/main/java/org/acme/module%d/Service%d.java", i%10, i),
+			fmt.Sprintf("src/test/java/org/acme/module%d/Service%dTest.java", i%10, i),
+			fmt.Sprintf("module%d/pom.xml", i))
+	}
+	return fileNames
+}
+
+func benchmarkMatching(b *testing.B, patterns []testkeeper.FilePattern) {
+	fileNames := benchmarkFileNames()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, fileName := range fileNames {
+			testkeeper.Matches(patterns, fileName)
+		}
+	}
+}
+
+func BenchmarkMatchingPrecompiledPatterns(b *testing.B) {
+	matcher, err := testkeeper.LoadDefaultMatcher()
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkMatching(b, append(matcher.Inclusion, matcher.Exclusion...))
+}
+
+func BenchmarkMatchingPatternsCompiledOnEachMatch(b *testing.B) {
+	matcher, err := testkeeper.LoadDefaultMatcher()
+	if err != nil {
+		b.Fatal(err)
+	}
+	// patterns which are not created by ParseFilePatterns are compiled on every match
+	var patterns []testkeeper.FilePattern
+	for _, pattern := range append(matcher.Inclusion, matcher.Exclusion...) {
+		patterns = append(patterns, testkeeper.FilePattern{Regexp: pattern.Regexp})
+	}
+	benchmarkMatching(b, patterns)
+}
+
+func BenchmarkLoadingCachedMatcher(b *testing.B) {
+	configuration := &testkeeper.PluginConfiguration{Inclusions: []string{"**/*Spec.java"}, Combine: true}
+	for i := 0; i < b.N; i++ {
+		if _, err := testkeeper.LoadMatcherFor(configuration, []string{"Java"}, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadingDefaultMatcher(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := testkeeper.LoadDefaultMatcher(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
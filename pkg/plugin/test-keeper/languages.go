@@ -0,0 +1,180 @@
+package testkeeper
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/assets"
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	"github.com/pkg/errors"
+)
+
+// LanguagePatterns holds test patterns, pairing rules, regular expressions matching declarations of test cases (TestCases)
+// and beginnings of comment lines (Comments) of a programming language. The language is considered to be used in the changeset
+// when any of the changed files has one of its Extensions
+type LanguagePatterns struct {
+	Name       string        `yaml:"name"`
+	Extensions []string      `yaml:"extensions,omitempty"`
+	Inclusions []string      `yaml:"test_patterns,omitempty"`
+	Pairing    []PairingRule `yaml:"test_pairing,omitempty"`
+	TestCases  []string      `yaml:"test_cases,omitempty"`
+	Comments   []string      `yaml:"comments,omitempty"`
+	Combine    *bool         `yaml:"combine_defaults,omitempty"`
+}
+
+// DefaultPatterns holds predefined patterns split by languages together with exclusions common for all of them.
+// It's unmarshaled from test-keeper.yaml asset
+type DefaultPatterns struct {
+	Languages  []LanguagePatterns `yaml:"languages"`
+	Exclusions []string           `yaml:"skip_validation_for"`
+}
+
+var (
+	defaultPatterns     DefaultPatterns
+	defaultPatternsErr  error
+	loadDefaultPatterns sync.Once
+)
+
+// LoadDefaultPatterns loads predefined patterns from test-keeper.yaml asset. The asset is parsed only once, as it's embedded
+// in the binary, so the returned patterns are shared and must not be modified
+func LoadDefaultPatterns() (DefaultPatterns, error) {
+	loadDefaultPatterns.Do(func() {
+		err := config.Load(&defaultPatterns, &assets.LocalLoadableConfig{ConfigFileName: "test-keeper.yaml"})
+		if err != nil {
+			defaultPatternsErr = errors.Errorf("an error occurred while loading the default test-keeper.yaml: %s", err)
+		}
+	})
+	return defaultPatterns, defaultPatternsErr
+}
+
+// combinesDefaults tells if the patterns defined for the language in the repository should be combined with the predefined
+// ones (true by default)
+func (l *LanguagePatterns) combinesDefaults() bool {
+	return l.Combine == nil || *l.Combine
+}
+
+func (l *LanguagePatterns) usedIn(files []scm.ChangedFile) bool {
+	for _, file := range files {
+		extension := strings.TrimPrefix(path.Ext(file.Name), ".")
+		for _, languageExtension := range l.Extensions {
+			if extension != "" && strings.EqualFold(strings.TrimPrefix(languageExtension, "."), extension) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// DetectLanguages returns languages used in the repository. When none of them is known, it returns languages any of
+// the changed files is written in. If there is no such language either, all of them are returned, as there is no better
+// guess which patterns should be used
+func DetectLanguages(languages []LanguagePatterns, repositoryLanguages []string, files []scm.ChangedFile) []LanguagePatterns {
+	var detected []LanguagePatterns
+	for _, language := range languages {
+		if indexOfName(repositoryLanguages, language.Name) >= 0 {
+			detected = append(detected, language)
+		}
+	}
+	if len(detected) != 0 {
+		return detected
+	}
+	for _, language := range languages {
+		if language.usedIn(files) {
+			detected = append(detected, language)
+		}
+	}
+	if len(detected) == 0 {
+		return languages
+	}
+	return detected
+}
+
+// mergeLanguages applies patterns defined for languages in the repository on top of the predefined ones. Patterns of
+// a known language are combined with the predefined ones unless its combine_defaults is set to false, unknown languages
+// are added as they are
+func mergeLanguages(defaults, configured []LanguagePatterns) []LanguagePatterns {
+	merged := make([]LanguagePatterns, len(defaults))
+	copy(merged, defaults)
+
+	for _, language := range configured {
+		index := indexOfLanguage(merged, language.Name)
+		if index < 0 {
+			merged = append(merged, language)
+			continue
+		}
+		predefined := merged[index]
+		predefined.Extensions = append(append([]string{}, predefined.Extensions...), language.Extensions...)
+		if len(language.Inclusions) != 0 {
+			if language.combinesDefaults() {
+				predefined.Inclusions = append(append([]string{}, predefined.Inclusions...), language.Inclusions...)
+			} else {
+				predefined.Inclusions = language.Inclusions
+			}
+		}
+		if len(language.TestCases) != 0 {
+			if language.combinesDefaults() {
+				predefined.TestCases = append(append([]string{}, predefined.TestCases...), language.TestCases...)
+			} else {
+				predefined.TestCases = language.TestCases
+			}
+		}
+		if len(language.Comments) != 0 {
+			if language.combinesDefaults() {
+				predefined.Comments = append(append([]string{}, predefined.Comments...), language.Comments...)
+			} else {
+				predefined.Comments = language.Comments
+			}
+		}
+		if len(language.Pairing) != 0 {
+			if language.combinesDefaults() {
+				// the rules defined in the repository take precedence as the first applicable one is used
+				predefined.Pairing = append(append([]PairingRule{}, language.Pairing...), predefined.Pairing...)
+			} else {
+				predefined.Pairing = language.Pairing
+			}
+		}
+		merged[index] = predefined
+	}
+
+	return merged
+}
+
+func indexOfLanguage(languages []LanguagePatterns, name string) int {
+	for i, language := range languages {
+		if strings.EqualFold(language.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func indexOfName(names []string, name string) int {
+	for i, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func validateLanguages(field string, languages []LanguagePatterns) []config.FieldError {
+	var fieldErrors []config.FieldError
+	for _, language := range languages {
+		if strings.TrimSpace(language.Name) == "" {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: field, Message: "language has to have a name"})
+		}
+		fieldErrors = append(fieldErrors, validateFilePatterns(field, language.Inclusions)...)
+		fieldErrors = append(fieldErrors, validatePairingRules(field, language.Pairing)...)
+		for _, testCase := range language.TestCases {
+			if _, err := regexp.Compile(testCase); err != nil {
+				fieldErrors = append(fieldErrors, config.FieldError{Field: field, Value: testCase,
+					Message: fmt.Sprintf("test case declaration of %s is not a valid regular expression: %s", language.Name, err)})
+			}
+		}
+	}
+	return fieldErrors
+}
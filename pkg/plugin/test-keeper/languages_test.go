@@ -0,0 +1,115 @@
+package testkeeper_test
+
+import (
+	testkeeper "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
+	"github.com/arquillian/ike-prow-plugins/pkg/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Language detection features", func() {
+
+	var defaults testkeeper.DefaultPatterns
+
+	BeforeEach(func() {
+		var err error
+		defaults, err = testkeeper.LoadDefaultPatterns()
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	names := func(languages []testkeeper.LanguagePatterns) []string {
+		var names []string
+		for _, language := range languages {
+			names = append(names, language.Name)
+		}
+		return names
+	}
+
+	Context("Detecting languages", func() {
+
+		It("should use known languages of the repository", func() {
+			// when
+			languages := testkeeper.DetectLanguages(defaults.Languages, []string{"Shell", "java"},
+				changedFilesSet("scripts/test_release.py"))
+
+			// then
+			Expect(names(languages)).To(ConsistOf("Java"))
+		})
+
+		It("should use extensions of changed files when none of the repository languages is known", func() {
+			// when
+			languages := testkeeper.DetectLanguages(defaults.Languages, []string{"Shell"},
+				changedFilesSet("pkg/server.go", "ui/src/app.component.ts", "README.adoc"))
+
+			// then
+			Expect(names(languages)).To(ConsistOf("Go", "TypeScript"))
+		})
+
+		It("should use all the languages when none can be detected", func() {
+			// when
+			languages := testkeeper.DetectLanguages(defaults.Languages, nil, changedFilesSet("Makefile"))
+
+			// then
+			Expect(languages).To(Equal(defaults.Languages))
+		})
+	})
+
+	Context("Loading matcher for detected languages", func() {
+
+		It("should not consider tests of other languages than those used in the repository", func() {
+			// given
+			changedFiles := changedFilesSet(
+				"src/main/java/org/acme/Release.java",
+				"scripts/test_release.py")
+
+			matcher, loaderErr := testkeeper.LoadMatcherFor(&testkeeper.PluginConfiguration{}, []string{"Java"}, changedFiles)
+			fileCategoryCounter := testkeeper.FileCategoryCounter{Matcher: matcher}
+
+			// when
+			fileCategories, err := fileCategoryCounter.Count(changedFiles)
+
+			// then
+			Ω(loaderErr).ShouldNot(HaveOccurred())
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(fileCategories.TestsExist()).To(BeFalse())
+		})
+
+		It("should replace predefined patterns of the language when it's not combined with defaults", func() {
+			// given
+			configuration := &testkeeper.PluginConfiguration{
+				Languages: []testkeeper.LanguagePatterns{{
+					Name:       "Java",
+					Inclusions: []string{"*Spec.java"},
+					Combine:    utils.Bool(false),
+				}},
+			}
+
+			// when
+			matcher, err := testkeeper.LoadMatcherFor(configuration, []string{"Java"}, nil)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(matcher.MatchesInclusion("src/test/java/org/acme/ReleaseSpec.java")).To(BeTrue())
+			Expect(matcher.MatchesInclusion("src/test/java/org/acme/ReleaseTest.java")).To(BeFalse())
+		})
+
+		It("should add patterns of the language which is not predefined", func() {
+			// given
+			configuration := &testkeeper.PluginConfiguration{
+				Languages: []testkeeper.LanguagePatterns{{
+					Name:       "Kotlin",
+					Extensions: []string{"kt"},
+					Inclusions: []string{"*Test.kt"},
+				}},
+			}
+
+			// when
+			matcher, err := testkeeper.LoadMatcherFor(configuration, []string{"Kotlin"}, nil)
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(matcher.MatchesInclusion("src/test/kotlin/ReleaseTest.kt")).To(BeTrue())
+			Expect(matcher.MatchesInclusion("src/test/java/ReleaseTest.java")).To(BeFalse())
+		})
+	})
+})
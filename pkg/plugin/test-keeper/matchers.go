@@ -1,16 +1,21 @@
 package testkeeper
 
 import (
-	"github.com/arquillian/ike-prow-plugins/pkg/assets"
-	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
 	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
 )
 
 // TestMatcher holds definitions of patterns considered as test filenames (inclusions) and those which shouldn't be
-// verified (exclusions)
+// verified (exclusions) together with the rules pairing production files with their tests and declarations of test cases
 type TestMatcher struct {
 	Inclusion []FilePattern
 	Exclusion []FilePattern
+	Pairing   []TestPairing
+	TestCases []TestCaseDeclarations
 }
 
 // MatchesInclusion checks if file name matches defined inclusion patterns
@@ -23,6 +28,21 @@ func (matcher *TestMatcher) MatchesExclusion(filename string) bool {
 	return Matches(matcher.Exclusion, filename)
 }
 
+// PairsWithTest checks if any of the given test files is the counterpart of the production file. The second value
+// tells if there is any pairing rule applicable to the production file
+func (matcher *TestMatcher) PairsWithTest(productionFile string, testFiles []string) (paired, applicable bool) {
+	pairing, applicable := FindPairing(matcher.Pairing, productionFile)
+	if !applicable {
+		return false, false
+	}
+	for _, testFile := range testFiles {
+		if pairing.Pairs(productionFile, testFile) {
+			return true, true
+		}
+	}
+	return false, true
+}
+
 // Matches iterates over a slice of FilePattern and verifies if passed name matches any of the defined patterns
 func Matches(matchers []FilePattern, filename string) bool {
 
@@ -35,18 +55,81 @@ func Matches(matchers []FilePattern, filename string) bool {
 	return false
 }
 
-// LoadDefaultMatcher loads default matcher containing default include and exclude patterns
+// LoadDefaultMatcher loads default matcher containing default include and exclude patterns of all the languages
 func LoadDefaultMatcher() (TestMatcher, error) {
-	matcher := TestMatcher{}
-	defaultConfig := PluginConfiguration{}
-
-	err := config.Load(&defaultConfig, &assets.LocalLoadableConfig{ConfigFileName: "test-keeper.yaml"})
-
+	defaults, err := LoadDefaultPatterns()
 	if err != nil {
-		return matcher, errors.Errorf("an error occurred while loading the default test-keeper.yaml: %s", err)
+		return TestMatcher{}, err
 	}
-	matcher.Inclusion = ParseFilePatterns(defaultConfig.Inclusions)
-	matcher.Exclusion = ParseFilePatterns(defaultConfig.Exclusions)
+	return newMatcher(defaults.Languages, defaults.Exclusions)
+}
 
+func newMatcher(languages []LanguagePatterns, exclusions []string) (TestMatcher, error) {
+	exclusion, err := ParseFilePatterns(exclusions)
+	if err != nil {
+		return TestMatcher{}, err
+	}
+	matcher := TestMatcher{Exclusion: exclusion}
+	for _, language := range languages {
+		inclusion, err := ParseFilePatterns(language.Inclusions)
+		if err != nil {
+			return TestMatcher{}, errors.Wrapf(err, "invalid test patterns of %s", language.Name)
+		}
+		pairing, err := ParseTestPairing(language.Pairing)
+		if err != nil {
+			return TestMatcher{}, errors.Wrapf(err, "invalid test pairing of %s", language.Name)
+		}
+		matcher.Inclusion = append(matcher.Inclusion, inclusion...)
+		matcher.Pairing = append(matcher.Pairing, pairing...)
+		if len(language.TestCases) != 0 || len(language.Comments) != 0 {
+			matcher.TestCases = append(matcher.TestCases,
+				ParseTestCaseDeclarations(language.Extensions, language.TestCases, language.Comments))
+		}
+	}
 	return matcher, nil
 }
+
+// maxCachedMatchers limits the number of matchers kept in the cache. When it's reached, the cache is cleared
+const maxCachedMatchers = 256
+
+// matcherCache holds matchers with compiled patterns by the hash of the content of the configuration they were loaded from.
+// The cached matchers are shared, so they must not be modified
+type matcherCache struct {
+	sync.RWMutex
+	matchers map[string]TestMatcher
+}
+
+var cachedMatchers = &matcherCache{matchers: make(map[string]TestMatcher)}
+
+func (c *matcherCache) get(key string) (TestMatcher, bool) {
+	c.RLock()
+	defer c.RUnlock()
+	matcher, found := c.matchers[key]
+	return matcher, found
+}
+
+func (c *matcherCache) put(key string, matcher TestMatcher) {
+	c.Lock()
+	defer c.Unlock()
+	if len(c.matchers) >= maxCachedMatchers {
+		c.matchers = make(map[string]TestMatcher)
+	}
+	c.matchers[key] = matcher
+}
+
+// matcherKey computes the hash of everything the matcher is loaded from - patterns of the detected languages, predefined
+// exclusions and patterns defined in the configuration
+func matcherKey(configuration *PluginConfiguration, languages []LanguagePatterns, exclusions []string) (string, error) {
+	content, err := yaml.Marshal(struct {
+		Languages         []LanguagePatterns
+		DefaultExclusions []string
+		Inclusions        []string
+		Exclusions        []string
+		Combine           bool
+		Pairing           []PairingRule
+	}{languages, exclusions, configuration.Inclusions, configuration.Exclusions, configuration.Combine, configuration.Pairing})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
@@ -79,6 +79,14 @@ var (
 	}()
 )
 
+var regexps = func(patterns []FilePattern) []string {
+	var regexps []string
+	for _, pattern := range patterns {
+		regexps = append(regexps, pattern.Regexp)
+	}
+	return regexps
+}
+
 var expectThatFile = func(matchers []FilePattern, file string, shouldMatch bool) {
 	Expect(Matches(matchers, file)).To(Equal(shouldMatch))
 }
@@ -98,13 +106,39 @@ var _ = Describe("Test Matcher features", func() {
 
 			// then
 			Ω(err).ShouldNot(HaveOccurred())
-			Expect(matchers).To(Equal(DefaultMatchers))
+			Expect(regexps(matchers.Inclusion)).To(Equal(regexps(DefaultMatchers.Inclusion)))
+			Expect(regexps(matchers.Exclusion)).To(Equal(regexps(DefaultMatchers.Exclusion)))
+		})
+
+		It("should reuse the matcher loaded for the same configuration", func() {
+			// given
+			configuration := &PluginConfiguration{Inclusions: []string{"**/*Spec.groovy"}, Combine: true}
+			matcher, err := LoadMatcher(configuration)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			// when
+			sameMatcher, err := LoadMatcher(&PluginConfiguration{Inclusions: []string{"**/*Spec.groovy"}, Combine: true})
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(&sameMatcher.Inclusion[0]).To(BeIdenticalTo(&matcher.Inclusion[0]))
+		})
+
+		It("should fail loading matcher when the configured pattern is not a valid regexp", func() {
+			// given
+			configuration := &PluginConfiguration{Exclusions: []string{`regex{{*.adoc}}`}}
+
+			// when
+			_, err := LoadMatcher(configuration)
+
+			// then
+			Ω(err).Should(HaveOccurred())
 		})
 
 		It("should load defined inclusion pattern without default language specific matchers", func() {
 			// given
 			configurationWithInclusionPattern := &PluginConfiguration{
-				Inclusions: []string{`regex{{*IT.java|*TestCase.java}}`},
+				Inclusions: []string{`regex{{.*IT.java|.*TestCase.java}}`},
 			}
 			firstRegexp := func(matcher TestMatcher) string {
 				return matcher.Inclusion[0].Regexp
@@ -116,7 +150,7 @@ var _ = Describe("Test Matcher features", func() {
 			// then
 			Ω(err).ShouldNot(HaveOccurred())
 			Expect(matchers.Inclusion).To(HaveLen(1))
-			Expect(matchers).To(WithTransform(firstRegexp, Equal("*IT.java|*TestCase.java")))
+			Expect(matchers).To(WithTransform(firstRegexp, Equal(".*IT.java|.*TestCase.java")))
 		})
 	})
 
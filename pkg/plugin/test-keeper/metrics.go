@@ -1,6 +1,8 @@
 package testkeeper
 
 import (
+	"strconv"
+
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	"github.com/arquillian/ike-prow-plugins/pkg/server"
 	gogh "github.com/google/go-github/github"
@@ -23,7 +25,7 @@ var (
 		Name:    "test_keeper_pull_requests_size",
 		Help:    "Histogram for pull request size with ok-without-tests command applied in test-keeper plugin",
 		Buckets: prometheus.ExponentialBuckets(1, 3, 6),
-	}, []string{"full_name"})
+	}, []string{"full_name", "with_reason"})
 )
 
 // RegisterMetrics registers prometheus collectors to collect metrics for test-keeper.
@@ -49,8 +51,11 @@ func reportPullRequest(l log.Logger, pr *gogh.PullRequest, prType string) {
 	}
 }
 
-func reportBypassCommand(pr *gogh.PullRequest) {
-	okWithoutTestsPullRequest.WithLabelValues(*pr.Base.Repo.FullName).Observe(float64(*pr.ChangedFiles))
+// reportBypassCommand observes the size of the bypassed PR. Only the presence of the reason is used as a label, so the free
+// text doesn't make the number of series unbounded - the reason itself is shown in the status description
+func reportBypassCommand(pr *gogh.PullRequest, reason string) {
+	okWithoutTestsPullRequest.WithLabelValues(*pr.Base.Repo.FullName, strconv.FormatBool(reason != "")).
+		Observe(float64(*pr.ChangedFiles))
 }
 
 // PullRequestCounterWithLabelValues replaces the method of the same name in MetricVec.
@@ -68,7 +68,7 @@ var _ = Describe("TestKeeper Metrics", func() {
 		// then - should not expect any additional request mocking
 		Ω(err).ShouldNot(HaveOccurred())
 		repositoryName := *prMock.PullRequest.Base.Repo.FullName
-		histogram, err := testkeeper.OkWithoutTestsPullRequestWithLabelValues(repositoryName)
+		histogram, err := testkeeper.OkWithoutTestsPullRequestWithLabelValues(repositoryName, "false")
 		Ω(err).ShouldNot(HaveOccurred())
 
 		metric, err := toMetric(histogram)
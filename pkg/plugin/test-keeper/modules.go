@@ -0,0 +1,277 @@
+package testkeeper
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+)
+
+// ModuleConfiguration defines patterns and the policy applied to the changed files located in the Path (a directory
+// relative to the root) of the repository.
+// Each setting defined for the module replaces the top-level one, the others are taken from the top-level configuration
+type ModuleConfiguration struct {
+	Path                   string        `yaml:"path"`
+	Inclusions             []string      `yaml:"test_patterns,omitempty"`
+	Exclusions             []string      `yaml:"skip_validation_for,omitempty"`
+	Combine                *bool         `yaml:"combine_defaults,omitempty"`
+	Policy                 string        `yaml:"policy,omitempty"`
+	MinTestRatio           float64       `yaml:"min_test_ratio,omitempty"`
+	ProductionFilesPerTest int           `yaml:"production_files_per_test,omitempty"`
+	Pairing                []PairingRule `yaml:"test_pairing,omitempty"`
+}
+
+// moduleConfiguration creates the configuration used to verify files of the given module
+func (c *PluginConfiguration) moduleConfiguration(module ModuleConfiguration) *PluginConfiguration {
+	moduleConfig := *c
+	moduleConfig.Modules = nil
+	if len(module.Inclusions) != 0 {
+		moduleConfig.Inclusions = module.Inclusions
+	}
+	if len(module.Exclusions) != 0 {
+		moduleConfig.Exclusions = module.Exclusions
+	}
+	if module.Combine != nil {
+		moduleConfig.Combine = *module.Combine
+	}
+	if module.Policy != "" {
+		moduleConfig.Policy = module.Policy
+	}
+	if module.MinTestRatio != 0 {
+		moduleConfig.MinTestRatio = module.MinTestRatio
+	}
+	if module.ProductionFilesPerTest != 0 {
+		moduleConfig.ProductionFilesPerTest = module.ProductionFilesPerTest
+	}
+	if len(module.Pairing) != 0 {
+		moduleConfig.Pairing = module.Pairing
+	}
+	return &moduleConfig
+}
+
+func (c *PluginConfiguration) validateModules(field string) []config.FieldError {
+	var fieldErrors []config.FieldError
+	for _, module := range c.Modules {
+		if modulePrefix(module.Path) == "" {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: field, Value: module.Path,
+				Message: "module path has to be a directory located in the repository"})
+			continue
+		}
+		fieldErrors = append(fieldErrors, validateFilePatterns(field, module.Inclusions)...)
+		fieldErrors = append(fieldErrors, validateFilePatterns(field, module.Exclusions)...)
+		fieldErrors = append(fieldErrors, validatePairingRules(field, module.Pairing)...)
+		if module.Policy != "" || module.MinTestRatio != 0 || module.ProductionFilesPerTest != 0 {
+			for _, policyErr := range c.moduleConfiguration(module).validatePolicy() {
+				fieldErrors = append(fieldErrors, config.FieldError{Field: field, Value: module.Path,
+					Message: fmt.Sprintf("module `%s`: %s", module.Path, policyErr.Message)})
+			}
+		}
+	}
+	return fieldErrors
+}
+
+// otherFilesModule is used as a path of the changed files which don't belong to any of the configured modules
+const otherFilesModule = "other files"
+
+// moduleResult holds the outcome of the verification of the changed files belonging to a single module
+type moduleResult struct {
+	path           string
+	fileCategories FileCategories
+	measurements   testMeasurements
+	sufficient     bool
+}
+
+// ok tells if the module doesn't need any test or comes with enough of them
+func (r moduleResult) ok() bool {
+	return r.fileCategories.OnlySkippedFiles() || r.sufficient
+}
+
+func (r moduleResult) String() string {
+	return describeResult(r.fileCategories, r.measurements, r.sufficient)
+}
+
+// verificationResults holds outcomes of the verification of all modules touched by the PR. When there are no modules
+// configured all the changed files are verified together as a single one
+type verificationResults struct {
+	modular bool
+	files   []scm.ChangedFile
+	modules []moduleResult
+}
+
+// onlySkippedFiles indicates if all the touched modules contain only files which are excluded from test verification
+func (v verificationResults) onlySkippedFiles() bool {
+	for _, module := range v.modules {
+		if !module.fileCategories.OnlySkippedFiles() {
+			return false
+		}
+	}
+	return len(v.modules) > 0
+}
+
+// sufficient indicates that none of the touched modules violates its policy
+func (v verificationResults) sufficient() bool {
+	return len(v.failed()) == 0
+}
+
+func (v verificationResults) failed() []moduleResult {
+	var failed []moduleResult
+	for _, module := range v.modules {
+		if !module.ok() {
+			failed = append(failed, module)
+		}
+	}
+	return failed
+}
+
+// measurements returns measurements of the only verified module when there are no modules configured
+func (v verificationResults) measurements() testMeasurements {
+	if v.modular || len(v.modules) == 0 {
+		return nil
+	}
+	return v.modules[0].measurements
+}
+
+// production lists production files of the modules which violate their policy, or production files without a matching
+// test if only those are known
+func (v verificationResults) production() []string {
+	var production []string
+	for _, module := range v.failed() {
+		if missing := module.measurements.missing(); len(missing) > 0 {
+			production = append(production, missing...)
+		} else {
+			production = append(production, module.fileCategories.Production...)
+		}
+	}
+	return production
+}
+
+// uncovered lists lines added to production files of the modules which violate their policy and are not covered by tests
+func (v verificationResults) uncovered() []uncoveredLines {
+	var uncovered []uncoveredLines
+	for _, module := range v.failed() {
+		uncovered = append(uncovered, module.measurements.uncovered()...)
+	}
+	return uncovered
+}
+
+// testCases sums up test cases declared in the tests of all the touched modules
+func (v verificationResults) testCases() int {
+	testCases := 0
+	for _, module := range v.modules {
+		testCases += module.fileCategories.TestCases
+	}
+	return testCases
+}
+
+func (v verificationResults) failedPaths() string {
+	var paths []string
+	for _, module := range v.failed() {
+		paths = append(paths, module.path)
+	}
+	return strings.Join(paths, ", ")
+}
+
+// breakdown creates a markdown table with results of the touched modules
+func (v verificationResults) breakdown() string {
+	rows := []string{"| Module | Result |", "| --- | --- |"}
+	for _, module := range v.modules {
+		icon := ":white_check_mark:"
+		if !module.ok() {
+			icon = ":x:"
+		}
+		path := "`" + module.path + "`"
+		if module.path == otherFilesModule {
+			path = module.path
+		}
+		rows = append(rows, fmt.Sprintf("| %s | %s %s |", path, icon, module))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// verifyModules splits the changed files into the configured modules and verifies each touched module separately using
+// its own patterns and policy. Files which don't belong to any module are verified using the top-level configuration.
+// The coverage report is needed only when any of the policies is based on it
+func verifyModules(configuration *PluginConfiguration, languages []string, changedFiles []scm.ChangedFile,
+	report *CoverageReport) (verificationResults, error) {
+	results := verificationResults{modular: len(configuration.Modules) != 0, files: changedFiles}
+
+	modulePrefixes := make([]string, 0, len(configuration.Modules))
+	for _, module := range configuration.Modules {
+		prefix := modulePrefix(module.Path)
+		if prefix == "" {
+			return results, fmt.Errorf("module path `%s` is not a directory located in the repository", module.Path)
+		}
+		modulePrefixes = append(modulePrefixes, prefix)
+	}
+	moduleFiles := make([][]scm.ChangedFile, len(configuration.Modules))
+	var otherFiles []scm.ChangedFile
+	for _, file := range changedFiles {
+		if i := moduleOf(modulePrefixes, file.Name); i >= 0 {
+			moduleFiles[i] = append(moduleFiles[i], file)
+		} else {
+			otherFiles = append(otherFiles, file)
+		}
+	}
+
+	for i, module := range configuration.Modules {
+		if len(moduleFiles[i]) == 0 {
+			continue
+		}
+		result, err := verifyModule(module.Path, configuration.moduleConfiguration(module), languages, moduleFiles[i], report)
+		if err != nil {
+			return results, err
+		}
+		results.modules = append(results.modules, result)
+	}
+
+	if len(otherFiles) != 0 || !results.modular {
+		result, err := verifyModule(otherFilesModule, configuration, languages, otherFiles, report)
+		if err != nil {
+			return results, err
+		}
+		results.modules = append(results.modules, result)
+	}
+
+	return results, nil
+}
+
+// modulePrefix turns the module path into the prefix of the names of the files located in the module directory, so the
+// nested directories with the same name (e.g. `services/api/` for `api/`) don't belong to the module. It returns an empty
+// string when the path doesn't point to a directory located in the repository
+func modulePrefix(modulePath string) string {
+	cleaned := path.Clean("/" + strings.TrimSpace(modulePath))
+	if cleaned == "/" || strings.TrimSpace(modulePath) == "" {
+		return ""
+	}
+	return strings.TrimPrefix(cleaned, "/") + "/"
+}
+
+// moduleOf returns the index of the first module the file belongs to or -1 if there is no such module
+func moduleOf(modulePrefixes []string, filename string) int {
+	for i, prefix := range modulePrefixes {
+		if strings.HasPrefix(filename, prefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+func verifyModule(path string, configuration *PluginConfiguration, languages []string,
+	files []scm.ChangedFile, report *CoverageReport) (moduleResult, error) {
+	fileVerifier, err := newVerifier(configuration, languages, files, report)
+	if err != nil {
+		return moduleResult{}, err
+	}
+
+	// all the files are counted, so the number of new test cases can be reported
+	fileCategoryCounter := FileCategoryCounter{Matcher: fileVerifier.matcher}
+	fileCategories, err := fileCategoryCounter.Count(files)
+	if err != nil {
+		return moduleResult{}, err
+	}
+
+	measurements, sufficient := fileVerifier.policy.evaluate(fileCategories)
+	return moduleResult{path: path, fileCategories: fileCategories, measurements: measurements, sufficient: sufficient}, nil
+}
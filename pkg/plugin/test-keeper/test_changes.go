@@ -0,0 +1,140 @@
+package testkeeper
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+)
+
+// TestCaseDeclarations holds regular expressions matching declarations of test cases (such as @Test annotation
+// or func Test...) in the test files with the given extensions together with beginnings of the lines which are
+// comments in these files (such as // or #)
+type TestCaseDeclarations struct {
+	Extensions   []string
+	Declarations []*regexp.Regexp
+	Comments     []string
+}
+
+// ParseTestCaseDeclarations compiles the given regular expressions matching declarations of test cases. Expressions
+// which cannot be compiled are ignored, as they are reported while validating the configuration
+func ParseTestCaseDeclarations(extensions, declarations, comments []string) TestCaseDeclarations {
+	testCases := TestCaseDeclarations{Extensions: extensions, Comments: comments}
+	for _, declaration := range declarations {
+		if expression, err := regexp.Compile(declaration); err == nil {
+			testCases.Declarations = append(testCases.Declarations, expression)
+		}
+	}
+	return testCases
+}
+
+func (d *TestCaseDeclarations) appliesTo(filename string) bool {
+	extension := strings.TrimPrefix(path.Ext(filename), ".")
+	for _, candidate := range d.Extensions {
+		if extension != "" && strings.EqualFold(strings.TrimPrefix(candidate, "."), extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// AnalyzeTestChange inspects hunks of the patch of the test file. It tells if the change brings any real test code (see codeLines)
+// and counts declarations of new test cases in such lines. When there is no patch (e.g. it's too large), the file is considered
+// to change the test code unless it only removes lines or it's renamed without any addition
+func (matcher *TestMatcher) AnalyzeTestChange(file scm.ChangedFile) (changesCode bool, testCases int) {
+	if file.Patch == "" {
+		if file.Status == "renamed" {
+			return file.Additions > 0, 0
+		}
+		return !(file.Additions == 0 && file.Deletions > 0), 0
+	}
+
+	declarations, _ := matcher.testCaseDeclarationsOf(file.Name)
+	added, _ := matcher.codeLines(file)
+	for _, line := range added {
+		if declaresTestCase(declarations, line) {
+			testCases++
+		}
+	}
+	return len(added) > 0, testCases
+}
+
+// ChangesTestCode tells if the patch of the test file either adds or removes any real test code (see codeLines), so the
+// change which only reformats the tests, moves them or edits comments can be told apart. When there is no patch, only
+// the file which is renamed without any other change is considered not to change the test code
+func (matcher *TestMatcher) ChangesTestCode(file scm.ChangedFile) bool {
+	if file.Patch == "" {
+		return !(file.Status == "renamed" && file.Additions == 0 && file.Deletions == 0)
+	}
+	added, removed := matcher.codeLines(file)
+	return len(added) > 0 || len(removed) > 0
+}
+
+// codeLines returns the lines of real code added and removed by the patch of the file - lines which are neither blank,
+// nor comments (as defined for the language of the file), nor only re-indented or moved lines present on both sides of the patch
+func (matcher *TestMatcher) codeLines(file scm.ChangedFile) (added, removed []string) {
+	_, comments := matcher.testCaseDeclarationsOf(file.Name)
+	isCode := func(normalized string) bool {
+		return normalized != "" && !isComment(comments, normalized)
+	}
+
+	patchAdded, patchRemoved := file.PatchLines()
+	removedLines := make(map[string]int, len(patchRemoved))
+	for _, line := range patchRemoved {
+		if normalized := normalizeLine(line); isCode(normalized) {
+			removedLines[normalized]++
+		}
+	}
+	for _, line := range patchAdded {
+		normalized := normalizeLine(line)
+		if !isCode(normalized) {
+			continue
+		}
+		if removedLines[normalized] > 0 {
+			removedLines[normalized]--
+			continue
+		}
+		added = append(added, line)
+	}
+	for _, line := range patchRemoved {
+		if normalized := normalizeLine(line); isCode(normalized) && removedLines[normalized] > 0 {
+			removedLines[normalized]--
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
+
+func (matcher *TestMatcher) testCaseDeclarationsOf(filename string) (declarations []*regexp.Regexp, comments []string) {
+	for _, testCases := range matcher.TestCases {
+		if testCases.appliesTo(filename) {
+			declarations = append(declarations, testCases.Declarations...)
+			comments = append(comments, testCases.Comments...)
+		}
+	}
+	return declarations, comments
+}
+
+func declaresTestCase(declarations []*regexp.Regexp, line string) bool {
+	for _, declaration := range declarations {
+		if declaration.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLine collapses all the whitespaces in the line so changes of indentation or spacing are not considered
+func normalizeLine(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
+
+func isComment(commentPrefixes []string, line string) bool {
+	for _, prefix := range commentPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
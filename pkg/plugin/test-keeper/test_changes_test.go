@@ -0,0 +1,104 @@
+package testkeeper_test
+
+import (
+	testkeeper "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test changes analysis features", func() {
+
+	var defaultMatcher, _ = testkeeper.LoadDefaultMatcher()
+
+	Context("Analyzing patches of test files", func() {
+
+		table.DescribeTable("should recognise real changes of test code and count new test cases",
+			func(file scm.ChangedFile, expectedChange bool, expectedTestCases int) {
+				// when
+				changesCode, testCases := defaultMatcher.AnalyzeTestChange(file)
+
+				// then
+				Expect(changesCode).To(Equal(expectedChange))
+				Expect(testCases).To(Equal(expectedTestCases))
+			},
+			table.Entry("new JUnit tests", scm.ChangedFile{
+				Name: "src/test/java/org/acme/BarTest.java", Status: "modified", Additions: 8,
+				Patch: "@@ -5,2 +5,10 @@ class BarTest {\n" +
+					"+    @Test\n+    void should_bar() {\n+        assertThat(bar()).isTrue();\n+    }\n" +
+					"+    @ParameterizedTest\n+    void should_bar_many(int i) {\n+        assertThat(bar(i)).isTrue();\n+    }\n }",
+			}, true, 2),
+			table.Entry("new Go test function", scm.ChangedFile{
+				Name: "pkg/bar/bar_test.go", Status: "modified", Additions: 3,
+				Patch: "@@ -10,0 +11,3 @@\n+func TestBar(t *testing.T) {\n+\tassert.True(t, bar())\n+}",
+			}, true, 1),
+			table.Entry("changed assertion", scm.ChangedFile{
+				Name: "pkg/bar/bar_test.go", Status: "modified", Additions: 1, Deletions: 1,
+				Patch: "@@ -12,1 +12,1 @@\n-\tassert.True(t, bar())\n+\tassert.False(t, bar())",
+			}, true, 0),
+			table.Entry("changed indentation only", scm.ChangedFile{
+				Name: "pkg/bar/bar_test.go", Status: "modified", Additions: 1, Deletions: 1,
+				Patch: "@@ -12,1 +12,1 @@\n-  assert.True(t,  bar())\n+\tassert.True(t, bar())",
+			}, false, 0),
+			table.Entry("added comments and blank lines only", scm.ChangedFile{
+				Name: "src/test/java/org/acme/BarTest.java", Status: "modified", Additions: 4,
+				Patch: "@@ -5,0 +6,4 @@\n+\n+    /**\n+     * @Test is missing here\n+     */",
+			}, false, 0),
+			table.Entry("added Python comments only", scm.ChangedFile{
+				Name: "tests/test_bar.py", Status: "modified", Additions: 2,
+				Patch: "@@ -5,0 +6,2 @@\n+    # bar should be always true\n+    # see the docs",
+			}, false, 0),
+			table.Entry("Python multiplication starting the line", scm.ChangedFile{
+				Name: "tests/test_bar.py", Status: "modified", Additions: 2,
+				Patch: "@@ -5,0 +6,2 @@\n+    total = (bar()\n+             * 2)",
+			}, true, 0),
+			table.Entry("Go dereference starting the line", scm.ChangedFile{
+				Name: "pkg/bar/bar_test.go", Status: "modified", Additions: 1,
+				Patch: "@@ -12,0 +13,1 @@\n+\t*bar = true",
+			}, true, 0),
+			table.Entry("C include in a test of a language without known comments", scm.ChangedFile{
+				Name: "test/test_bar.c", Status: "modified", Additions: 1,
+				Patch: "@@ -1,0 +2,1 @@\n+#include \"bar.h\"",
+			}, true, 0),
+			table.Entry("moved test", scm.ChangedFile{
+				Name: "pkg/bar/bar_test.go", Status: "modified", Additions: 1, Deletions: 1,
+				Patch: "@@ -3,1 +3,0 @@\n-func TestBar(t *testing.T) {}\n@@ -20,0 +19,1 @@\n+func TestBar(t *testing.T) {}",
+			}, false, 0),
+			table.Entry("renamed test without changes", scm.ChangedFile{
+				Name: "pkg/baz/bar_test.go", PreviousFilename: "pkg/bar/bar_test.go", Status: "renamed",
+			}, false, 0),
+			table.Entry("test without available patch", scm.ChangedFile{
+				Name: "pkg/bar/bar_test.go", Status: "modified", Additions: 2400,
+			}, true, 0),
+		)
+
+		table.DescribeTable("should tell apart changes which only reformat or move tests",
+			func(file scm.ChangedFile, expectedChange bool) {
+				// when
+				changesCode := defaultMatcher.ChangesTestCode(file)
+
+				// then
+				Expect(changesCode).To(Equal(expectedChange))
+			},
+			table.Entry("changed indentation only", scm.ChangedFile{
+				Name: "pkg/bar/bar_test.go", Status: "modified", Additions: 1, Deletions: 1,
+				Patch: "@@ -12,1 +12,1 @@\n-  assert.True(t,  bar())\n+\tassert.True(t, bar())",
+			}, false),
+			table.Entry("edited comment", scm.ChangedFile{
+				Name: "tests/test_bar.py", Status: "modified", Additions: 1, Deletions: 1,
+				Patch: "@@ -5,1 +5,1 @@\n-    # bar should be true\n+    # bar should be always true",
+			}, false),
+			table.Entry("removed test", scm.ChangedFile{
+				Name: "pkg/bar/bar_test.go", Status: "modified", Deletions: 3,
+				Patch: "@@ -11,3 +10,0 @@\n-func TestBar(t *testing.T) {\n-\tassert.True(t, bar())\n-}",
+			}, true),
+			table.Entry("renamed test without changes", scm.ChangedFile{
+				Name: "pkg/baz/bar_test.go", PreviousFilename: "pkg/bar/bar_test.go", Status: "renamed",
+			}, false),
+			table.Entry("test without available patch", scm.ChangedFile{
+				Name: "pkg/bar/bar_test.go", Status: "modified", Deletions: 2400,
+			}, true),
+		)
+	})
+})
@@ -21,6 +21,8 @@ type testStatusService struct {
 const (
 	// TestsExistMessage is a message used in GH Status as description when tests are found
 	TestsExistMessage = "There are some tests :)"
+	// NewTestCasesMessage is a message used in GH Status as description when tests declaring new test cases are found
+	NewTestCasesMessage = "There are some tests :) New test cases: %d"
 	// TestsExistDetailsPageName is a name of a documentation page that contains additional status details for TestsExistMessage
 	TestsExistDetailsPageName = "tests-exist"
 
@@ -29,24 +31,65 @@ const (
 	// NoTestsDetailsPageName is a name of a documentation page that contains additional status details for NoTestsMessage
 	NoTestsDetailsPageName = "no-tests"
 
+	// ProportionalTestsMessage is a message used in GH Status as description when tests are proportional to the changes of the production code
+	ProportionalTestsMessage = "Tests are proportional to the changes: %s"
+
+	// InsufficientTestsMessage is a message used in GH Status as description when tests are not proportional to the changes of the production code
+	InsufficientTestsMessage = "Not enough tests: %s"
+	// ModulesWithoutTestsMessage is a message used in GH Status as description when some of the modules changed in the PR don't come with enough tests
+	ModulesWithoutTestsMessage = "Not enough tests in modules: %s"
+	// InsufficientTestsDetailsPageName is a name of a documentation page that contains additional status details for InsufficientTestsMessage
+	InsufficientTestsDetailsPageName = "insufficient-tests"
+
 	// OkOnlySkippedFilesMessage is a message used in GH Status as description when PR comes with a changeset which shouldn't be subject of test verification
 	OkOnlySkippedFilesMessage = "Seems that this PR doesn't need to have tests"
 	// OkOnlySkippedFilesDetailsPageName is a name of a documentation page that contains additional status details for OkOnlySkippedFilesMessage
 	OkOnlySkippedFilesDetailsPageName = "only-skipped"
 
+	// WaitingForCoverageMessage is a message used in GH Status as description when the coverage report of the PR is not available yet
+	WaitingForCoverageMessage = "Waiting for coverage report"
+	// CoverageReportNotFoundMessage is a message used in GH Status as description when the coverage report of the PR is not found at report_url
+	CoverageReportNotFoundMessage = "Coverage report not found - comment /run test-keeper once it's published"
+	// CoverageReportNotFoundDetailsPageName is a name of a documentation page that contains additional status details for CoverageReportNotFoundMessage
+	CoverageReportNotFoundDetailsPageName = "coverage-report-not-found"
+
 	// FailureMessage is a message used in GH Status as description when failure occurred
 	FailureMessage = "Failed while check for tests"
 
 	// ApprovedByMessage is a message used in GH Status as description when it's commented to skip the check
 	ApprovedByMessage = "PR is fine without tests says @%s"
+	// ApprovedByWithReasonMessage is a message used in GH Status as description when it's commented to skip the check with a reason
+	ApprovedByWithReasonMessage = "PR is fine without tests says @%s: %s"
+	// ApprovedByWithReasonMsg is a summary of the check run holding the whole reason given by the user who skipped the check
+	ApprovedByWithReasonMsg = "@%s says this PR is fine without tests:\n\n%s"
 	// ApprovedByDetailsPageName is a name of a documentation page that contains additional status details for ApprovedByMessage
 	ApprovedByDetailsPageName = "keeper-approved-by"
+
+	// MissingBypassReasonMsg is a message commented when the bypass command is used without a reason which is required
+	MissingBypassReasonMsg = "Hey @%s, please let us know why this PR is fine without tests, e.g. `" + BypassCheckComment +
+		" only typos in log messages`. The command without a reason is not accepted in this repository."
+
+	// ApprovedByActionMsg is a message commented when the PR is approved without tests using the check run action. The comment
+	// records the approval, so it is kept when the PR is verified again, until it's revoked by the require tests command
+	ApprovedByActionMsg = "@%s approved this PR without tests using the check run action. Comment `" + RequireTestsComment +
+		"` to revoke the approval."
+
+	// maxDescriptionLength is the maximal length of the status description accepted by GitHub
+	maxDescriptionLength = 140
+
+	// OkWithoutTestsActionID identifies the check run action which approves the PR without tests
+	OkWithoutTestsActionID = "ok-without-tests"
+	// MissingTestsAnnotationMessage is a message of the check run annotation marking production file with no related test
+	MissingTestsAnnotationMessage = "This file has been changed, but no test has been added or updated in this PR."
+	// UncoveredLinesAnnotationMessage is a message of the check run annotation marking added lines not covered by tests
+	UncoveredLinesAnnotationMessage = "These lines have been added in this PR, but they are not covered by tests."
 )
 
-func (gh *GitHubTestEventsHandler) newTestStatusService(logger log.Logger, pullRequest *gogh.PullRequest) *testStatusService {
+func (gh *GitHubTestEventsHandler) newTestStatusService(logger log.Logger, pullRequest *gogh.PullRequest,
+	config *PluginConfiguration) *testStatusService {
 	change := ghservice.NewRepositoryChangeForPR(pullRequest)
 	statusContext := github.StatusContext{BotName: gh.BotName, PluginName: ProwPluginName}
-	statusService := status.NewStatusService(gh.Client, logger, change, statusContext)
+	statusService := status.NewConfiguredStatusService(gh.Client, logger, change, statusContext, &config.PluginConfiguration)
 	return &testStatusService{
 		logger:        logger,
 		change:        change,
@@ -54,7 +97,13 @@ func (gh *GitHubTestEventsHandler) newTestStatusService(logger log.Logger, pullR
 	}
 }
 
-func (ts *testStatusService) okTestsExist() error {
+func (ts *testStatusService) okTestsExist(results verificationResults) error {
+	if measurements := results.measurements(); len(measurements) > 0 {
+		return ts.statusService.Success(fmt.Sprintf(ProportionalTestsMessage, measurements), TestsExistDetailsPageName)
+	}
+	if testCases := results.testCases(); testCases > 0 {
+		return ts.statusService.Success(fmt.Sprintf(NewTestCasesMessage, testCases), TestsExistDetailsPageName)
+	}
 	return ts.statusService.Success(TestsExistMessage, TestsExistDetailsPageName)
 }
 
@@ -62,34 +111,132 @@ func (ts *testStatusService) okOnlySkippedFiles() error {
 	return ts.statusService.Success(OkOnlySkippedFilesMessage, OkOnlySkippedFilesDetailsPageName)
 }
 
-func (ts *testStatusService) okWithoutTests(approvedBy string) error {
-	return ts.statusService.Success(fmt.Sprintf(ApprovedByMessage, approvedBy), ApprovedByDetailsPageName)
+// okWithoutTests reports the success status of the bypassed PR. As the status description is limited, the whole reason
+// of the bypass is published in the summary of the check run
+func (ts *testStatusService) okWithoutTests(approval bypass) error {
+	if approval.reason == "" {
+		return ts.statusService.Success(fmt.Sprintf(ApprovedByMessage, approval.user), ApprovedByDetailsPageName)
+	}
+	description := truncate(fmt.Sprintf(ApprovedByWithReasonMessage, approval.user, approval.reason), maxDescriptionLength)
+	report := scm.CheckReport{Summary: fmt.Sprintf(ApprovedByWithReasonMsg, approval.user, approval.reason)}
+	return status.WithReport(ts.statusService, report).Success(description, ApprovedByDetailsPageName)
+}
+
+func (ts *testStatusService) waitForCoverageReport() error {
+	return ts.statusService.Pending(WaitingForCoverageMessage)
+}
+
+func (ts *testStatusService) failCoverageReportNotFound() error {
+	return ts.statusService.Failure(CoverageReportNotFoundMessage, CoverageReportNotFoundDetailsPageName)
 }
 
 func (ts *testStatusService) reportError() error {
 	return ts.statusService.Error(FailureMessage)
 }
 
-func (ts *testStatusService) failNoTests() error {
-	return ts.statusService.Failure(NoTestsMessage, NoTestsDetailsPageName)
+// reportConfigError sets the error status when the configuration of the plugin cannot be loaded. The cause is returned
+// so it is propagated to the caller
+func (ts *testStatusService) reportConfigError(cause error) error {
+	return status.ReportConfigError(ts.statusService, nil, ts.logger, cause)
+}
+
+// failNoTests reports the failure status. The action approving the PR without tests is offered only when it can be
+// accepted, i.e. the reason of the bypass is not required
+func (ts *testStatusService) failNoTests(results verificationResults, offerBypass bool) error {
+	description, detailsPage, summary := NoTestsMessage, NoTestsDetailsPageName, WithoutTestsMsg
+	if results.modular {
+		description = truncate(fmt.Sprintf(ModulesWithoutTestsMessage, results.failedPaths()), maxDescriptionLength)
+		detailsPage = InsufficientTestsDetailsPageName
+		summary = modulesWithoutTestsMsg(results)
+	} else if measurements := results.measurements(); len(measurements) > 0 {
+		description = fmt.Sprintf(InsufficientTestsMessage, measurements.unsatisfied())
+		detailsPage = InsufficientTestsDetailsPageName
+		summary = insufficientTestsMsg(measurements)
+	}
+	report := scm.CheckReport{Summary: summary}
+	if offerBypass {
+		report.Actions = []scm.CheckAction{{
+			Label:       "Ok without tests",
+			Description: "Approve this PR without tests",
+			Identifier:  OkWithoutTestsActionID,
+		}}
+	}
+	if uncovered := results.uncovered(); len(uncovered) > 0 {
+		for _, lines := range uncovered {
+			for _, lineRange := range lines.ranges {
+				report.Annotations = append(report.Annotations, scm.CheckAnnotation{
+					Path:      lines.file,
+					StartLine: lineRange.start,
+					EndLine:   lineRange.end,
+					Level:     scm.AnnotationWarning,
+					Title:     description,
+					Message:   UncoveredLinesAnnotationMessage,
+				})
+			}
+		}
+	} else {
+		for _, file := range results.production() {
+			report.Annotations = append(report.Annotations, scm.CheckAnnotation{
+				Path:    file,
+				Level:   scm.AnnotationWarning,
+				Title:   description,
+				Message: MissingTestsAnnotationMessage,
+			})
+		}
+	}
+	return status.WithReport(ts.statusService, report).Failure(description, detailsPage)
 }
 
 const (
 	paragraph = "\n\n"
 
+	bypassHint = "If you are an admin or the reviewer of this PR and you are sure that no test is needed then you can use the command `" + BypassCheckComment + "` " +
+		"as a comment to make the status green.\n"
+
 	// WithoutTestsMsg contains a status message related to the state when PR is pushed without any test
 	WithoutTestsMsg = "It appears that no tests have been added or updated in this PR." +
 		paragraph +
 		"Automated tests give us confidence in shipping reliable software. Please add some as part of this change." +
 		paragraph +
-		"If you are an admin or the reviewer of this PR and you are sure that no test is needed then you can use the command `" + BypassCheckComment + "` " +
-		"as a comment to make the status green.\n"
+		bypassHint
+
+	// InsufficientTestsMsg contains a status message related to the state when PR comes with tests which are not proportional
+	// to the changes of the production code. It is formatted with the ratios which don't meet the configured minimum
+	// and with the list of production files without a matching test (if the tests are paired with production files)
+	InsufficientTestsMsg = "It appears that the tests added or updated in this PR are not proportional to the changes of the production code: %s.%s" +
+		paragraph +
+		"Automated tests give us confidence in shipping reliable software. Please add more of them as part of this change." +
+		paragraph +
+		bypassHint
+
+	// ModulesWithoutTestsMsg contains a status message related to the state when some of the modules changed in the PR don't come
+	// with enough tests. It is formatted with the table breaking the results down per module
+	ModulesWithoutTestsMsg = "It appears that some of the modules changed in this PR don't come with enough tests:" +
+		paragraph +
+		"%s" +
+		paragraph +
+		"Automated tests give us confidence in shipping reliable software. Please add some as part of this change." +
+		paragraph +
+		bypassHint
+
+	// MissingTestsMsg introduces the list of production files without a matching test in InsufficientTestsMsg
+	MissingTestsMsg = "Following production files come without a matching test:\n"
+
+	// UncoveredLinesMsg introduces the list of added production lines not covered by tests in InsufficientTestsMsg
+	UncoveredLinesMsg = "Following production lines added in this PR are not covered by tests:\n"
 
 	documentationSection = "#_test_keeper_plugin"
 
 	// WithTestsMsg contains a status message related to the state when PR is updated by a commit containing a test
 	WithTestsMsg = "It seems that this PR already contains some added or changed tests. Good job!"
 
+	// NewTestCasesMsg is appended to the status message related to the state when PR contains tests declaring new test cases
+	NewTestCasesMsg = "Number of new test cases: %d"
+
+	// WithProportionalTestsMsg contains a status message related to the state when PR is updated so its tests are proportional
+	// to the changes of the production code. It is formatted with the measured ratios
+	WithProportionalTestsMsg = "It seems that this PR already contains enough added or changed tests (%s). Good job!"
+
 	// OnlySkippedMsg contains a status message related to the state when PR is updated so it contains only skipped files
 	OnlySkippedMsg = "It seems that this PR doesn't need any test as all changed files in the changeset match " +
 		"patterns for which the validation should be skipped."
@@ -108,23 +255,77 @@ func (gh *GitHubTestEventsHandler) newTestStatusServiceWithMessages(logger log.L
 	msgService := message.NewStatusMessageService(gh.Client, logger, commentsLoader, msgContext)
 
 	return testStatusServiceWithMessages{
-		testStatusService: gh.newTestStatusService(logger, pullRequest),
+		testStatusService: gh.newTestStatusService(logger, pullRequest, config),
 		statusMsgService:  msgService,
 		config:            config,
 	}
 }
 
+// reportConfigError sets the error status when the configuration of the plugin cannot be loaded and lists the problems
+// in the status message when the configuration is invalid. The cause is returned so it is propagated to the caller
+func (ts *testStatusServiceWithMessages) reportConfigError(cause error) error {
+	return status.ReportConfigError(ts.statusService, ts.statusMsgService, ts.logger, cause)
+}
+
 // CreateWithoutTestsMessage creates a status message for the test-keeper plugin. If the status message is set in config then it takes that one, the default otherwise.
-func (ts *testStatusServiceWithMessages) withoutTestsMessage() {
+func (ts *testStatusServiceWithMessages) withoutTestsMessage(results verificationResults) {
+	if results.modular {
+		ts.statusMsgService.SadStatusMessage(modulesWithoutTestsMsg(results), "modules_without_tests", true)
+		return
+	}
+	if measurements := results.measurements(); len(measurements) > 0 {
+		ts.statusMsgService.SadStatusMessage(insufficientTestsMsg(measurements), "insufficient_tests", true)
+		return
+	}
 	ts.statusMsgService.SadStatusMessage(WithoutTestsMsg, "without_tests", true)
 }
 
 // CreateWithTestsMessage creates a status message for the test-keeper plugin. If the status message is set in config then it takes that one, the default otherwise.
-func (ts *testStatusServiceWithMessages) withTestsMessage() {
-	ts.statusMsgService.HappyStatusMessage(WithTestsMsg, "with_tests", false)
+func (ts *testStatusServiceWithMessages) withTestsMessage(results verificationResults) {
+	msg := WithTestsMsg
+	if measurements := results.measurements(); len(measurements) > 0 {
+		msg = fmt.Sprintf(WithProportionalTestsMsg, measurements)
+	}
+	if testCases := results.testCases(); testCases > 0 {
+		msg += paragraph + fmt.Sprintf(NewTestCasesMsg, testCases)
+	}
+	if results.modular {
+		msg += paragraph + results.breakdown()
+	}
+	ts.statusMsgService.HappyStatusMessage(msg, "with_tests", false)
 }
 
 // CreateOnlySkippedMessage creates a status message for the test-keeper plugin. If the status message is set in config then it takes that one, the default otherwise.
 func (ts *testStatusServiceWithMessages) onlySkippedMessage() {
 	ts.statusMsgService.HappyStatusMessage(OnlySkippedMsg, "only_skipped", false)
 }
+
+func modulesWithoutTestsMsg(results verificationResults) string {
+	return fmt.Sprintf(ModulesWithoutTestsMsg, results.breakdown())
+}
+
+func insufficientTestsMsg(measurements testMeasurements) string {
+	missingTests := ""
+	if missing := measurements.missing(); len(missing) > 0 {
+		missingTests = paragraph + MissingTestsMsg
+		for _, file := range missing {
+			missingTests += fmt.Sprintf("\n* `%s`", file)
+		}
+	}
+	if uncovered := measurements.uncovered(); len(uncovered) > 0 {
+		missingTests += paragraph + UncoveredLinesMsg
+		for _, lines := range uncovered {
+			missingTests += fmt.Sprintf("\n* %s", lines)
+		}
+	}
+	return fmt.Sprintf(InsufficientTestsMsg, measurements.unsatisfied(), missingTests)
+}
+
+// truncate shortens the text to the given number of characters (including the trailing ellipsis)
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return string(runes[:length-1]) + "…"
+}
@@ -0,0 +1,113 @@
+package testkeeper
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+)
+
+// testNamePlaceholder is replaced by the name of the production file (without its extension) in the names of expected tests
+const testNamePlaceholder = "{name}"
+
+// PairingRule defines how the test of a production file matching the Production pattern is named and where it is located.
+// It's unmarshaled from test_pairing section of test-keeper.yml configuration file
+type PairingRule struct {
+	Production    string        `yaml:"production"`
+	Tests         []string      `yaml:"tests"`
+	PathMapping   []PathMapping `yaml:"path_mapping,omitempty"`
+	SameDirectory bool          `yaml:"same_directory,omitempty"`
+}
+
+// PathMapping replaces From part of the directory of the production file with To in order to get the directory of its test
+type PathMapping struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+// TestPairing is a parsed PairingRule used to find tests of the production files
+type TestPairing struct {
+	Production    FilePattern
+	Tests         []string
+	PathMapping   []PathMapping
+	SameDirectory bool
+}
+
+// ParseTestPairing takes the given rules and parses them to an array of TestPairing instances. It fails on the first rule
+// with invalid production pattern
+func ParseTestPairing(rules []PairingRule) ([]TestPairing, error) {
+	pairing := make([]TestPairing, 0, len(rules))
+	for _, rule := range rules {
+		production, err := ParseFilePattern(rule.Production)
+		if err != nil {
+			return nil, err
+		}
+		pairing = append(pairing, TestPairing{
+			Production:    production,
+			Tests:         rule.Tests,
+			PathMapping:   rule.PathMapping,
+			SameDirectory: rule.SameDirectory,
+		})
+	}
+	return pairing, nil
+}
+
+// Pairs checks if the given test file is the expected counterpart of the production file. The name of the test has to
+// match one of the Tests names with {name} replaced by the name of the production file. When any of the path mappings
+// applies to the directory of the production file, the test has to be located in the mapped directory. Otherwise
+// it has to be in the same directory if SameDirectory is set, or it can be located anywhere
+func (p *TestPairing) Pairs(productionFile, testFile string) bool {
+	productionDir, productionName := path.Split(productionFile)
+	productionName = strings.TrimSuffix(productionName, path.Ext(productionName))
+	testDir, testName := path.Split(testFile)
+
+	if !p.matchesTestName(productionName, testName) {
+		return false
+	}
+	for _, mapping := range p.PathMapping {
+		if strings.Contains(productionDir, mapping.From) {
+			return testDir == strings.Replace(productionDir, mapping.From, mapping.To, 1)
+		}
+	}
+	return !p.SameDirectory || testDir == productionDir
+}
+
+func (p *TestPairing) matchesTestName(productionName, testName string) bool {
+	for _, expected := range p.Tests {
+		if strings.Replace(expected, testNamePlaceholder, productionName, -1) == testName {
+			return true
+		}
+	}
+	return false
+}
+
+// FindPairing returns the first pairing rule applicable to the given production file
+func FindPairing(pairing []TestPairing, productionFile string) (TestPairing, bool) {
+	for _, rule := range pairing {
+		if rule.Production.Matches(productionFile) {
+			return rule, true
+		}
+	}
+	return TestPairing{}, false
+}
+
+func validatePairingRules(field string, rules []PairingRule) []config.FieldError {
+	var fieldErrors []config.FieldError
+	for _, rule := range rules {
+		if err := ValidateFilePattern(rule.Production); err != nil {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: field, Value: rule.Production, Message: err.Error()})
+		}
+		if len(rule.Tests) == 0 {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: field, Value: rule.Production,
+				Message: fmt.Sprintf("rule for `%s` has to define at least one test name", rule.Production)})
+		}
+		for _, mapping := range rule.PathMapping {
+			if mapping.From == "" {
+				fieldErrors = append(fieldErrors, config.FieldError{Field: field, Value: rule.Production,
+					Message: fmt.Sprintf("path mapping of rule for `%s` has to define the directory it maps from", rule.Production)})
+			}
+		}
+	}
+	return fieldErrors
+}
@@ -0,0 +1,81 @@
+package testkeeper_test
+
+import (
+	. "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test pairing features", func() {
+
+	var defaultMatcher, _ = LoadDefaultMatcher()
+
+	Context("Pairing production files with tests using predefined rules (DefaultMatchers)", func() {
+
+		table.DescribeTable("should pair production file with its test",
+			func(productionFile, testFile string, shouldPair bool) {
+				// when
+				paired, applicable := defaultMatcher.PairsWithTest(productionFile, []string{testFile})
+
+				// then
+				Expect(applicable).To(BeTrue())
+				Expect(paired).To(Equal(shouldPair))
+			},
+			table.Entry("Java test in mapped directory",
+				"src/main/java/org/acme/Bar.java", "src/test/java/org/acme/BarTest.java", true),
+			table.Entry("Java integration test in mapped directory of a module",
+				"module/src/main/java/org/acme/Bar.java", "module/src/test/java/org/acme/BarIT.java", true),
+			table.Entry("Java test in a different package",
+				"src/main/java/org/acme/Bar.java", "src/test/java/org/BarTest.java", false),
+			table.Entry("Java test of a different class",
+				"src/main/java/org/acme/Bar.java", "src/test/java/org/acme/FooTest.java", false),
+			table.Entry("Go test in the same directory",
+				"pkg/x/y.go", "pkg/x/y_test.go", true),
+			table.Entry("Go test in a different directory",
+				"pkg/x/y.go", "pkg/z/y_test.go", false),
+			table.Entry("JavaScript spec located anywhere",
+				"lib/app.js", "test/app.spec.js", true),
+			table.Entry("TypeScript test of a component",
+				"src/app/component.tsx", "src/app/component.test.tsx", true),
+			table.Entry("Python test located anywhere",
+				"lib/parser.py", "tests/test_parser.py", true),
+		)
+
+		It("should not find pairing rule for unknown language", func() {
+			// when
+			paired, applicable := defaultMatcher.PairsWithTest("lib/app.rb", []string{"spec/app_spec.rb"})
+
+			// then
+			Expect(applicable).To(BeFalse())
+			Expect(paired).To(BeFalse())
+		})
+	})
+
+	Context("Loading pairing rules from configuration", func() {
+
+		It("should prefer rules defined in configuration over predefined ones", func() {
+			// given
+			configuration := &PluginConfiguration{
+				Combine: true,
+				Pairing: []PairingRule{{
+					Production: "*.java",
+					Tests:      []string{"{name}Spec.groovy"},
+					PathMapping: []PathMapping{
+						{From: "src/main/java/", To: "src/test/groovy/"},
+					},
+				}},
+			}
+
+			// when
+			matcher, err := LoadMatcher(configuration)
+			paired, _ := matcher.PairsWithTest("src/main/java/org/acme/Bar.java",
+				[]string{"src/test/groovy/org/acme/BarSpec.groovy"})
+
+			// then
+			Ω(err).ShouldNot(HaveOccurred())
+			Expect(matcher.Pairing).To(HaveLen(len(defaultMatcher.Pairing) + 1))
+			Expect(paired).To(BeTrue())
+		})
+	})
+})
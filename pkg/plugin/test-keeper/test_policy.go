@@ -0,0 +1,171 @@
+package testkeeper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+)
+
+const (
+	// AnyTestPolicy is satisfied when there is at least one test in the PR (default)
+	AnyTestPolicy = "any"
+	// ProportionalPolicy requires the amount of tests in the PR to be proportional to the changes of the production code
+	ProportionalPolicy = "proportional"
+	// PairedPolicy requires every changed production file to come with its own test found using the pairing rules
+	PairedPolicy = "paired"
+	// CoveragePolicy requires production lines added in the PR to be covered by tests according to the coverage report
+	CoveragePolicy = "coverage"
+)
+
+// testPolicy decides if the PR comes with enough tests
+type testPolicy interface {
+	// evaluate checks the counted files and returns measurements the decision is based on (if any)
+	evaluate(fileCategories FileCategories) (testMeasurements, bool)
+}
+
+func newTestPolicy(configuration *PluginConfiguration, matcher TestMatcher, report *CoverageReport) testPolicy {
+	switch configuration.Policy {
+	case ProportionalPolicy:
+		return &proportionalPolicy{
+			minTestRatio:           configuration.MinTestRatio,
+			productionFilesPerTest: configuration.ProductionFilesPerTest,
+		}
+	case PairedPolicy:
+		return &pairedPolicy{matcher: matcher}
+	case CoveragePolicy:
+		return &coveragePolicy{report: report, minDiffCoverage: configuration.Coverage.MinDiffCoverage}
+	}
+	return anyTestPolicy{}
+}
+
+// verifier categorizes files using matcher and decides if they come with enough tests using policy
+type verifier struct {
+	matcher TestMatcher
+	policy  testPolicy
+}
+
+func newVerifier(configuration *PluginConfiguration, repositoryLanguages []string, changedFiles []scm.ChangedFile,
+	report *CoverageReport) (verifier, error) {
+	matcher, err := LoadMatcherFor(configuration, repositoryLanguages, changedFiles)
+	return verifier{matcher: matcher, policy: newTestPolicy(configuration, matcher, report)}, err
+}
+
+type anyTestPolicy struct{}
+
+func (anyTestPolicy) evaluate(fileCategories FileCategories) (testMeasurements, bool) {
+	return nil, fileCategories.TestsExist()
+}
+
+// proportionalPolicy requires the minimum ratio of lines added in tests to lines added in production files and/or
+// at least one test file for every productionFilesPerTest production files
+type proportionalPolicy struct {
+	minTestRatio           float64
+	productionFilesPerTest int
+}
+
+// evaluate measures configured ratios. When no production line has been added (e.g. the production code has been
+// only removed) there is nothing the tests could be proportional to, so it falls back to the default policy
+func (p *proportionalPolicy) evaluate(fileCategories FileCategories) (testMeasurements, bool) {
+	if fileCategories.ProductionAdditions == 0 {
+		return anyTestPolicy{}.evaluate(fileCategories)
+	}
+	var measurements testMeasurements
+	if p.minTestRatio > 0 {
+		measurements = append(measurements, testMeasurement{
+			name:      "test/production lines",
+			measured:  float64(fileCategories.TestAdditions) / float64(fileCategories.ProductionAdditions),
+			threshold: p.minTestRatio,
+		})
+	}
+	if p.productionFilesPerTest > 0 {
+		measurements = append(measurements, testMeasurement{
+			name:      "test/production files",
+			measured:  float64(fileCategories.Tests) / float64(len(fileCategories.Production)),
+			threshold: 1 / float64(p.productionFilesPerTest),
+		})
+	}
+	return measurements, len(measurements.unsatisfied()) == 0
+}
+
+// pairedPolicy requires every production file to be paired with one of the tests in the changeset. Files which no pairing
+// rule applies to are verified the same way as by the default policy - any test is enough for them
+type pairedPolicy struct {
+	matcher TestMatcher
+}
+
+func (p *pairedPolicy) evaluate(fileCategories FileCategories) (testMeasurements, bool) {
+	if len(fileCategories.Production) == 0 {
+		return anyTestPolicy{}.evaluate(fileCategories)
+	}
+	var unpaired []string
+	for _, file := range fileCategories.Production {
+		paired, applicable := p.matcher.PairsWithTest(file, fileCategories.TestFiles)
+		if !paired && (applicable || !fileCategories.TestsExist()) {
+			unpaired = append(unpaired, file)
+		}
+	}
+	measurements := testMeasurements{{
+		name:      "paired production files",
+		measured:  float64(len(fileCategories.Production)-len(unpaired)) / float64(len(fileCategories.Production)),
+		threshold: 1,
+		missing:   unpaired,
+	}}
+	return measurements, len(unpaired) == 0
+}
+
+// testMeasurement holds a ratio of tests to production changes measured in the changeset and its required minimum.
+// When the ratio is measured per production file, missing lists the files which come without a test. When it's measured
+// per added line, uncovered lists the lines which are not covered by tests
+type testMeasurement struct {
+	name                string
+	measured, threshold float64
+	missing             []string
+	uncovered           []uncoveredLines
+}
+
+func (m testMeasurement) satisfied() bool {
+	return m.measured >= m.threshold
+}
+
+func (m testMeasurement) String() string {
+	return fmt.Sprintf("%s %.2f (required %.2f)", m.name, m.measured, m.threshold)
+}
+
+type testMeasurements []testMeasurement
+
+func (m testMeasurements) unsatisfied() testMeasurements {
+	var unsatisfied testMeasurements
+	for _, measurement := range m {
+		if !measurement.satisfied() {
+			unsatisfied = append(unsatisfied, measurement)
+		}
+	}
+	return unsatisfied
+}
+
+// missing lists production files without a test collected from all the measurements
+func (m testMeasurements) missing() []string {
+	var missing []string
+	for _, measurement := range m {
+		missing = append(missing, measurement.missing...)
+	}
+	return missing
+}
+
+// uncovered lists lines added to production files which are not covered by tests collected from all the measurements
+func (m testMeasurements) uncovered() []uncoveredLines {
+	var uncovered []uncoveredLines
+	for _, measurement := range m {
+		uncovered = append(uncovered, measurement.uncovered...)
+	}
+	return uncovered
+}
+
+func (m testMeasurements) String() string {
+	descriptions := make([]string, 0, len(m))
+	for _, measurement := range m {
+		descriptions = append(descriptions, measurement.String())
+	}
+	return strings.Join(descriptions, ", ")
+}
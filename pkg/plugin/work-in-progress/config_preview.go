@@ -0,0 +1,93 @@
+package wip
+
+import (
+	"fmt"
+	"strings"
+
+	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/status/message"
+	gogh "github.com/google/go-github/github"
+)
+
+const (
+	// ConfigPreviewName is used in the title of the comment previewing the effect of the configuration changed in the PR
+	ConfigPreviewName = ProwPluginName + " configuration preview"
+
+	// ConfigPreviewMsg is a beginning of the comment previewing the effect of the configuration changed in the PR
+	ConfigPreviewMsg = "This PR changes the configuration of the plugin. Titles and labels of other open pull requests " +
+		"have been verified again using both the current configuration and the one coming with this PR."
+	// ConfigPreviewNoChangesMsg is a part of the preview comment used when none of the open pull requests is affected by the change
+	ConfigPreviewNoChangesMsg = "None of the %d open pull requests would be marked differently."
+	// ConfigPreviewChangesMsg is a part of the preview comment introducing the list of the affected open pull requests
+	ConfigPreviewChangesMsg = "Following pull requests (out of %d open ones) would be marked differently:\n"
+
+	inProgressState     = "work in progress"
+	readyForReviewState = "ready for review"
+)
+
+// previewConfiguration checks if the pull request changes the configuration of the plugin. If so, it verifies other
+// open pull requests using both the configuration of the base branch and the one coming with the pull request and
+// adds (or updates) the comment listing pull requests which would be marked differently. The preview is made only once
+// for each head commit of the pull request. Any failure is only logged as the preview is not essential for the verification of the PR
+func (gh *GitHubWIPPRHandler) previewConfiguration(logger log.Logger, pr *gogh.PullRequest, newConfig PluginConfiguration) {
+	change := ghservice.NewRepositoryChangeForPR(pr)
+	files, err := gh.Client.ListPullRequestFiles(change.Owner, change.RepoName, pr.GetNumber())
+	if err != nil {
+		logger.Errorf("failed to list files of the PR to find out if it changes the configuration. cause: %s", err)
+		return
+	}
+	if !ghservice.ChangesConfiguration(ProwPluginName, files) {
+		return
+	}
+
+	msgContext := message.NewStatusMessageContext(ConfigPreviewName, documentationSection, pr, &newConfig.PluginConfiguration)
+	msgService := message.NewStatusMessageService(gh.Client, logger, ghservice.NewIssueCommentsLazyLoader(gh.Client, pr), msgContext)
+	previewedCommit := fmt.Sprintf(message.PreviewedCommitTemplate, pr.GetHead().GetSHA())
+	if msgService.StatusMessageContains(previewedCommit) {
+		return
+	}
+
+	currentConfig, err := LoadConfiguration(logger, gh.Client, ghservice.NewRepositoryChangeForPRBase(pr))
+	if err != nil {
+		logger.Warnf("skipping preview of the configuration change, the current one cannot be loaded. cause: %s", err)
+		return
+	}
+	openPRs, err := gh.Client.ListOpenPullRequests(change.Owner, change.RepoName)
+	if err != nil {
+		logger.Errorf("failed to list open pull requests to preview configuration change. cause: %s", err)
+		return
+	}
+
+	var verified int
+	var lines []string
+	for _, openPR := range openPRs {
+		if openPR.GetNumber() == pr.GetNumber() {
+			continue
+		}
+		verified++
+		if before, after := gh.stateOf(openPR, currentConfig), gh.stateOf(openPR, newConfig); before != after {
+			lines = append(lines, fmt.Sprintf("* [#%d](%s) %s: %s → %s", openPR.GetNumber(), openPR.GetHTMLURL(),
+				openPR.GetTitle(), before, after))
+		}
+	}
+
+	err = msgService.StatusMessage(func() string {
+		if len(lines) == 0 {
+			return ConfigPreviewMsg + "\n\n" + fmt.Sprintf(ConfigPreviewNoChangesMsg, verified) + "\n\n" + previewedCommit
+		}
+		return ConfigPreviewMsg + "\n\n" + fmt.Sprintf(ConfigPreviewChangesMsg, verified) + strings.Join(lines, "\n") +
+			"\n\n" + previewedCommit
+	}, true)
+	if err != nil {
+		logger.Errorf("failed to comment configuration preview on PR [%q]. cause: %s", *pr, err)
+	}
+}
+
+// stateOf describes how the given pull request is marked using the given configuration
+func (gh *GitHubWIPPRHandler) stateOf(pr *gogh.PullRequest, config PluginConfiguration) string {
+	if _, prefixExists := GetWorkInProgressPrefix(pr.GetTitle(), config); prefixExists || gh.hasWorkInProgressLabel(pr.Labels, config.Label) {
+		return inProgressState
+	}
+	return readyForReviewState
+}
@@ -1,7 +1,12 @@
 package wip
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
 	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
 	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	"github.com/arquillian/ike-prow-plugins/pkg/scm"
@@ -19,18 +24,40 @@ type PluginConfiguration struct {
 // DefaultLabel is the GitHub label name set in absence of any configured label name
 const DefaultLabel = "work-in-progress"
 
-// LoadConfiguration loads a PluginConfiguration for the given change
-func LoadConfiguration(logger log.Logger, change scm.RepositoryChange) PluginConfiguration {
+// maxLabelLength is the maximum length of the name of GitHub label
+const maxLabelLength = 50
+
+// Validate checks that the title prefixes are not empty and can be used in regular expressions and that the label
+// name is accepted by GitHub
+func (c *PluginConfiguration) Validate() []config.FieldError {
+	fieldErrors := c.PluginConfiguration.Validate()
+	for _, prefix := range c.Prefix {
+		if strings.TrimSpace(prefix) == "" {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "title_prefixes", Message: "title prefix must not be empty"})
+		} else if _, err := regexp.Compile(fmt.Sprintf(prefixPattern, prefix)); err != nil {
+			fieldErrors = append(fieldErrors, config.FieldError{Field: "title_prefixes", Value: prefix,
+				Message: fmt.Sprintf("title prefix `%s` is not a valid regular expression: %s", prefix, err)})
+		}
+	}
+	if len(c.Label) > maxLabelLength {
+		fieldErrors = append(fieldErrors, config.FieldError{Field: "gh_label",
+			Message: fmt.Sprintf("label name must not be longer than %d characters", maxLabelLength)})
+	}
+	return fieldErrors
+}
+
+// LoadConfiguration loads a PluginConfiguration for the given change. Defaults are used when there is no configuration
+// file in the repository. When the file exists, but cannot be loaded, the error is returned so it can be reported
+func LoadConfiguration(logger log.Logger, client ghclient.Client, change scm.RepositoryChange) (PluginConfiguration, error) {
 
 	configuration := PluginConfiguration{Combine: true, Label: DefaultLabel}
-	loadableConfig := &ghservice.LoadableConfig{PluginName: ProwPluginName, Change: change, BaseConfig: &configuration.PluginConfiguration}
+	loadableConfig := &ghservice.LoadableConfig{Client: client, PluginName: ProwPluginName, Change: change, BaseConfig: &configuration.PluginConfiguration}
 
-	err := config.Load(&configuration, loadableConfig)
+	err := config.LoadLayers(&configuration, loadableConfig)
 
 	if err != nil {
 		logger.Errorf("Config file was not loaded. Cause: %s", err)
-		return configuration
 	}
 
-	return configuration
+	return configuration, err
 }
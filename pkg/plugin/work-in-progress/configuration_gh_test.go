@@ -39,9 +39,10 @@ var _ = Describe("Work In Progress config loader features", func() {
 				ToChange(change)
 
 			// when
-			configuration := wip.LoadConfiguration(logger, change)
+			configuration, err := wip.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
 
 			// then
+			Ω(err).ShouldNot(HaveOccurred())
 			Expect(configuration.Prefix).To(ConsistOf("[work in progress]", "work in progress"))
 			Expect(configuration.Combine).To(Equal(true))
 			Expect(configuration.Label).To(Equal("working-in-progress"))
@@ -58,9 +59,10 @@ var _ = Describe("Work In Progress config loader features", func() {
 			}
 
 			// when
-			configuration := wip.LoadConfiguration(logger, change)
+			configuration, err := wip.LoadConfiguration(logger, NewDefaultGitHubClient(), change)
 
 			// then
+			Ω(err).ShouldNot(HaveOccurred())
 			Expect(configuration.Prefix).To(BeEmpty())
 			Expect(configuration.Combine).To(Equal(true))
 			Expect(configuration.Label).To(Equal("work-in-progress"))
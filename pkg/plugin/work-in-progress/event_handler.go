@@ -11,6 +11,7 @@ import (
 	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	"github.com/arquillian/ike-prow-plugins/pkg/status"
+	"github.com/arquillian/ike-prow-plugins/pkg/status/message"
 	"github.com/arquillian/ike-prow-plugins/pkg/utils"
 	gogh "github.com/google/go-github/github"
 )
@@ -28,6 +29,8 @@ const (
 	ReadyForReviewMessage = "PR is ready for review and merge"
 	// ReadyForReviewDetailsPageName is a name of a documentation page that contains additional status details for ReadyForReviewMessage
 	ReadyForReviewDetailsPageName = "wip-success"
+
+	documentationSection = "#_work_in_progress_plugin"
 )
 
 // GitHubWIPPRHandler handles PR events and updates status of the PR based on work-in-progress indicator
@@ -40,6 +43,8 @@ var (
 	handledCommentActions = []string{"created", "edited"}
 	handledPrActions      = []string{"opened", "reopened", "edited", "synchronize", "labeled", "unlabeled"}
 	defaultPrefixes       = []string{"WIP", "DO NOT MERGE", "DON'T MERGE", "WORK-IN-PROGRESS"}
+	// previewedPrActions are the actions which may bring a change of the configuration to preview
+	previewedPrActions = []string{"opened", "reopened", "synchronize"}
 )
 
 // HandlePullRequestEvent is an entry point for the plugin logic. This method is invoked by the Server when
@@ -51,9 +56,9 @@ func (gh *GitHubWIPPRHandler) HandlePullRequestEvent(logger log.Logger, event *g
 
 	switch *event.Action {
 	case github.ActionLabeled, github.ActionUnlabeled:
-		return gh.checkComponentsAndSetStatus(logger, event.PullRequest, true)
+		return gh.checkComponentsAndSetStatus(logger, event.PullRequest, true, false)
 	default:
-		return gh.checkComponentsAndSetStatus(logger, event.PullRequest, false)
+		return gh.checkComponentsAndSetStatus(logger, event.PullRequest, false, utils.Contains(previewedPrActions, *event.Action))
 	}
 }
 
@@ -77,7 +82,7 @@ func (gh *GitHubWIPPRHandler) HandleIssueCommentEvent(logger log.Logger, comment
 				return err
 			}
 
-			return gh.checkComponentsAndSetStatus(logger, pullRequest, false)
+			return gh.checkComponentsAndSetStatus(logger, pullRequest, false, false)
 
 		}})
 
@@ -88,12 +93,21 @@ func (gh *GitHubWIPPRHandler) HandleIssueCommentEvent(logger log.Logger, comment
 	return err
 }
 
-func (gh *GitHubWIPPRHandler) checkComponentsAndSetStatus(logger log.Logger, pullRequest *gogh.PullRequest, labelUpdated bool) error {
+func (gh *GitHubWIPPRHandler) checkComponentsAndSetStatus(logger log.Logger, pullRequest *gogh.PullRequest,
+	labelUpdated, previewConfig bool) error {
 	change := ghservice.NewRepositoryChangeForPR(pullRequest)
 	statusContext := github.StatusContext{BotName: gh.BotName, PluginName: ProwPluginName}
-	statusService := status.NewStatusService(gh.Client, logger, change, statusContext)
+	configuration, err := LoadConfiguration(logger, gh.Client, change)
+	statusService := status.NewConfiguredStatusService(gh.Client, logger, change, statusContext, &configuration.PluginConfiguration)
+	if err != nil {
+		msgContext := message.NewStatusMessageContext(ProwPluginName, documentationSection, pullRequest, &configuration.PluginConfiguration)
+		msgService := message.NewStatusMessageService(gh.Client, logger, ghservice.NewIssueCommentsLazyLoader(gh.Client, pullRequest), msgContext)
+		return status.ReportConfigError(statusService, msgService, logger, err)
+	}
+	if previewConfig {
+		gh.previewConfiguration(logger, pullRequest, configuration)
+	}
 
-	configuration := LoadConfiguration(logger, change)
 	labelExists := gh.hasWorkInProgressLabel(pullRequest.Labels, configuration.Label)
 	prefix, prefixExists := GetWorkInProgressPrefix(*pullRequest.Title, configuration)
 
@@ -144,10 +158,12 @@ func GetWorkInProgressPrefix(title string, config PluginConfiguration) (string,
 	return getPrefix(title, prefixes)
 }
 
+// prefixPattern is a regular expression matching the title starting with the given "work in progress" prefix
+const prefixPattern = `(?mi)^(\[|\(|\{|\<|\>|\*|#|!|\"|-|_| )*%s(\]|\)|\}|\>|\<|\*|#|!|\"|-|_| )*(:| )+`
+
 func getPrefix(title string, prefixes []string) (string, bool) {
 	for _, prefix := range prefixes {
-		pattern := `(?mi)^(\[|\(|\{|\<|\>|\*|#|!|\"|-|_| )*` + prefix + `(\]|\)|\}|\>|\<|\*|#|!|\"|-|_| )*(:| )+`
-		r, err := regexp.Compile(pattern)
+		r, err := regexp.Compile(fmt.Sprintf(prefixPattern, prefix))
 		if err != nil {
 			continue
 		}
@@ -1,6 +1,9 @@
 package wip_test
 
 import (
+	"fmt"
+	"strconv"
+
 	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	wip "github.com/arquillian/ike-prow-plugins/pkg/plugin/work-in-progress"
@@ -11,6 +14,7 @@ import (
 	"github.com/arquillian/ike-prow-plugins/pkg/command"
 	"github.com/arquillian/ike-prow-plugins/pkg/github"
 	testkeeper "github.com/arquillian/ike-prow-plugins/pkg/plugin/test-keeper"
+	"github.com/arquillian/ike-prow-plugins/pkg/status/message"
 )
 
 const botName = "alien-ike"
@@ -79,6 +83,7 @@ var _ = Describe("Work In Progress Plugin features", func() {
 		It("should mark opened PR as ready for review if not prefixed with WIP", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle("feat: introduces dummy response").
 				WithoutConfigFiles().
 				WithoutLabels().
@@ -96,6 +101,7 @@ var _ = Describe("Work In Progress Plugin features", func() {
 		It("should mark opened PR as work-in-progress when prefixed with WIP", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle("WIP feat: introduces dummy response").
 				WithoutConfigFiles().
 				WithoutLabels().
@@ -114,6 +120,7 @@ var _ = Describe("Work In Progress Plugin features", func() {
 		It("should mark opened PR as work-in-progress when title starts with configured prefix", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithoutFiles().
 				WithTitle("WORK IN PROGRESS: configures plugin").
 				WithConfigFile(ConfigYml(LoadedFrom("test_fixtures/github_calls/work-in-progress.yml"))).
 				WithoutLabels().
@@ -129,6 +136,61 @@ var _ = Describe("Work In Progress Plugin features", func() {
 			Ω(err).ShouldNot(HaveOccurred())
 		})
 
+		It("should list open pull requests marked differently using the configuration changed in the PR", func() {
+			// given
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(`[{"filename": ".ike-prow/work-in-progress.yml", "status": "added"}]`).
+				WithTitle("chore: configures plugin").
+				WithConfigFile(ConfigYml(LoadedFrom("test_fixtures/github_calls/work-in-progress.yml"))).
+				WithoutBaseConfigFiles().
+				WithoutLabels().
+				WithOpenPullRequestsContaining(`[
+					{"number": 4, "title": "chore: configures plugin"},
+					{"number": 5, "title": "work in progress: adds docs",
+					 "html_url": "https://github.com/bartoszmajsak/wfswarm-booster-pipeline-test/pull/5"},
+					{"number": 6, "title": "feat: adds tests", "labels": [{"name": "work-in-progress"}],
+					 "html_url": "https://github.com/bartoszmajsak/wfswarm-booster-pipeline-test/pull/6"},
+					{"number": 7, "title": "WIP fix: corrects typo"}]`).
+				WithoutComments().
+				Expecting(
+					Status(toHaveSuccessState),
+					Comment(To(
+						HaveBodyThatContains(fmt.Sprintf(message.PluginTitleTemplate, wip.ConfigPreviewName)),
+						HaveBodyThatContains(fmt.Sprintf(wip.ConfigPreviewChangesMsg, 3)),
+						HaveBodyThatContains("work in progress: adds docs: ready for review → work in progress"),
+						HaveBodyThatContains("feat: adds tests: work in progress → ready for review"),
+						HaveBodyThatContains(fmt.Sprintf(message.PreviewedCommitTemplate, "6582335bd87edd6b1fa32e32d566fbdf6c2fa579"))))).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("opened"))
+
+			// then - implicit verification of /statuses call occurrence with proper payload
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
+		It("should not preview the configuration changed in the PR again for the same head commit", func() {
+			// given
+			previewComment := fmt.Sprintf(message.PluginTitleTemplate, wip.ConfigPreviewName) + "\n\n" +
+				fmt.Sprintf(wip.ConfigPreviewNoChangesMsg, 3) + "\n\n" +
+				fmt.Sprintf(message.PreviewedCommitTemplate, "6582335bd87edd6b1fa32e32d566fbdf6c2fa579")
+			prMock := mocker.MockPr().LoadedFromDefaultJSON().
+				WithFiles(`[{"filename": ".ike-prow/work-in-progress.yml", "status": "added"}]`).
+				WithTitle("chore: configures plugin").
+				WithConfigFile(ConfigYml(LoadedFrom("test_fixtures/github_calls/work-in-progress.yml"))).
+				WithoutLabels().
+				WithComments(`[{"id": 1, "body": ` + strconv.Quote(previewComment) + `}]`).
+				Expecting(
+					Status(toHaveSuccessState)).
+				Create()
+
+			// when
+			err := handler.HandlePullRequestEvent(log, prMock.CreatePullRequestEvent("reopened"))
+
+			// then - implicit verification that neither open pull requests nor the base configuration have been loaded
+			Ω(err).ShouldNot(HaveOccurred())
+		})
+
 		It("should mark status as failed (thus block PR merge) when title updated to contain WIP", func() {
 			// given
 			prMock := mocker.MockPr().LoadedFromDefaultJSON().
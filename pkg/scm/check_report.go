@@ -0,0 +1,34 @@
+package scm
+
+// Annotation levels used to mark the severity of CheckAnnotation
+const (
+	AnnotationNotice  = "notice"
+	AnnotationWarning = "warning"
+	AnnotationFailure = "failure"
+)
+
+// CheckReport holds detailed outcome of the check which can be published along with the status of the change
+// (e.g. as a GitHub check run)
+type CheckReport struct {
+	Title       string
+	Summary     string
+	Annotations []CheckAnnotation
+	Actions     []CheckAction
+}
+
+// CheckAnnotation points to a file (and optionally its lines) which is related to the outcome of the check
+type CheckAnnotation struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Level     string
+	Title     string
+	Message   string
+}
+
+// CheckAction is an action which can be requested by the user directly from the check report
+type CheckAction struct {
+	Label       string
+	Description string
+	Identifier  string
+}
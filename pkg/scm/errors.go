@@ -0,0 +1,19 @@
+package scm
+
+import "fmt"
+
+// NotFoundError indicates that the requested resource (such as a file) does not exist in the repository.
+// It lets callers tell a missing resource apart from a failure while retrieving it.
+type NotFoundError struct {
+	Resource string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s not found", e.Resource)
+}
+
+// IsNotFound checks if the given error is NotFoundError
+func IsNotFound(err error) bool {
+	_, notFound := err.(*NotFoundError)
+	return notFound
+}
@@ -0,0 +1,62 @@
+package scm
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// AddedLines returns numbers of the lines (in the new version of the file) which have been added or modified by the change.
+// The lines are parsed from hunks of the unified diff held in Patch, so nothing is returned when the patch is not available
+// (e.g. for binary files or too large diffs)
+func (f *ChangedFile) AddedLines() []int {
+	var added []int
+	f.forEachHunkLine(func(line int, kind byte, content string) {
+		if kind == '+' {
+			added = append(added, line)
+		}
+	})
+	return added
+}
+
+// PatchLines returns contents of the lines added and removed by the change (without the leading + and - signs)
+// as parsed from hunks of the unified diff held in Patch
+func (f *ChangedFile) PatchLines() (added, removed []string) {
+	f.forEachHunkLine(func(line int, kind byte, content string) {
+		switch kind {
+		case '+':
+			added = append(added, content)
+		case '-':
+			removed = append(removed, content)
+		}
+	})
+	return added, removed
+}
+
+// forEachHunkLine calls the given function for every line of the hunks of the patch with the number of the line in the new
+// version of the file, its kind (+ for added, - for removed and space for context lines) and its content
+func (f *ChangedFile) forEachHunkLine(consume func(line int, kind byte, content string)) {
+	line, inHunk := 0, false
+	for _, patchLine := range strings.Split(f.Patch, "\n") {
+		if header := hunkHeader.FindStringSubmatch(patchLine); header != nil {
+			line, _ = strconv.Atoi(header[1])
+			inHunk = true
+			continue
+		}
+		if !inHunk || patchLine == "" || patchLine[0] == '\\' {
+			continue
+		}
+		switch patchLine[0] {
+		case '+':
+			consume(line, '+', patchLine[1:])
+			line++
+		case '-':
+			consume(line, '-', patchLine[1:])
+		default:
+			consume(line, ' ', patchLine[1:])
+			line++
+		}
+	}
+}
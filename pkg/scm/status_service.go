@@ -7,3 +7,9 @@ type StatusService interface {
 	Pending(reason string) error
 	Error(reason string) error
 }
+
+// ReportingStatusService is a StatusService which is able to publish detailed CheckReport along with the status
+type ReportingStatusService interface {
+	StatusService
+	WithReport(report CheckReport) StatusService
+}
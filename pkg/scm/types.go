@@ -18,12 +18,15 @@ func NewRepositoryIssue(owner, repoName string, number int) *RepositoryIssue {
 	}
 }
 
-// ChangedFile is a type that contains information about created/modified/removed file within an scm repository
+// ChangedFile is a type that contains information about created/modified/removed file within an scm repository.
+// Patch holds the unified diff of the file (if available) and PreviousFilename the name of the file before it was renamed
 type ChangedFile struct {
-	Name      string
-	Status    string
-	Additions int
-	Deletions int
+	Name             string
+	PreviousFilename string
+	Status           string
+	Additions        int
+	Deletions        int
+	Patch            string
 }
 
 // RepositoryChange holds information about owner and repository to which the change indicated by Hash belongs
@@ -36,9 +39,11 @@ type RepositoryChange struct {
 // NewChangedFile maps the fields and returns the new struct
 func NewChangedFile(file *gogh.CommitFile) *ChangedFile {
 	return &ChangedFile{
-		Name:      *file.Filename,
-		Status:    *file.Status,
-		Additions: *file.Additions,
-		Deletions: *file.Deletions,
+		Name:             *file.Filename,
+		PreviousFilename: file.GetPreviousFilename(),
+		Status:           *file.Status,
+		Additions:        *file.Additions,
+		Deletions:        *file.Deletions,
+		Patch:            file.GetPatch(),
 	}
 }
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+)
+
+var (
+	// ErrEventQueueFull is returned when the event cannot be enqueued because there is no more room left in the queue
+	ErrEventQueueFull = errors.New("event queue is full")
+	// ErrEventQueueClosed is returned when the event is enqueued after the queue has been shut down
+	ErrEventQueueClosed = errors.New("event queue is closed")
+)
+
+// EventQueue is a bounded in-memory queue drained by a pool of workers. Events sharing the same key (e.g. referring
+// to the same pull request) are always handled by the same worker in the order they were enqueued, so they are never
+// processed concurrently. The bound is shared by all the workers, so events of a single busy key can take the whole
+// queue while other workers are idle.
+type EventQueue struct {
+	workers []chan func()
+	slots   chan struct{}
+	wg      sync.WaitGroup
+	mutex   sync.RWMutex
+	closed  bool
+}
+
+// NewEventQueue creates the queue holding at most size events and starts the given number of workers draining it
+func NewEventQueue(workers, size int) *EventQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	if size < 1 {
+		size = 1
+	}
+
+	// each worker can hold all the queued events, the shared bound is kept by the slots taken for the waiting ones
+	queue := &EventQueue{workers: make([]chan func(), workers), slots: make(chan struct{}, size)}
+	for i := range queue.workers {
+		events := make(chan func(), size)
+		queue.workers[i] = events
+		queue.wg.Add(1)
+		go func() {
+			defer queue.wg.Done()
+			for handle := range events {
+				<-queue.slots
+				handle()
+			}
+		}()
+	}
+
+	return queue
+}
+
+// Enqueue puts handling of the event identified by the key in the queue. It does not block - if there is no room left
+// for the event ErrEventQueueFull is returned.
+func (q *EventQueue) Enqueue(key string, handle func()) error {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	if q.closed {
+		return ErrEventQueueClosed
+	}
+
+	select {
+	case q.slots <- struct{}{}:
+		q.workers[q.workerIndex(key)] <- handle
+		return nil
+	default:
+		return ErrEventQueueFull
+	}
+}
+
+// Shutdown stops accepting new events and waits until all already enqueued events are handled or the context is done
+func (q *EventQueue) Shutdown(ctx context.Context) error {
+	q.mutex.Lock()
+	if !q.closed {
+		q.closed = true
+		for _, events := range q.workers {
+			close(events)
+		}
+	}
+	q.mutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *EventQueue) workerIndex(key string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(len(q.workers)))
+}
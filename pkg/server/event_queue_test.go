@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/server"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Event queue", func() {
+
+	It("should handle events with the same key sequentially in the order they were enqueued", func() {
+		// given
+		queue := server.NewEventQueue(4, 20)
+		var (
+			mutex   sync.Mutex
+			handled []int
+			running int
+			overlap bool
+		)
+		handle := func(i int) func() {
+			return func() {
+				mutex.Lock()
+				running++
+				overlap = overlap || running > 1
+				mutex.Unlock()
+				time.Sleep(time.Millisecond)
+				mutex.Lock()
+				running--
+				handled = append(handled, i)
+				mutex.Unlock()
+			}
+		}
+
+		// when
+		for i := 0; i < 5; i++ {
+			Ω(queue.Enqueue("owner/repo#1", handle(i))).Should(Succeed())
+		}
+		Ω(queue.Shutdown(context.Background())).Should(Succeed())
+
+		// then
+		Expect(overlap).To(BeFalse())
+		Expect(handled).To(Equal([]int{0, 1, 2, 3, 4}))
+	})
+
+	It("should reject event when there is no room left in the queue", func() {
+		// given
+		queue := server.NewEventQueue(1, 1)
+		release := make(chan struct{})
+		started := make(chan struct{})
+		Ω(queue.Enqueue("owner/repo#1", func() {
+			close(started)
+			<-release
+		})).Should(Succeed())
+		<-started
+		Ω(queue.Enqueue("owner/repo#1", func() {})).Should(Succeed())
+
+		// when
+		err := queue.Enqueue("owner/repo#1", func() {})
+
+		// then
+		Ω(err).Should(Equal(server.ErrEventQueueFull))
+		close(release)
+		Ω(queue.Shutdown(context.Background())).Should(Succeed())
+	})
+
+	It("should let events with the same key take the whole queue shared by the workers", func() {
+		// given
+		queue := server.NewEventQueue(4, 4)
+		release := make(chan struct{})
+		started := make(chan struct{})
+		Ω(queue.Enqueue("owner/repo#1", func() {
+			close(started)
+			<-release
+		})).Should(Succeed())
+		<-started
+
+		// when
+		for i := 0; i < 4; i++ {
+			Ω(queue.Enqueue("owner/repo#1", func() {})).Should(Succeed())
+		}
+		err := queue.Enqueue("owner/repo#2", func() {})
+
+		// then
+		Ω(err).Should(Equal(server.ErrEventQueueFull))
+		close(release)
+		Ω(queue.Shutdown(context.Background())).Should(Succeed())
+	})
+
+	It("should handle all queued events before shutdown completes", func() {
+		// given
+		queue := server.NewEventQueue(2, 20)
+		var (
+			mutex   sync.Mutex
+			handled int
+		)
+		for i := 0; i < 6; i++ {
+			Ω(queue.Enqueue("owner/repo#"+strconv.Itoa(i), func() {
+				time.Sleep(time.Millisecond)
+				mutex.Lock()
+				handled++
+				mutex.Unlock()
+			})).Should(Succeed())
+		}
+
+		// when
+		err := queue.Shutdown(context.Background())
+
+		// then
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(handled).To(Equal(6))
+		Ω(queue.Enqueue("owner/repo#1", func() {})).Should(Equal(server.ErrEventQueueClosed))
+	})
+
+	It("should stop waiting for queued events when context is done", func() {
+		// given
+		queue := server.NewEventQueue(1, 1)
+		release := make(chan struct{})
+		defer close(release)
+		Ω(queue.Enqueue("owner/repo#1", func() { <-release })).Should(Succeed())
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		// when
+		err := queue.Shutdown(ctx)
+
+		// then
+		Ω(err).Should(Equal(context.DeadlineExceeded))
+	})
+})
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
 	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	"github.com/prometheus/client_golang/prometheus"
@@ -19,12 +20,16 @@ var (
 		Name: "handled_events_total",
 		Help: "Total number of handled events.",
 	}, []string{"event_type"})
+	pluginHandledEventsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "plugin_handled_events_total",
+		Help: "Total number of events handled by the given plugin.",
+	}, []string{"plugin", "event_type", "result"})
 	ghClient ghclient.Client
 )
 
 // RegisterMetrics registers prometheus collectors to collect metrics
 func RegisterMetrics(client ghclient.Client) []error {
-	errors := make([]error, 0, 3)
+	errors := make([]error, 0, 4)
 	ghClient = client
 	RegisterOrAssignCollector(rateLimit, &errors, func(collector prometheus.Collector) {
 		rateLimit = collector.(*prometheus.GaugeVec)
@@ -38,6 +43,10 @@ func RegisterMetrics(client ghclient.Client) []error {
 		handledEventsCounter = collector.(*prometheus.CounterVec)
 	})
 
+	RegisterOrAssignCollector(pluginHandledEventsCounter, &errors, func(collector prometheus.Collector) {
+		pluginHandledEventsCounter = collector.(*prometheus.CounterVec)
+	})
+
 	return errors
 }
 
@@ -79,6 +88,18 @@ func reportHandledEvents(l log.Logger, label string) {
 	}
 }
 
+func reportPluginHandledEvent(l log.Logger, pluginName string, eventType github.EventType, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	if counter, e := pluginHandledEventsCounter.GetMetricWithLabelValues(pluginName, string(eventType), result); e != nil {
+		l.Errorf("Failed to get metric for Plugin: %q and Event: %q. Cause: %q", pluginName, eventType, e)
+	} else {
+		counter.Inc()
+	}
+}
+
 // RateLimitWithLabelValues replaces the method of the same name in MetricVec.
 func RateLimitWithLabelValues(lvs ...string) (prometheus.Gauge, error) {
 	return rateLimit.GetMetricWithLabelValues(lvs...)
@@ -94,6 +115,11 @@ func HandledEventsCounterWithLabelValues(lvs ...string) (prometheus.Counter, err
 	return handledEventsCounter.GetMetricWithLabelValues(lvs...)
 }
 
+// PluginHandledEventsCounterWithLabelValues replaces the method of the same name in MetricVec.
+func PluginHandledEventsCounterWithLabelValues(lvs ...string) (prometheus.Counter, error) {
+	return pluginHandledEventsCounter.GetMetricWithLabelValues(lvs...)
+}
+
 // UnRegisterAndResetMetrics unregisters and reset prometheus collectors.
 func UnRegisterAndResetMetrics() {
 	prometheus.Unregister(webHookCounter)
@@ -102,4 +128,6 @@ func UnRegisterAndResetMetrics() {
 	rateLimit.Reset()
 	prometheus.Unregister(handledEventsCounter)
 	handledEventsCounter.Reset()
+	prometheus.Unregister(pluginHandledEventsCounter)
+	pluginHandledEventsCounter.Reset()
 }
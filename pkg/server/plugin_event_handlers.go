@@ -0,0 +1,86 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	gogh "github.com/google/go-github/github"
+)
+
+// PluginEventHandler binds GitHubEventHandler together with the name of the plugin it implements
+type PluginEventHandler struct {
+	PluginName string
+	GitHubEventHandler
+}
+
+// PluginEventHandlers is a GitHubEventHandler which dispatches every incoming event to all of the plugin handlers
+// it consists of. Failure of one plugin does not prevent the event from being handled by the remaining ones.
+type PluginEventHandlers []PluginEventHandler
+
+// HandlePullRequestEvent passes the event to every plugin handler
+func (h PluginEventHandlers) HandlePullRequestEvent(logger log.Logger, event *gogh.PullRequestEvent) error {
+	return h.dispatch(logger, github.PullRequest, func(handler GitHubEventHandler) error {
+		return handler.HandlePullRequestEvent(logger, event)
+	})
+}
+
+// HandleIssueCommentEvent passes the event to every plugin handler
+func (h PluginEventHandlers) HandleIssueCommentEvent(logger log.Logger, event *gogh.IssueCommentEvent) error {
+	return h.dispatch(logger, github.IssueComment, func(handler GitHubEventHandler) error {
+		return handler.HandleIssueCommentEvent(logger, event)
+	})
+}
+
+// HandleCheckRunEvent passes the event to every plugin handler which implements CheckRunEventHandler
+func (h PluginEventHandlers) HandleCheckRunEvent(logger log.Logger, event *gogh.CheckRunEvent) error {
+	var checkRunHandlers PluginEventHandlers
+	for _, handler := range h {
+		if _, ok := handler.GitHubEventHandler.(CheckRunEventHandler); ok {
+			checkRunHandlers = append(checkRunHandlers, handler)
+		}
+	}
+	return checkRunHandlers.dispatch(logger, github.CheckRun, func(handler GitHubEventHandler) error {
+		return handler.(CheckRunEventHandler).HandleCheckRunEvent(logger, event)
+	})
+}
+
+// HandleStatusEvent passes the event to every plugin handler which implements StatusEventHandler
+func (h PluginEventHandlers) HandleStatusEvent(logger log.Logger, event *gogh.StatusEvent) error {
+	var statusHandlers PluginEventHandlers
+	for _, handler := range h {
+		if _, ok := handler.GitHubEventHandler.(StatusEventHandler); ok {
+			statusHandlers = append(statusHandlers, handler)
+		}
+	}
+	return statusHandlers.dispatch(logger, github.Status, func(handler GitHubEventHandler) error {
+		return handler.(StatusEventHandler).HandleStatusEvent(logger, event)
+	})
+}
+
+// PluginNames returns names of all the plugins the handler consists of
+func (h PluginEventHandlers) PluginNames() []string {
+	names := make([]string, 0, len(h))
+	for _, handler := range h {
+		names = append(names, handler.PluginName)
+	}
+	return names
+}
+
+func (h PluginEventHandlers) dispatch(logger log.Logger, eventType github.EventType, handle func(handler GitHubEventHandler) error) error {
+	var failedPlugins []string
+	for _, handler := range h {
+		err := handle(handler.GitHubEventHandler)
+		reportPluginHandledEvent(logger, handler.PluginName, eventType, err)
+		if err != nil {
+			logger.Errorf("plugin %q failed while handling %q event. cause: %q", handler.PluginName, eventType, err)
+			failedPlugins = append(failedPlugins, handler.PluginName)
+		}
+	}
+
+	if len(failedPlugins) > 0 {
+		return fmt.Errorf("handling of %q event failed for plugins: %s", eventType, strings.Join(failedPlugins, ", "))
+	}
+	return nil
+}
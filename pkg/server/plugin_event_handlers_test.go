@@ -0,0 +1,116 @@
+package server_test
+
+import (
+	"errors"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
+	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/server"
+	gogh "github.com/google/go-github/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type RecordingGHEventHandler struct {
+	handledEvents []string
+	err           error
+}
+
+func (gh *RecordingGHEventHandler) HandlePullRequestEvent(logger log.Logger, event *gogh.PullRequestEvent) error {
+	gh.handledEvents = append(gh.handledEvents, string(github.PullRequest))
+	return gh.err
+}
+
+func (gh *RecordingGHEventHandler) HandleIssueCommentEvent(logger log.Logger, event *gogh.IssueCommentEvent) error {
+	gh.handledEvents = append(gh.handledEvents, string(github.IssueComment))
+	return gh.err
+}
+
+type RecordingStatusEventHandler struct {
+	RecordingGHEventHandler
+}
+
+func (gh *RecordingStatusEventHandler) HandleStatusEvent(logger log.Logger, event *gogh.StatusEvent) error {
+	gh.handledEvents = append(gh.handledEvents, string(github.Status))
+	return gh.err
+}
+
+var _ = Describe("Plugin event handlers", func() {
+
+	var (
+		testKeeper, wip *RecordingGHEventHandler
+		handlers        server.PluginEventHandlers
+	)
+
+	BeforeEach(func() {
+		server.RegisterMetrics(NewDefaultGitHubClient())
+		testKeeper = &RecordingGHEventHandler{}
+		wip = &RecordingGHEventHandler{}
+		handlers = server.PluginEventHandlers{
+			{PluginName: "test-keeper", GitHubEventHandler: testKeeper},
+			{PluginName: "work-in-progress", GitHubEventHandler: wip},
+		}
+	})
+
+	AfterEach(func() {
+		server.UnRegisterAndResetMetrics()
+	})
+
+	It("should dispatch pull request event to all plugins", func() {
+		// when
+		err := handlers.HandlePullRequestEvent(log.NewTestLogger(), &gogh.PullRequestEvent{})
+
+		// then
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(testKeeper.handledEvents).To(ConsistOf(string(github.PullRequest)))
+		Expect(wip.handledEvents).To(ConsistOf(string(github.PullRequest)))
+	})
+
+	It("should dispatch issue comment event to remaining plugins when one of them fails", func() {
+		// given
+		testKeeper.err = errors.New("boom")
+
+		// when
+		err := handlers.HandleIssueCommentEvent(log.NewTestLogger(), &gogh.IssueCommentEvent{})
+
+		// then
+		Ω(err).Should(MatchError(ContainSubstring("test-keeper")))
+		Expect(wip.handledEvents).To(ConsistOf(string(github.IssueComment)))
+	})
+
+	It("should count handled events per plugin and result", func() {
+		// given
+		wip.err = errors.New("boom")
+
+		// when
+		_ = handlers.HandlePullRequestEvent(log.NewTestLogger(), &gogh.PullRequestEvent{})
+
+		// then
+		succeeded, err := server.PluginHandledEventsCounterWithLabelValues("test-keeper", string(github.PullRequest), "success")
+		Ω(err).ShouldNot(HaveOccurred())
+		verifyCount(succeeded, 1)
+
+		failed, err := server.PluginHandledEventsCounterWithLabelValues("work-in-progress", string(github.PullRequest), "error")
+		Ω(err).ShouldNot(HaveOccurred())
+		verifyCount(failed, 1)
+	})
+
+	It("should dispatch status event only to plugins handling it", func() {
+		// given
+		testKeeperWithStatus := &RecordingStatusEventHandler{}
+		handlers[0].GitHubEventHandler = testKeeperWithStatus
+
+		// when
+		err := handlers.HandleStatusEvent(log.NewTestLogger(), &gogh.StatusEvent{})
+
+		// then
+		Ω(err).ShouldNot(HaveOccurred())
+		Expect(testKeeperWithStatus.handledEvents).To(ConsistOf(string(github.Status)))
+		Expect(wip.handledEvents).To(BeEmpty())
+	})
+
+	It("should list names of the plugins", func() {
+		Expect(handlers.PluginNames()).To(Equal([]string{"test-keeper", "work-in-progress"}))
+	})
+})
@@ -1,11 +1,14 @@
 package server
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"encoding/json"
 
 	"github.com/arquillian/ike-prow-plugins/pkg/github"
+	"github.com/arquillian/ike-prow-plugins/pkg/journal"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	gogh "github.com/google/go-github/github"
 	"github.com/sirupsen/logrus" //nolint:depguard
@@ -19,12 +22,36 @@ type GitHubEventHandler interface {
 	HandleIssueCommentEvent(logger log.Logger, event *gogh.IssueCommentEvent) error
 }
 
+// CheckRunEventHandler is implemented by GitHubEventHandler which also reacts on check run events,
+// such as actions requested by the user from the check run created by the plugin
+type CheckRunEventHandler interface {
+	HandleCheckRunEvent(logger log.Logger, event *gogh.CheckRunEvent) error
+}
+
+// StatusEventHandler is implemented by GitHubEventHandler which also reacts on commit statuses set by other parties,
+// such as CI publishing results of the build
+type StatusEventHandler interface {
+	HandleStatusEvent(logger log.Logger, event *gogh.StatusEvent) error
+}
+
+// InstallationRegistry keeps track of GitHub App installations the incoming events are sent by, so the requests
+// related to the event can be authenticated as the right installation
+type InstallationRegistry interface {
+	RegisterInstallation(account string, installationID int64)
+}
+
 // Server implements http.Handler. It validates incoming GitHub webhooks and
-// then dispatches them to the appropriate plugins.
+// then dispatches them to the appropriate plugins. When Queue is set events are handled asynchronously
+// and the webhook is answered with 202 Accepted right after it has been enqueued. When Journal is set every
+// validated event is persisted, so it can be replayed later. When Installations is set the GitHub App installation
+// of every validated event is registered in it.
 type Server struct {
 	GitHubEventHandler GitHubEventHandler
 	HmacSecret         []byte
 	PluginName         string
+	Queue              *EventQueue
+	Journal            journal.Appender
+	Installations      InstallationRegistry
 }
 
 // repoEvent is a minimal common subset of most of the events sent by GitHub (such as IssueComment or PullRequest)
@@ -32,6 +59,10 @@ type Server struct {
 type repoEvent struct {
 	Repo   *gogh.Repository `json:"repository,omitempty"`
 	Sender *gogh.User       `json:"sender,omitempty"`
+	Number *int             `json:"number,omitempty"`
+	Issue  *gogh.Issue      `json:"issue,omitempty"`
+
+	Installation *gogh.Installation `json:"installation,omitempty"`
 }
 
 // ServeHTTP validates an incoming webhook and puts it into the event channel.
@@ -58,13 +89,77 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			github.RepoLogField:   event.Repo.URL,
 			github.SenderLogField: event.Sender.URL,
 		})
+		s.registerInstallation(event)
 	}
 
 	fullName := *event.Repo.FullName
+	s.appendToJournal(l, journal.Entry{
+		Time:      time.Now(),
+		GUID:      eventGUID,
+		EventType: eventType,
+		Repo:      fullName,
+		Payload:   payload,
+	})
 	reportIncomingWebHooks(l, fullName)
 	reportHandledEvents(l, eventType)
 	reportRateLimit(l)
 
+	if s.Queue == nil {
+		s.handleEvent(l, eventType, payload)
+		return
+	}
+
+	if err := s.Queue.Enqueue(event.key(), func() { s.handleEvent(l, eventType, payload) }); err != nil {
+		l.WithError(err).Errorf("unable to enqueue %q event", eventType)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Replay handles previously journaled events one after another, in the order they are given
+func (s *Server) Replay(entries []journal.Entry) {
+	for _, entry := range entries {
+		l := logrus.StandardLogger().WithFields(
+			logrus.Fields{
+				"ike-plugins":       s.PluginName,
+				github.EventGUID:    entry.GUID,
+				github.Event:        entry.EventType,
+				github.RepoLogField: entry.Repo,
+				"replay":            true,
+			},
+		)
+		var event repoEvent
+		if err := json.Unmarshal(entry.Payload, &event); err == nil {
+			s.registerInstallation(event)
+		}
+		s.handleEvent(l, entry.EventType, entry.Payload)
+	}
+}
+
+func (s *Server) registerInstallation(event repoEvent) {
+	if s.Installations == nil || event.Installation == nil || event.Repo == nil {
+		return
+	}
+	s.Installations.RegisterInstallation(event.Repo.GetOwner().GetLogin(), event.Installation.GetID())
+}
+
+func (s *Server) appendToJournal(l *logrus.Entry, entry journal.Entry) {
+	if s.Journal == nil {
+		return
+	}
+	if err := s.Journal.Append(entry); err != nil {
+		l.WithError(err).Errorf("failed to append %q event to the journal", entry.EventType)
+	}
+}
+
+func (s *Server) handleEvent(l *logrus.Entry, eventType string, payload []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.Errorf("panic while handling %q event: %v", eventType, r)
+		}
+	}()
+
 	switch github.EventType(eventType) {
 	case github.PullRequest:
 		var event gogh.PullRequestEvent
@@ -84,7 +179,46 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			l.WithError(err).Errorf("error handling '%q' event with payload %+v.", github.IssueComment, event)
 			return
 		}
+	case github.CheckRun:
+		checkRunHandler, ok := s.GitHubEventHandler.(CheckRunEventHandler)
+		if !ok {
+			l.Warnf("received an event of type %q but didn't ask for it", eventType)
+			return
+		}
+		var event gogh.CheckRunEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			l.WithError(err).Errorf("failed while parsing '%q' event with payload: %+v.", github.CheckRun, event)
+		}
+		if err := checkRunHandler.HandleCheckRunEvent(l, &event); err != nil {
+			l.WithError(err).Errorf("error handling '%q' event with payload %+v.", github.CheckRun, event)
+			return
+		}
+	case github.Status:
+		statusHandler, ok := s.GitHubEventHandler.(StatusEventHandler)
+		if !ok {
+			l.Warnf("received an event of type %q but didn't ask for it", eventType)
+			return
+		}
+		var event gogh.StatusEvent
+		if err := json.Unmarshal(payload, &event); err != nil {
+			l.WithError(err).Errorf("failed while parsing '%q' event with payload: %+v.", github.Status, event)
+		}
+		if err := statusHandler.HandleStatusEvent(l, &event); err != nil {
+			l.WithError(err).Errorf("error handling '%q' event with payload %+v.", github.Status, event)
+			return
+		}
 	default:
 		l.Warnf("received an event of type %q but didn't ask for it", eventType)
 	}
 }
+
+// key identifies the pull request (or issue) the event refers to, so events related to it can be serialized
+func (e repoEvent) key() string {
+	number := 0
+	if e.Number != nil {
+		number = *e.Number
+	} else if e.Issue != nil {
+		number = e.Issue.GetNumber()
+	}
+	return fmt.Sprintf("%s#%d", e.Repo.GetFullName(), number)
+}
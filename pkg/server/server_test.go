@@ -0,0 +1,236 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
+	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
+	"github.com/arquillian/ike-prow-plugins/pkg/journal"
+	"github.com/arquillian/ike-prow-plugins/pkg/server"
+	gogh "github.com/google/go-github/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+var _ = Describe("Server with event queue", func() {
+	secret := []byte("123abc")
+
+	var (
+		handler    *RecordingGHEventHandler
+		queue      *server.EventQueue
+		testServer *httptest.Server
+	)
+
+	BeforeEach(func() {
+		handler = &RecordingGHEventHandler{}
+		queue = server.NewEventQueue(2, 1)
+		server.RegisterMetrics(NewDefaultGitHubClient())
+		testServer = httptest.NewServer(&server.Server{
+			GitHubEventHandler: handler,
+			PluginName:         "dummy-name",
+			HmacSecret:         secret,
+			Queue:              queue,
+		})
+	})
+
+	AfterEach(func() {
+		testServer.Close()
+		server.UnRegisterAndResetMetrics()
+		gock.OffAll()
+	})
+
+	It("should accept the event and handle it asynchronously", func() {
+		// given
+		setRateLimitMocks()
+		event := MockPr().
+			LoadedFrom("../plugin/work-in-progress/test_fixtures/github_calls/prs/pr_details.json").
+			Create().
+			CreatePullRequestEvent("opened")
+
+		// when
+		statusCode := sendHook(testServer.URL, github.PullRequest, marshal(event), secret)
+
+		// then
+		Expect(statusCode).To(Equal(http.StatusAccepted))
+		Ω(queue.Shutdown(context.Background())).Should(Succeed())
+		Expect(handler.handledEvents).To(ConsistOf(string(github.PullRequest)))
+	})
+
+	It("should respond with service unavailable when the event cannot be enqueued", func() {
+		// given
+		setRateLimitMocks()
+		Ω(queue.Shutdown(context.Background())).Should(Succeed())
+		event := MockPr().
+			LoadedFrom("../plugin/work-in-progress/test_fixtures/github_calls/prs/pr_details.json").
+			Create().
+			CreatePullRequestEvent("opened")
+
+		// when
+		statusCode := sendHook(testServer.URL, github.PullRequest, marshal(event), secret)
+
+		// then
+		Expect(statusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(handler.handledEvents).To(BeEmpty())
+	})
+})
+
+func sendHook(address string, eventType github.EventType, payload, hmacSecret []byte) int {
+	req, err := http.NewRequest(http.MethodPost, address, bytes.NewBuffer(payload))
+	Ω(err).ShouldNot(HaveOccurred())
+	mac := hmac.New(sha1.New, hmacSecret)
+	_, err = mac.Write(payload)
+	Ω(err).ShouldNot(HaveOccurred())
+	req.Header.Set("X-GitHub-Event", string(eventType))
+	req.Header.Set("X-GitHub-Delivery", "GUID")
+	req.Header.Set("X-Hub-Signature", "sha1="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("content-type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	Ω(err).ShouldNot(HaveOccurred())
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+type InMemoryJournal struct {
+	entries []journal.Entry
+}
+
+func (j *InMemoryJournal) Append(entry journal.Entry) error {
+	j.entries = append(j.entries, entry)
+	return nil
+}
+
+var _ = Describe("Server with event journal", func() {
+	secret := []byte("123abc")
+
+	var (
+		handler       *RecordingGHEventHandler
+		eventsJournal *InMemoryJournal
+		prowServer    *server.Server
+		testServer    *httptest.Server
+	)
+
+	BeforeEach(func() {
+		handler = &RecordingGHEventHandler{}
+		eventsJournal = &InMemoryJournal{}
+		server.RegisterMetrics(NewDefaultGitHubClient())
+		prowServer = &server.Server{
+			GitHubEventHandler: handler,
+			PluginName:         "dummy-name",
+			HmacSecret:         secret,
+			Journal:            eventsJournal,
+		}
+		testServer = httptest.NewServer(prowServer)
+	})
+
+	AfterEach(func() {
+		testServer.Close()
+		server.UnRegisterAndResetMetrics()
+		gock.OffAll()
+	})
+
+	It("should append validated event to the journal", func() {
+		// given
+		setRateLimitMocks()
+		event := MockPr().
+			LoadedFrom("../plugin/work-in-progress/test_fixtures/github_calls/prs/pr_details.json").
+			Create().
+			CreatePullRequestEvent("opened")
+		payload := marshal(event)
+
+		// when
+		statusCode := sendHook(testServer.URL, github.PullRequest, payload, secret)
+
+		// then
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(eventsJournal.entries).To(HaveLen(1))
+		entry := eventsJournal.entries[0]
+		Expect(entry.GUID).To(Equal("GUID"))
+		Expect(entry.EventType).To(Equal(string(github.PullRequest)))
+		Expect(entry.Repo).To(Equal("bartoszmajsak/wfswarm-booster-pipeline-test"))
+		Expect(entry.Payload).To(MatchJSON(payload))
+	})
+
+	It("should not append event with invalid signature to the journal", func() {
+		// when
+		statusCode := sendHook(testServer.URL, github.PullRequest, []byte(`{}`), []byte("wrong-secret"))
+
+		// then
+		Expect(statusCode).To(Equal(http.StatusForbidden))
+		Expect(eventsJournal.entries).To(BeEmpty())
+	})
+
+	It("should replay journaled events through event handler", func() {
+		// given
+		pullRequest := MockPr().
+			LoadedFrom("../plugin/work-in-progress/test_fixtures/github_calls/prs/pr_details.json").
+			Create()
+		entries := []journal.Entry{
+			{GUID: "1", EventType: string(github.PullRequest), Payload: marshal(pullRequest.CreatePullRequestEvent("opened"))},
+			{GUID: "2", EventType: string(github.IssueComment), Payload: marshal(pullRequest.CreateCommentEvent(SentByRepoOwner, "/run all", "created"))},
+		}
+
+		// when
+		prowServer.Replay(entries)
+
+		// then
+		Expect(handler.handledEvents).To(Equal([]string{string(github.PullRequest), string(github.IssueComment)}))
+	})
+})
+
+type InstallationsRecorder map[string]int64
+
+func (r InstallationsRecorder) RegisterInstallation(account string, installationID int64) {
+	r[account] = installationID
+}
+
+var _ = Describe("Server authenticated as GitHub App", func() {
+	secret := []byte("123abc")
+
+	var (
+		installations InstallationsRecorder
+		testServer    *httptest.Server
+	)
+
+	BeforeEach(func() {
+		installations = InstallationsRecorder{}
+		server.RegisterMetrics(NewDefaultGitHubClient())
+		testServer = httptest.NewServer(&server.Server{
+			GitHubEventHandler: &RecordingGHEventHandler{},
+			PluginName:         "dummy-name",
+			HmacSecret:         secret,
+			Installations:      installations,
+		})
+	})
+
+	AfterEach(func() {
+		testServer.Close()
+		server.UnRegisterAndResetMetrics()
+		gock.OffAll()
+	})
+
+	It("should register installation the event has been sent by", func() {
+		// given
+		setRateLimitMocks()
+		event := MockPr().
+			LoadedFrom("../plugin/work-in-progress/test_fixtures/github_calls/prs/pr_details.json").
+			Create().
+			CreatePullRequestEvent("opened")
+		event.Installation = &gogh.Installation{ID: gogh.Int64(42)}
+
+		// when
+		statusCode := sendHook(testServer.URL, github.PullRequest, marshal(event), secret)
+
+		// then
+		Expect(statusCode).To(Equal(http.StatusOK))
+		Expect(installations).To(Equal(InstallationsRecorder{"bartoszmajsak": 42}))
+	})
+})
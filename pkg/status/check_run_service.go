@@ -0,0 +1,222 @@
+package status
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	githubType "github.com/arquillian/ike-prow-plugins/pkg/github"
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	"github.com/arquillian/ike-prow-plugins/pkg/utils"
+	"github.com/google/go-github/github"
+)
+
+// MaxAnnotations is the maximum number of annotations GitHub accepts in a single check run request
+const MaxAnnotations = 50
+
+// CheckRunService is a scm.StatusService which publishes status of the change as a GitHub check run.
+// The check run can be enriched with the scm.CheckReport (see WithReport)
+type CheckRunService struct {
+	client        ghclient.Client
+	logger        log.Logger
+	statusContext githubType.StatusContext
+	change        scm.RepositoryChange
+	report        *scm.CheckReport
+}
+
+// NewCheckRunService creates an instance of CheckRunService necessary for creating check runs
+func NewCheckRunService(client ghclient.Client, logger log.Logger, change scm.RepositoryChange, context githubType.StatusContext) scm.StatusService {
+	return &CheckRunService{
+		client:        client,
+		logger:        logger,
+		statusContext: context,
+		change:        change,
+	}
+}
+
+// NewConfiguredStatusService creates scm.StatusService publishing commit statuses, check runs or both of them
+// depending on status_report setting of the plugin configuration
+func NewConfiguredStatusService(client ghclient.Client, logger log.Logger, change scm.RepositoryChange,
+	context githubType.StatusContext, configuration *config.PluginConfiguration) scm.StatusService {
+
+	switch configuration.StatusReport {
+	case config.ReportChecks:
+		return NewCheckRunService(client, logger, change, context)
+	case config.ReportBoth:
+		return statusServices{
+			NewStatusService(client, logger, change, context),
+			NewCheckRunService(client, logger, change, context),
+		}
+	default:
+		return NewStatusService(client, logger, change, context)
+	}
+}
+
+// WithReport returns StatusService which publishes the given report along with the status when it is able to.
+// Otherwise the given StatusService is returned untouched
+func WithReport(statusService scm.StatusService, report scm.CheckReport) scm.StatusService {
+	if reporting, ok := statusService.(scm.ReportingStatusService); ok {
+		return reporting.WithReport(report)
+	}
+	return statusService
+}
+
+// WithReport creates a copy of the CheckRunService which publishes the given report
+func (s *CheckRunService) WithReport(report scm.CheckReport) scm.StatusService {
+	withReport := *s
+	withReport.report = &report
+	return &withReport
+}
+
+// Success marks given change as a success.
+func (s *CheckRunService) Success(reason, detailsPageName string) error {
+	return s.createCheckRun(githubType.CheckRunCompleted, githubType.ConclusionSuccess, reason,
+		generateDetailsLink(s.statusContext, detailsPageName, githubType.StatusSuccess))
+}
+
+// Failure marks given change as a failure.
+func (s *CheckRunService) Failure(reason, detailsPageName string) error {
+	return s.createCheckRun(githubType.CheckRunCompleted, githubType.ConclusionFailure, reason,
+		generateDetailsLink(s.statusContext, detailsPageName, githubType.StatusFailure))
+}
+
+// Pending marks given change as a pending.
+func (s *CheckRunService) Pending(reason string) error {
+	return s.createCheckRun(githubType.CheckRunInProgress, "", reason, "")
+}
+
+// Error marks given change as a error. As check runs don't have an error state it is reported as a failure.
+func (s *CheckRunService) Error(reason string) error {
+	return s.createCheckRun(githubType.CheckRunCompleted, githubType.ConclusionFailure, reason, "")
+}
+
+func (s *CheckRunService) createCheckRun(status, conclusion, reason, detailsLink string) error {
+	checkRun := github.CreateCheckRunOptions{
+		Name:   fmt.Sprintf("%s/%s", s.statusContext.BotName, s.statusContext.PluginName),
+		Status: utils.String(status),
+		Output: s.createOutput(reason),
+	}
+	if detailsLink != "" {
+		checkRun.DetailsURL = utils.String(detailsLink)
+	}
+	if conclusion != "" {
+		checkRun.Conclusion = utils.String(conclusion)
+		checkRun.CompletedAt = &github.Timestamp{Time: time.Now()}
+	}
+	if s.report != nil {
+		for _, action := range s.report.Actions {
+			checkRun.Actions = append(checkRun.Actions, &github.CheckRunAction{
+				Label:       action.Label,
+				Description: action.Description,
+				Identifier:  action.Identifier,
+			})
+		}
+	}
+
+	err := s.client.CreateCheckRun(s.change, &checkRun)
+
+	if err != nil {
+		s.logger.Errorf("error trying to create check run. %q. cause: %q", checkRun.Name, err)
+	}
+
+	return err
+}
+
+func (s *CheckRunService) createOutput(reason string) *github.CheckRunOutput {
+	title, summary := reason, reason
+	if s.report == nil {
+		return &github.CheckRunOutput{Title: &title, Summary: &summary}
+	}
+
+	if s.report.Title != "" {
+		title = s.report.Title
+	}
+	if s.report.Summary != "" {
+		summary = s.report.Summary
+	}
+
+	annotations := s.report.Annotations
+	if len(annotations) > MaxAnnotations {
+		summary += fmt.Sprintf("\n\nOnly first %d out of %d annotations are shown.", MaxAnnotations, len(annotations))
+		annotations = annotations[:MaxAnnotations]
+	}
+
+	output := &github.CheckRunOutput{Title: &title, Summary: &summary}
+	for _, annotation := range annotations {
+		output.Annotations = append(output.Annotations, newCheckRunAnnotation(annotation))
+	}
+	return output
+}
+
+func newCheckRunAnnotation(annotation scm.CheckAnnotation) *github.CheckRunAnnotation {
+	startLine, endLine := annotation.StartLine, annotation.EndLine
+	if startLine == 0 {
+		startLine = 1
+	}
+	if endLine < startLine {
+		endLine = startLine
+	}
+	level := annotation.Level
+	if level == "" {
+		level = scm.AnnotationNotice
+	}
+
+	checkRunAnnotation := &github.CheckRunAnnotation{
+		Path:            utils.String(annotation.Path),
+		StartLine:       utils.Int(startLine),
+		EndLine:         utils.Int(endLine),
+		AnnotationLevel: utils.String(level),
+		Message:         utils.String(annotation.Message),
+	}
+	if annotation.Title != "" {
+		checkRunAnnotation.Title = utils.String(annotation.Title)
+	}
+	return checkRunAnnotation
+}
+
+// statusServices publishes the status using all of the services it consists of
+type statusServices []scm.StatusService
+
+func (s statusServices) WithReport(report scm.CheckReport) scm.StatusService {
+	withReport := make(statusServices, 0, len(s))
+	for _, service := range s {
+		withReport = append(withReport, WithReport(service, report))
+	}
+	return withReport
+}
+
+func (s statusServices) Success(reason, detailsPageName string) error {
+	return s.forEach(func(service scm.StatusService) error {
+		return service.Success(reason, detailsPageName)
+	})
+}
+
+func (s statusServices) Failure(reason, detailsPageName string) error {
+	return s.forEach(func(service scm.StatusService) error {
+		return service.Failure(reason, detailsPageName)
+	})
+}
+
+func (s statusServices) Pending(reason string) error {
+	return s.forEach(func(service scm.StatusService) error {
+		return service.Pending(reason)
+	})
+}
+
+func (s statusServices) Error(reason string) error {
+	return s.forEach(func(service scm.StatusService) error {
+		return service.Error(reason)
+	})
+}
+
+func (s statusServices) forEach(publish func(service scm.StatusService) error) error {
+	var firstErr error
+	for _, service := range s {
+		if err := publish(service); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
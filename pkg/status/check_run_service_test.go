@@ -0,0 +1,136 @@
+package status_test
+
+import (
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	"github.com/arquillian/ike-prow-plugins/pkg/github"
+	. "github.com/arquillian/ike-prow-plugins/pkg/internal/test"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/plugin"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	"github.com/arquillian/ike-prow-plugins/pkg/status"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	gock "gopkg.in/h2non/gock.v1"
+)
+
+var _ = Describe("GitHub Check Run Service", func() {
+
+	change := scm.RepositoryChange{RepoName: "test-repo", Owner: "alien-ike", Hash: "1232asdasd"}
+	context := github.StatusContext{BotName: "alien-ike", PluginName: "test-keeper"}
+
+	newStatusService := func(statusReport string) scm.StatusService {
+		return status.NewConfiguredStatusService(NewDefaultGitHubClient(), log.NewTestLogger(), change, context,
+			&config.PluginConfiguration{StatusReport: statusReport})
+	}
+
+	AfterEach(EnsureGockRequestsHaveBeenMatched)
+
+	It("should report success as completed check run named after bot and plugin", func() {
+		// given
+		dummySuccessURL := plugin.DocumentationURL + "/status/test-keeper/success/dummy-success.html"
+
+		gock.New("https://api.github.com").
+			Post("/repos/alien-ike/test-repo/check-runs").
+			SetMatcher(ExpectPayload(
+				HaveName("alien-ike/test-keeper"),
+				HaveConclusion(github.ConclusionSuccess),
+				HaveDetailsURL(dummySuccessURL),
+				HaveOutputTitle("All good, we have tests"))).
+			Reply(201)
+
+		// when
+		err := newStatusService(config.ReportChecks).Success("All good, we have tests", "dummy-success")
+
+		// then - implicit verification of /check-runs call occurrence with proper payload
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should report failure with annotations and actions of the report", func() {
+		// given
+		report := scm.CheckReport{
+			Summary: "We don't have tests",
+			Annotations: []scm.CheckAnnotation{
+				{Path: "src/main/java/Anything.java", Level: scm.AnnotationWarning, Message: "missing test"},
+				{Path: "src/main/java/Other.java", Level: scm.AnnotationWarning, Message: "missing test"},
+			},
+			Actions: []scm.CheckAction{{Label: "Ok without tests", Description: "Approve", Identifier: "ok-without-tests"}},
+		}
+
+		gock.New("https://api.github.com").
+			Post("/repos/alien-ike/test-repo/check-runs").
+			SetMatcher(ExpectPayload(
+				HaveConclusion(github.ConclusionFailure),
+				HaveOutputSummaryThatContains("We don't have tests"),
+				HaveAnnotatedFiles("src/main/java/Anything.java", "src/main/java/Other.java"),
+				HaveActions("ok-without-tests"))).
+			Reply(201)
+
+		// when
+		err := status.WithReport(newStatusService(config.ReportChecks), report).Failure("No tests in this PR", "dummy-failure")
+
+		// then - implicit verification of /check-runs call occurrence with proper payload
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should limit number of annotations sent in a single check run", func() {
+		// given
+		report := scm.CheckReport{Summary: "We don't have tests"}
+		var expectedPaths []string
+		for i := 0; i < status.MaxAnnotations+10; i++ {
+			path := "src/main/java/Class" + string(rune('A'+i%26)) + string(rune('a'+i/26)) + ".java"
+			report.Annotations = append(report.Annotations, scm.CheckAnnotation{Path: path})
+			if i < status.MaxAnnotations {
+				expectedPaths = append(expectedPaths, path)
+			}
+		}
+
+		gock.New("https://api.github.com").
+			Post("/repos/alien-ike/test-repo/check-runs").
+			SetMatcher(ExpectPayload(
+				HaveAnnotatedFiles(expectedPaths...),
+				HaveOutputSummaryThatContains("Only first 50 out of 60 annotations are shown."))).
+			Reply(201)
+
+		// when
+		err := status.WithReport(newStatusService(config.ReportChecks), report).Failure("No tests in this PR", "dummy-failure")
+
+		// then - implicit verification of /check-runs call occurrence with proper payload
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should publish both commit status and check run", func() {
+		// given
+		gock.New("https://api.github.com").
+			Post("/repos/alien-ike/test-repo/statuses/1232asdasd").
+			SetMatcher(ExpectPayload(HaveState(github.StatusSuccess))).
+			Reply(201)
+
+		gock.New("https://api.github.com").
+			Post("/repos/alien-ike/test-repo/check-runs").
+			SetMatcher(ExpectPayload(HaveConclusion(github.ConclusionSuccess))).
+			Reply(201)
+
+		// when
+		err := status.WithReport(newStatusService(config.ReportBoth), scm.CheckReport{Summary: "All good"}).
+			Success("All good, we have tests", "dummy-success")
+
+		// then - implicit verification of both calls occurrence with proper payload
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(gock.IsDone()).Should(BeTrue())
+	})
+
+	It("should publish only commit status when report mode is not set", func() {
+		// given
+		gock.New("https://api.github.com").
+			Post("/repos/alien-ike/test-repo/statuses/1232asdasd").
+			SetMatcher(ExpectPayload(HaveState(github.StatusFailure))).
+			Reply(201)
+
+		// when
+		err := status.WithReport(newStatusService(""), scm.CheckReport{Summary: "Missing tests"}).
+			Failure("We don't have tests", "dummy-failure")
+
+		// then - implicit verification of /statuses call occurrence with proper payload
+		Ω(err).ShouldNot(HaveOccurred())
+	})
+})
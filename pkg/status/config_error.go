@@ -0,0 +1,42 @@
+package status
+
+import (
+	"strings"
+
+	"github.com/arquillian/ike-prow-plugins/pkg/config"
+	"github.com/arquillian/ike-prow-plugins/pkg/log"
+	"github.com/arquillian/ike-prow-plugins/pkg/scm"
+	"github.com/arquillian/ike-prow-plugins/pkg/status/message"
+)
+
+// InvalidConfigMessageBeginning is a beginning of the status message listing problems found in the configuration of the plugin
+const InvalidConfigMessageBeginning = "The configuration of the plugin is invalid, so this pull request cannot be verified. " +
+	"Please fix the following problems:\n\n"
+
+// ReportConfigError sets the error status when the configuration of the plugin cannot be loaded. When the configuration
+// is invalid, the problems found in it are also listed in the status message (if msgService is given).
+// The cause is returned so it is propagated to the caller
+func ReportConfigError(statusService scm.StatusService, msgService *message.StatusMessageService, logger log.Logger,
+	cause error) error {
+
+	if validationErr, ok := cause.(*config.ValidationError); ok {
+		msg := InvalidConfigMessage(validationErr)
+		if msgService != nil {
+			msgService.SadStatusMessage(msg, "invalid_config", true)
+		}
+		statusService = WithReport(statusService, scm.CheckReport{Summary: msg})
+	}
+	if statusErr := statusService.Error(config.ErrorStatusMessage(cause)); statusErr != nil {
+		logger.Errorf("failed to report error status. cause: %s", statusErr)
+	}
+	return cause
+}
+
+// InvalidConfigMessage creates a status message listing the problems found in the configuration of the plugin
+func InvalidConfigMessage(validationErr *config.ValidationError) string {
+	problems := make([]string, 0, len(validationErr.Errors))
+	for _, fieldErr := range validationErr.Errors {
+		problems = append(problems, "* "+fieldErr.String())
+	}
+	return InvalidConfigMessageBeginning + strings.Join(problems, "\n")
+}
@@ -6,14 +6,15 @@ import (
 	"text/template"
 
 	assets "github.com/arquillian/ike-prow-plugins/pkg/assets/generated"
+	ghclient "github.com/arquillian/ike-prow-plugins/pkg/github/client"
 	ghservice "github.com/arquillian/ike-prow-plugins/pkg/github/service"
 	"github.com/arquillian/ike-prow-plugins/pkg/log"
 	"github.com/arquillian/ike-prow-plugins/pkg/scm"
-	"github.com/arquillian/ike-prow-plugins/pkg/utils"
 )
 
 // Loader keeps information necessary for status message loading
 type Loader struct {
+	Client     ghclient.Client
 	Message    *Message
 	Log        log.Logger
 	PluginName string
@@ -21,11 +22,12 @@ type Loader struct {
 
 // Message keeps all data used in message templates
 type Message struct {
-	Thumbnail      string
-	Description    string
-	ConfigFile     string
-	Documentation  string
-	MessageFileURL string
+	Thumbnail            string
+	Description          string
+	ConfigFile           string
+	InheritedConfigFiles []string
+	Documentation        string
+	MessageFileURL       string
 }
 
 // LoadMessage loads a status message from the template files
@@ -60,10 +62,11 @@ func (l *Loader) defaultFileContent(pluginName string, change scm.RepositoryChan
 		defaultFileSpec = "_" + defaultFileSpec
 	}
 	statusMsgPath := fmt.Sprintf("%s%s%s_message.md", ghservice.ConfigHome, pluginName, defaultFileSpec)
-	ghFileService := ghservice.RawFileService{Change: change}
-
-	content, e := utils.GetFileFromURL(ghFileService.GetRawFileURL(statusMsgPath))
+	content, e := l.Client.GetFileContent(change.Owner, change.RepoName, change.Hash, statusMsgPath)
 	if e != nil {
+		if !scm.IsNotFound(e) {
+			l.Log.Errorf("failed to load status message file %s. cause: %s", statusMsgPath, e)
+		}
 		return ""
 	}
 	return string(content)
@@ -16,10 +16,18 @@ var _ = Describe("Loader message creation", func() {
 
 	Context("Creation of default message messages that are sent to a validated PR when custom message file is not set", func() {
 
+		BeforeEach(func() {
+			defer gock.OffAll()
+		})
+
+		AfterEach(EnsureGockRequestsHaveBeenMatched)
+
 		It("should create default message referencing to documentation when url to config is empty", func() {
 			// given
 			conf := config.PluginConfiguration{PluginName: ProwPluginName}
+			NonExistingRawGitHubFiles("_message.md")
 			message := &message.Loader{
+				Client:  NewDefaultGitHubClient(),
 				Message: &message.Message{ConfigFile: conf.LocationURL, Documentation: "#_my_test_plugin"},
 			}
 
@@ -35,7 +43,9 @@ var _ = Describe("Loader message creation", func() {
 			// given
 			url := "http://github.com/my/repo/my-test-plugin.yaml"
 			conf := config.PluginConfiguration{LocationURL: url}
+			NonExistingRawGitHubFiles("_message.md")
 			message := &message.Loader{
+				Client:  NewDefaultGitHubClient(),
 				Message: &message.Message{ConfigFile: conf.LocationURL, Documentation: "#_my_test_plugin"},
 			}
 
@@ -46,6 +56,29 @@ var _ = Describe("Loader message creation", func() {
 			Expect(msg).NotTo(ContainSubstring("http://arquillian.org/ike-prow-plugins/#_my_test_plugin"))
 			Expect(msg).To(ContainSubstring(url))
 		})
+
+		It("should create default message referencing to config file and organization defaults it overrides", func() {
+			// given
+			orgURL := "http://github.com/my/.ike-prow/blob/HEAD/my-test-plugin.yml"
+			repoURL := "http://github.com/my/repo/blob/46cb8fac/.ike-prow/my-test-plugin.yml"
+			NonExistingRawGitHubFiles("_message.md")
+			message := &message.Loader{
+				Client: NewDefaultGitHubClient(),
+				Message: &message.Message{
+					ConfigFile:           repoURL,
+					InheritedConfigFiles: []string{orgURL},
+					Documentation:        "#_my_test_plugin",
+				},
+			}
+
+			// when
+			msg := message.LoadMessage(scm.RepositoryChange{}, "")
+
+			// then
+			Expect(msg).To(ContainSubstring("stored in the [file](" + repoURL + ")"))
+			Expect(msg).To(ContainSubstring("It overrides the defaults defined in:"))
+			Expect(msg).To(ContainSubstring("* [" + orgURL + "](" + orgURL + ")"))
+		})
 	})
 
 	Context("Creation of default message messages from default location when config plugin message is not set", func() {
@@ -60,10 +93,11 @@ var _ = Describe("Loader message creation", func() {
 			// given
 			NonExistingRawGitHubFiles("my-test-plugin.yaml", "my-test-plugin.yml")
 
-			gock.New("https://raw.githubusercontent.com").
-				Get("owner/repo/46cb8fac44709e4ccaae97448c65e8f7320cfea7/.ike-prow/my-test-plugin_message.md").
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/contents/.ike-prow/my-test-plugin_message.md").
+				MatchParam("ref", "46cb8fac44709e4ccaae97448c65e8f7320cfea7").
 				Reply(200).
-				BodyString("Custom message")
+				BodyString(FileContentResponse("Custom message"))
 
 			change := scm.RepositoryChange{
 				Owner:    "owner",
@@ -72,6 +106,7 @@ var _ = Describe("Loader message creation", func() {
 			}
 
 			message := &message.Loader{
+				Client:     NewDefaultGitHubClient(),
 				PluginName: ProwPluginName,
 				Message: &message.Message{
 					ConfigFile:    "http://github.com/my/repo/my-test-plugin.yaml",
@@ -89,10 +124,11 @@ var _ = Describe("Loader message creation", func() {
 			// given
 			NonExistingRawGitHubFiles("my-test-plugin.yaml", "my-test-plugin.yml")
 
-			gock.New("https://raw.githubusercontent.com").
-				Get("owner/repo/46cb8fac44709e4ccaae97448c65e8f7320cfea7/.ike-prow/my-test-plugin_message.md").
+			gock.New("https://api.github.com").
+				Get("/repos/owner/repo/contents/.ike-prow/my-test-plugin_message.md").
+				MatchParam("ref", "46cb8fac44709e4ccaae97448c65e8f7320cfea7").
 				Reply(200).
-				BodyString("Custom message")
+				BodyString(FileContentResponse("Custom message"))
 
 			change := scm.RepositoryChange{
 				Owner:    "owner",
@@ -101,6 +137,7 @@ var _ = Describe("Loader message creation", func() {
 			}
 
 			message := &message.Loader{
+				Client:     NewDefaultGitHubClient(),
 				PluginName: ProwPluginName,
 				Message:    &message.Message{Documentation: "#_my_test_plugin"},
 			}
@@ -18,12 +18,17 @@ const (
 	PluginTitleTemplate     = "### Ike Plugins (%s)"
 	assigneeMentionTemplate = "Thank you @%s for this contribution!"
 
+	// PreviewedCommitTemplate closes the comment previewing the effect of the configuration changed in the PR. It records
+	// the head commit the preview has been made for, so the preview is not made again until new commits are pushed
+	PreviewedCommitTemplate = "_Previewed for commit %s._"
+
 	sadIke   = `<img align="left" src="https://raw.githubusercontent.com/arquillian/ike-prow-plugins/master/docs/images/arquillian_ui_failure_64px.png">`
 	happyIke = `<img align="left" src="https://raw.githubusercontent.com/arquillian/ike-prow-plugins/master/docs/images/arquillian_ui_success_64px.png">`
 )
 
 // StatusMessageService is a struct managing plugin comments
 type StatusMessageService struct {
+	client         ghclient.Client
 	commentService *ghservice.CommentService
 	logger         log.Logger
 	commentContext StatusMessageContext
@@ -53,6 +58,7 @@ func NewStatusMessageContext(pluginName, documentationSection string, pr *gogh.P
 func NewStatusMessageService(client ghclient.Client, logger log.Logger, commentsLoader *ghservice.IssueCommentsLazyLoader,
 	commentContext StatusMessageContext) *StatusMessageService {
 	return &StatusMessageService{
+		client: client,
 		commentService: &ghservice.CommentService{
 			Client: client,
 			Issue:  commentsLoader.Issue,
@@ -88,17 +94,29 @@ func (s *StatusMessageService) logError(err error) {
 
 func (s *StatusMessageService) newMessageLoader(image, msg string) *Loader {
 	return &Loader{
+		Client:     s.client,
 		Log:        s.logger,
 		PluginName: s.commentContext.pluginName,
 		Message: &Message{
-			Thumbnail:     image,
-			Description:   msg,
-			ConfigFile:    s.commentContext.config.LocationURL,
-			Documentation: s.commentContext.documentationSection,
+			Thumbnail:            image,
+			Description:          msg,
+			ConfigFile:           s.commentContext.config.LocationURL,
+			InheritedConfigFiles: s.inheritedConfigFiles(),
+			Documentation:        s.commentContext.documentationSection,
 		},
 	}
 }
 
+// inheritedConfigFiles returns the configuration files (such as organization-wide defaults) the plugin configuration
+// stored in ConfigFile is merged on top of
+func (s *StatusMessageService) inheritedConfigFiles() []string {
+	locations := s.commentContext.config.LocationURLs
+	if len(locations) < 2 {
+		return nil
+	}
+	return locations[:len(locations)-1]
+}
+
 // StatusMessage checks all present comments in the issue/pull-request. If no comment with PluginTitleTemplate
 // (with the related plugin) is found, then it adds a new comment with the plugin title, assignee mention
 // and the given commentMsg. If such a comment is present already, then it does nothing.
@@ -126,6 +144,22 @@ func (s *StatusMessageService) StatusMessage(commentMsgCreator func() string, ad
 	return nil
 }
 
+// StatusMessageContains checks if the comment with PluginTitleTemplate (with the related plugin) is present in the
+// issue/pull-request and contains the given content
+func (s *StatusMessageService) StatusMessageContains(content string) bool {
+	comments, err := s.commentsLoader.Load()
+	if err != nil {
+		s.logger.Errorf("Getting all comments failed with an error: %s", err)
+		return false
+	}
+	for _, com := range comments {
+		if strings.HasPrefix(com.GetBody(), s.getPluginTitle()) {
+			return strings.Contains(com.GetBody(), content)
+		}
+	}
+	return false
+}
+
 func (s *StatusMessageService) append(first, second string) string {
 	return first + "\n\n" + second
 }
@@ -72,5 +72,9 @@ func (s *Service) setStatus(status, reason, detailsLink string) error {
 }
 
 func (s *Service) generateDetailsLink(filename, status string) string {
-	return fmt.Sprintf("%s/status/%s/%s/%s.html", plugin.DocumentationURL, s.statusContext.PluginName, strings.ToLower(status), filename)
+	return generateDetailsLink(s.statusContext, filename, status)
+}
+
+func generateDetailsLink(statusContext githubType.StatusContext, filename, status string) string {
+	return fmt.Sprintf("%s/status/%s/%s/%s.html", plugin.DocumentationURL, statusContext.PluginName, strings.ToLower(status), filename)
 }
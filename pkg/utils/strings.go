@@ -8,6 +8,9 @@ func String(v string) *string { return &v }
 // Int returns a pointer to the int value passed in.
 func Int(v int) *int { return &v }
 
+// Bool returns a pointer to the bool value passed in.
+func Bool(v bool) *bool { return &v }
+
 // Contains checks if a slice contains an element
 func Contains(s, e interface{}) bool {
 	slice := convertSliceToInterface(s)
@@ -7,18 +7,29 @@ import (
 	"time"
 )
 
+// HTTPError is returned when the server responds with an error status code
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("server responded with error %d", e.StatusCode)
+}
+
 // GetFileFromURL retrieves the content of the file on the given url
 func GetFileFromURL(url string) ([]byte, error) {
 	client := &http.Client{
 		Timeout: time.Second * 10,
 	}
+	return GetFileFromURLUsing(client, url)
+}
+
+// GetFileFromURLUsing retrieves the content of the file on the given url using the given client
+func GetFileFromURLUsing(client *http.Client, url string) ([]byte, error) {
 	resp, err := client.Get(url)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode >= 400 {
-		return make([]byte, 0), fmt.Errorf("server responded with error %d", resp.StatusCode)
-	}
 
 	defer func() {
 		if cerr := resp.Body.Close(); cerr != nil && err == nil {
@@ -26,6 +37,10 @@ func GetFileFromURL(url string) ([]byte, error) {
 		}
 	}()
 
+	if resp.StatusCode >= 400 {
+		return make([]byte, 0), &HTTPError{StatusCode: resp.StatusCode}
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err